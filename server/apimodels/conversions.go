@@ -92,6 +92,7 @@ func ConvertBlockModelToBlockResponse(block *dbmodels.Block, selectedTipBlueScor
 		Bits:                 block.Bits,
 		Nonce:                serializer.BytesToUint64(block.Nonce),
 		ParentBlockHashes:    make([]string, len(block.ParentBlocks)),
+		ChildBlockHashes:     make([]string, len(block.ChildBlocks)),
 		TransactionIDs:       make([]string, len(block.Transactions)),
 		BlueScore:            block.BlueScore,
 		TransactionCount:     block.TransactionCount,
@@ -102,6 +103,10 @@ func ConvertBlockModelToBlockResponse(block *dbmodels.Block, selectedTipBlueScor
 		blockRes.ParentBlockHashes[i] = parent.BlockHash
 	}
 
+	for i, child := range block.ChildBlocks {
+		blockRes.ChildBlockHashes[i] = child.BlockHash
+	}
+
 	for i, tx := range block.Transactions {
 		blockRes.TransactionIDs[i] = tx.TransactionID
 	}
@@ -145,3 +150,55 @@ func ConvertTransactionOutputModelToTransactionOutputResponse(transactionOutput
 		IsSpendable:             &isSpendable,
 	}, nil
 }
+
+// Block colors, as seen by the selected parent chain
+const (
+	BlockColorBlue    = "blue"
+	BlockColorRed     = "red"
+	BlockColorUnknown = "unknown"
+)
+
+// ConvertDAGBlockToDAGBlockResponse converts a compact block database object into a DAGBlock
+func ConvertDAGBlockToDAGBlockResponse(dagBlock *dbmodels.DAGBlock) *DAGBlock {
+	color := BlockColorUnknown
+	switch {
+	case dagBlock.IsChainBlock:
+		// Chain blocks are always blue
+		color = BlockColorBlue
+	case dagBlock.IsBlue == nil:
+	case *dagBlock.IsBlue:
+		color = BlockColorBlue
+	default:
+		color = BlockColorRed
+	}
+
+	return &DAGBlock{
+		BlockHash:    dagBlock.BlockHash,
+		BlueScore:    dagBlock.BlueScore,
+		Timestamp:    uint64(dagBlock.Timestamp.Unix()),
+		IsChainBlock: dagBlock.IsChainBlock,
+		Color:        color,
+	}
+}
+
+// ConvertDAGEdgeToDAGEdgeResponse converts a compact block-parent relation database object into a DAGEdge
+func ConvertDAGEdgeToDAGEdgeResponse(dagEdge *dbmodels.DAGEdge) *DAGEdge {
+	return &DAGEdge{
+		BlockHash:       dagEdge.BlockHash,
+		ParentBlockHash: dagEdge.ParentBlockHash,
+	}
+}
+
+// ConvertWebhookSubscriptionModelToWebhookSubscriptionResponse converts a webhook subscription
+// database object into a WebhookSubscriptionResponse. The secret is not included.
+func ConvertWebhookSubscriptionModelToWebhookSubscriptionResponse(
+	subscription *dbmodels.WebhookSubscription) *WebhookSubscriptionResponse {
+
+	return &WebhookSubscriptionResponse{
+		ID:            subscription.ID,
+		URL:           subscription.URL,
+		Address:       subscription.Address,
+		TransactionID: subscription.TransactionID,
+		Confirmations: subscription.Confirmations,
+	}
+}
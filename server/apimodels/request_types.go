@@ -4,3 +4,18 @@ package apimodels
 type RawTransaction struct {
 	RawTransaction string `json:"rawTransaction"`
 }
+
+// AddressesRequest is a json representation of a request
+// for the data of several addresses at once
+type AddressesRequest struct {
+	Addresses []string `json:"addresses"`
+}
+
+// WebhookSubscriptionRequest is a json representation of a request
+// to subscribe a webhook to address or transaction events
+type WebhookSubscriptionRequest struct {
+	URL           string  `json:"url"`
+	Address       *string `json:"address"`
+	TransactionID *string `json:"transactionId"`
+	Confirmations *uint64 `json:"confirmations"`
+}
@@ -15,6 +15,7 @@ type TransactionResponse struct {
 	Mass                    uint64                       `json:"mass"`
 	Version                 uint16                       `json:"version"`
 	Blocks                  []*BlockResponse             `json:"blocks"`
+	NetValue                *int64                       `json:"netValue,omitempty"`
 }
 
 // TransactionOutputResponse is a json representation of a transaction output
@@ -61,20 +62,176 @@ type BlockResponse struct {
 	Bits                 uint32   `json:"bits"`
 	Nonce                uint64   `json:"nonce"`
 	ParentBlockHashes    []string `json:"parentBlockHashes"`
+	ChildBlockHashes     []string `json:"childBlockHashes"`
 	BlueScore            uint64   `json:"blueScore"`
 	TransactionCount     uint16   `json:"transactionCount"`
 	Difficulty           float64  `json:"difficulty"`
 	TransactionIDs       []string `json:"transactionIds"`
 }
 
-// FeeEstimateResponse is a json representation of a fee estimate
+// BlockNeighbourhoodResponse is a json representation of the
+// blocks surrounding a block in the DAG, up to a certain depth
+type BlockNeighbourhoodResponse struct {
+	BlockHash string           `json:"blockHash"`
+	Depth     uint64           `json:"depth"`
+	Blocks    []*BlockResponse `json:"blocks"`
+}
+
+// DAGResponse is a json representation of a section of the DAG
+type DAGResponse struct {
+	FromBlueScore uint64      `json:"fromBlueScore"`
+	ToBlueScore   uint64      `json:"toBlueScore"`
+	Truncated     bool        `json:"truncated"`
+	Blocks        []*DAGBlock `json:"blocks"`
+	Edges         []*DAGEdge  `json:"edges"`
+}
+
+// DAGBlock is a compact json representation of a block,
+// used to visualize the DAG
+type DAGBlock struct {
+	BlockHash    string `json:"blockHash"`
+	BlueScore    uint64 `json:"blueScore"`
+	Timestamp    uint64 `json:"timestamp"`
+	IsChainBlock bool   `json:"isChainBlock"`
+	Color        string `json:"color"`
+}
+
+// DAGEdge is a json representation of an edge
+// between a block and one of its parents
+type DAGEdge struct {
+	BlockHash       string `json:"blockHash"`
+	ParentBlockHash string `json:"parentBlockHash"`
+}
+
+// FeeEstimateResponse is a json representation of a fee estimate.
+// The estimates are in sompi per gram of mass.
 type FeeEstimateResponse struct {
-	HighPriority   float64 `json:"highPriority"`
-	NormalPriority float64 `json:"normalPriority"`
-	LowPriority    float64 `json:"lowPriority"`
+	HighPriority    float64 `json:"highPriority"`
+	NormalPriority  float64 `json:"normalPriority"`
+	LowPriority     float64 `json:"lowPriority"`
+	Confidence      float64 `json:"confidence"`
+	SampleSize      uint64  `json:"sampleSize"`
+	FromBlueScore   uint64  `json:"fromBlueScore"`
+	ToBlueScore     uint64  `json:"toBlueScore"`
+	MempoolSize     uint64  `json:"mempoolSize"`
+	MempoolMass     uint64  `json:"mempoolMass"`
+	MempoolPressure float64 `json:"mempoolPressure"`
 }
 
 // TransactionDoubleSpendsResponse is a json representation of transaction doublespends response
 type TransactionDoubleSpendsResponse struct {
 	Transactions []*TransactionResponse `json:"transactions"`
 }
+
+// WebhookSubscriptionResponse is a json representation of a webhook subscription.
+// The secret is returned only once, when the subscription is created.
+type WebhookSubscriptionResponse struct {
+	ID            uint64  `json:"id"`
+	URL           string  `json:"url"`
+	Address       *string `json:"address,omitempty"`
+	TransactionID *string `json:"transactionId,omitempty"`
+	Confirmations *uint64 `json:"confirmations,omitempty"`
+	Secret        string  `json:"secret,omitempty"`
+}
+
+// SubmitTransactionResponse is a json representation of
+// a transaction that was relayed to the node
+type SubmitTransactionResponse struct {
+	TransactionID string `json:"transactionId"`
+}
+
+// PaginatedResponse is a json representation of a page of a list. Next and Prev
+// are opaque cursors that fetch the pages after and before it, and are null
+// when there are no such pages.
+type PaginatedResponse struct {
+	Items interface{} `json:"items"`
+	Next  *string     `json:"next"`
+	Prev  *string     `json:"prev"`
+}
+
+// AddressUTXOsResponse is a json representation of the UTXOs of an address
+type AddressUTXOsResponse struct {
+	Address string                       `json:"address"`
+	UTXOs   []*TransactionOutputResponse `json:"utxos"`
+}
+
+// AddressTransactionsResponse is a json representation of
+// the transactions an address is either an input or an output of
+type AddressTransactionsResponse struct {
+	Address      string                 `json:"address"`
+	Transactions []*TransactionResponse `json:"transactions"`
+}
+
+// AddressHistoryEntryResponse is a json representation of the change in the
+// balance of an address in an accepted transaction. Timestamp is the unix time
+// in seconds of the accepting block, and Fee is the fee of the transaction if
+// it's outgoing, and 0 otherwise.
+type AddressHistoryEntryResponse struct {
+	Timestamp      uint64 `json:"timestamp"`
+	TransactionID  string `json:"transactionId"`
+	Direction      string `json:"direction"`
+	NetAmount      int64  `json:"netAmount"`
+	Fee            uint64 `json:"fee"`
+	RunningBalance int64  `json:"runningBalance"`
+}
+
+// AddressBalanceResponse is a json representation of the balance of an
+// address from the transactions that were accepted up to a blue score
+type AddressBalanceResponse struct {
+	Address   string `json:"address"`
+	BlueScore uint64 `json:"blueScore"`
+	Balance   int64  `json:"balance"`
+}
+
+// AddressBalanceHistoryResponse is a json representation of the
+// balances of an address at the boundaries of an interval
+type AddressBalanceHistoryResponse struct {
+	Address  string                         `json:"address"`
+	Interval string                         `json:"interval"`
+	Balances []*AddressBalancePointResponse `json:"balances"`
+}
+
+// AddressBalancePointResponse is a json representation of the balance
+// of an address at a unix time in seconds
+type AddressBalancePointResponse struct {
+	Timestamp uint64 `json:"timestamp"`
+	Balance   int64  `json:"balance"`
+}
+
+// SearchResultType is the type of the entity a search result refers to
+type SearchResultType string
+
+// SearchResultType constants
+const (
+	SearchResultTypeBlock       SearchResultType = "block"
+	SearchResultTypeTransaction SearchResultType = "transaction"
+	SearchResultTypeAddress     SearchResultType = "address"
+)
+
+// SearchResultResponse is a json representation of a search result.
+// Value is the block hash, transaction ID or address that matched.
+type SearchResultResponse struct {
+	Type  SearchResultType `json:"type"`
+	Value string           `json:"value"`
+}
+
+// SearchResponse is a json representation of the results of a search query
+type SearchResponse struct {
+	Query   string                  `json:"query"`
+	Results []*SearchResultResponse `json:"results"`
+}
+
+// SyncStatusResponse is a json representation of how far the database
+// is synced with the node. Timestamps and ages are in seconds. The node
+// fields are nil when the node is not available.
+type SyncStatusResponse struct {
+	IsSynced                           bool    `json:"isSynced"`
+	SelectedTipHash                    string  `json:"selectedTipHash"`
+	SelectedTipBlueScore               uint64  `json:"selectedTipBlueScore"`
+	SelectedTipTimestamp               uint64  `json:"selectedTipTimestamp"`
+	SelectedTipAge                     uint64  `json:"selectedTipAge"`
+	NodeVirtualSelectedParentBlueScore *uint64 `json:"nodeVirtualSelectedParentBlueScore"`
+	NodeBlockCount                     *uint64 `json:"nodeBlockCount"`
+	NodeHeaderCount                    *uint64 `json:"nodeHeaderCount"`
+	BlueScoreLag                       *uint64 `json:"blueScoreLag"`
+}
@@ -0,0 +1,17 @@
+package apimodels
+
+// Webhook notification events
+const (
+	WebhookEventTransaction           = "transaction"
+	WebhookEventTransactionAccepted   = "transaction-accepted"
+	WebhookEventTransactionUnaccepted = "transaction-unaccepted"
+	WebhookEventTransactionConfirmed  = "transaction-confirmed"
+)
+
+// WebhookNotification is a json representation of
+// the data sent to a webhook subscriber.
+type WebhookNotification struct {
+	SubscriptionID uint64               `json:"subscriptionId"`
+	Event          string               `json:"event"`
+	Transaction    *TransactionResponse `json:"transaction"`
+}
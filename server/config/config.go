@@ -12,16 +12,21 @@ import (
 
 // CommonConfigFlags holds configuration common to both the server and the sync daemon.
 type CommonConfigFlags struct {
-	ShowVersion bool   `short:"V" long:"version" description:"Display version information and exit"`
-	LogDir      string `long:"logdir" description:"Directory to log output."`
-	DebugLevel  string `short:"d" long:"debuglevel" description:"Set log level {trace, debug, info, warn, error, critical}"  default:"info"`
-	DBAddress   string `long:"dbaddress" description:"Database address" default:"localhost:5432"`
-	DBSSLMode   string `long:"dbsslmode" description:"Database SSL mode" choice:"disable" choice:"allow" choice:"prefer" choice:"require" choice:"verify-ca" choice:"verify-full" default:"disable"`
-	DBUser      string `long:"dbuser" description:"Database user" required:"true"`
-	DBPassword  string `long:"dbpass" description:"Database password" required:"true"`
-	DBName      string `long:"dbname" description:"Database name" required:"true"`
-	RPCServer   string `short:"s" long:"rpcserver" description:"RPC server to connect to"`
-	Profile     string `long:"profile" description:"Enable HTTP profiling on the given port"`
+	ShowVersion      bool    `short:"V" long:"version" description:"Display version information and exit"`
+	LogDir           string  `long:"logdir" description:"Directory to log output."`
+	DebugLevel       string  `short:"d" long:"debuglevel" description:"Set log level {trace, debug, info, warn, error, critical}"  default:"info"`
+	DBAddress        string  `long:"dbaddress" description:"Database address" default:"localhost:5432"`
+	DBSSLMode        string  `long:"dbsslmode" description:"Database SSL mode" choice:"disable" choice:"allow" choice:"prefer" choice:"require" choice:"verify-ca" choice:"verify-full" default:"disable"`
+	DBUser           string  `long:"dbuser" description:"Database user" required:"true"`
+	DBPassword       string  `long:"dbpass" description:"Database password" required:"true"`
+	DBName           string  `long:"dbname" description:"Database name" required:"true"`
+	RPCServer        string  `short:"s" long:"rpcserver" description:"RPC server to connect to"`
+	Profile          string  `long:"profile" description:"Enable HTTP profiling on the given port"`
+	MetricsListen    string  `long:"metricslisten" description:"Serve Prometheus metrics under /metrics on the given address, e.g. 0.0.0.0:9090"`
+	TraceExporter    string  `long:"traceexporter" description:"Export OpenTelemetry traces with the given exporter. Traces are not exported when it's not set" choice:"stdout" choice:"file" choice:"jaeger"`
+	TraceFile        string  `long:"tracefile" description:"File to append the traces to when using the file exporter"`
+	TraceEndpoint    string  `long:"traceendpoint" description:"Jaeger collector endpoint to export the traces to, e.g. http://localhost:14268/api/traces"`
+	TraceSampleRatio float64 `long:"tracesampleratio" description:"Fraction of the traces to sample, between 0 and 1" default:"1"`
 	config.NetworkFlags
 }
 
@@ -55,5 +60,15 @@ func (commonFlags *CommonConfigFlags) ResolveCommonFlags(parser *flags.Parser,
 		}
 	}
 
+	if commonFlags.TraceExporter == "file" && commonFlags.TraceFile == "" {
+		return errors.New("--tracefile is required when using the file trace exporter")
+	}
+	if commonFlags.TraceExporter == "jaeger" && commonFlags.TraceEndpoint == "" {
+		return errors.New("--traceendpoint is required when using the jaeger trace exporter")
+	}
+	if commonFlags.TraceSampleRatio < 0 || commonFlags.TraceSampleRatio > 1 {
+		return errors.New("--tracesampleratio must be between 0 and 1")
+	}
+
 	return commonFlags.ResolveNetwork(parser)
 }
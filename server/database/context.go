@@ -1,6 +1,8 @@
 package database
 
 import (
+	"context"
+
 	"github.com/go-pg/pg/v9"
 	"github.com/go-pg/pg/v9/orm"
 	"github.com/pkg/errors"
@@ -14,6 +16,8 @@ type DB interface {
 	Insert(model ...interface{}) error
 	Update(model interface{}) error
 	Delete(model interface{}) error
+	Exec(query interface{}, params ...interface{}) (orm.Result, error)
+	Query(model, query interface{}, params ...interface{}) (orm.Result, error)
 	QueryOne(model, query interface{}, params ...interface{}) (orm.Result, error)
 }
 
@@ -24,19 +28,36 @@ type Context interface {
 	DB() (DB, error)
 }
 
-type noTxContext struct{}
+// noTxContext runs queries without a transaction. Queries run
+// in its ctx if it has one, so that they're traced as its children.
+type noTxContext struct {
+	ctx context.Context
+}
 
 // DB returns a db instance
-func (*noTxContext) DB() (DB, error) {
-	return DBInstance()
+func (noTx *noTxContext) DB() (DB, error) {
+	db, err := DBInstance()
+	if err != nil {
+		return nil, err
+	}
+	if noTx.ctx != nil {
+		return db.WithContext(noTx.ctx), nil
+	}
+	return db, nil
 }
 
 // TxContext represents a database context with an attached database transaction
 type TxContext struct {
+	ctx       context.Context
 	tx        *pg.Tx
 	committed bool
 }
 
+// Context returns the context.Context that the transaction runs in
+func (ctx *TxContext) Context() context.Context {
+	return ctx.ctx
+}
+
 // DB returns a db instance
 func (ctx *TxContext) DB() (DB, error) {
 	return ctx.tx, nil
@@ -71,17 +92,48 @@ func NoTx() Context {
 	return noTxContextSingleton
 }
 
+// NoTxWithContext returns an instance of dbaccess.Context without an
+// attached database transaction, whose queries run in the given ctx
+func NoTxWithContext(ctx context.Context) Context {
+	return &noTxContext{ctx: ctx}
+}
+
 // NewTx returns an instance of TxContext with a new database transaction
 func NewTx() (*TxContext, error) {
+	return NewTxWithContext(context.Background())
+}
+
+// NewTxWithContext returns an instance of TxContext with
+// a new database transaction that runs in the given ctx
+func NewTxWithContext(ctx context.Context) (*TxContext, error) {
 	db, err := DBInstance()
 	if err != nil {
 		return nil, err
 	}
 
-	tx, err := db.Begin()
+	tx, err := db.WithContext(ctx).Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TxContext{ctx: ctx, tx: tx}, nil
+}
+
+// NewSnapshotTxWithContext returns an instance of TxContext with a new
+// read-only REPEATABLE READ transaction that runs in the given ctx. All
+// of its queries see the same snapshot of the database, so that reads
+// that span several queries are consistent even if syncd writes meanwhile.
+func NewSnapshotTxWithContext(ctx context.Context) (*TxContext, error) {
+	dbTx, err := NewTxWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = dbTx.tx.Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ, READ ONLY")
 	if err != nil {
+		dbTx.Rollback()
 		return nil, err
 	}
 
-	return &TxContext{tx: tx}, nil
+	return dbTx, nil
 }
@@ -52,6 +52,7 @@ func Connect(cfg *config.CommonConfigFlags) error {
 	}
 
 	db = pg.Connect(connectionOptions)
+	db.AddQueryHook(tracingQueryHook{})
 
 	return validateTimeZone(db)
 }
@@ -82,6 +83,15 @@ func allowedTimezonesString() string {
 	return strings.Join(keys, ", ")
 }
 
+// Listen starts listening for notifications on the given channels
+func Listen(channels ...string) (*pg.Listener, error) {
+	db, err := DBInstance()
+	if err != nil {
+		return nil, err
+	}
+	return db.Listen(channels...), nil
+}
+
 // Close closes the connection to the database
 func Close() error {
 	if db == nil {
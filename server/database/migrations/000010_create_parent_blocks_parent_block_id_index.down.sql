@@ -0,0 +1 @@
+DROP INDEX idx_parent_blocks_parent_block_id;
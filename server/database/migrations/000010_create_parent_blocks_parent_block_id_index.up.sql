@@ -0,0 +1 @@
+CREATE INDEX idx_parent_blocks_parent_block_id ON parent_blocks (parent_block_id);
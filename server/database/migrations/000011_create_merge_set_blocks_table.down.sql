@@ -0,0 +1 @@
+DROP TABLE merge_set_blocks;
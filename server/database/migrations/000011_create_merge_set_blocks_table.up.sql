@@ -0,0 +1,13 @@
+CREATE TABLE merge_set_blocks
+(
+    block_id        BIGINT  NOT NULL,
+    merged_block_id BIGINT  NOT NULL,
+    is_blue         BOOLEAN NOT NULL,
+    PRIMARY KEY (block_id, merged_block_id),
+    CONSTRAINT fk_merge_set_blocks_block_id
+        FOREIGN KEY (block_id)
+            REFERENCES blocks (id),
+    CONSTRAINT fk_merge_set_blocks_merged_block_id
+        FOREIGN KEY (merged_block_id)
+            REFERENCES blocks (id)
+);
@@ -0,0 +1 @@
+DROP INDEX idx_blocks_blue_score;
@@ -0,0 +1 @@
+CREATE INDEX idx_blocks_blue_score ON blocks (blue_score);
@@ -0,0 +1,16 @@
+CREATE TABLE webhook_subscriptions
+(
+    id             BIGSERIAL,
+    url            TEXT                                    NOT NULL,
+    secret         CHAR(64)                                NOT NULL,
+    address        VARCHAR(71)                             NULL,
+    transaction_id CHAR(64)                                NULL,
+    confirmations  BIGINT CHECK (confirmations >= 1)       NULL,
+    created_at     TIMESTAMP(0)                            NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT chk_webhook_subscriptions_address_or_transaction_id
+        CHECK (address IS NOT NULL OR transaction_id IS NOT NULL)
+);
+
+CREATE INDEX idx_webhook_subscriptions_address ON webhook_subscriptions (address);
+CREATE INDEX idx_webhook_subscriptions_transaction_id ON webhook_subscriptions (transaction_id);
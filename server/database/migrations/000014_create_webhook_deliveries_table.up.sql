@@ -0,0 +1,25 @@
+CREATE TABLE webhook_deliveries
+(
+    id                      BIGSERIAL,
+    webhook_subscription_id BIGINT       NOT NULL,
+    event                   VARCHAR(32)  NOT NULL,
+    transaction_id          CHAR(64)     NULL,
+    payload                 TEXT         NOT NULL,
+    status                  VARCHAR(16)  NOT NULL,
+    attempts                INT CHECK (attempts >= 0) NOT NULL,
+    next_attempt_at         TIMESTAMP(0) NOT NULL,
+    last_error              TEXT         NULL,
+    created_at              TIMESTAMP(0) NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_webhook_deliveries_webhook_subscription_id
+        FOREIGN KEY (webhook_subscription_id)
+            REFERENCES webhook_subscriptions (id)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX idx_webhook_deliveries_status_next_attempt_at ON webhook_deliveries (status, next_attempt_at);
+
+-- Confirmation notifications are sent only once per subscription and transaction
+CREATE UNIQUE INDEX idx_webhook_deliveries_confirmed_transaction
+    ON webhook_deliveries (webhook_subscription_id, transaction_id)
+    WHERE event = 'transaction-confirmed';
@@ -0,0 +1,8 @@
+CREATE TABLE events_outbox
+(
+    id         BIGSERIAL,
+    topic      VARCHAR(255) NOT NULL,
+    payload    TEXT         NOT NULL,
+    created_at TIMESTAMP(0) NOT NULL,
+    PRIMARY KEY (id)
+);
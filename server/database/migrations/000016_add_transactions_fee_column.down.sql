@@ -0,0 +1,4 @@
+DROP INDEX idx_transactions_accepting_block_id;
+
+ALTER TABLE transactions
+    DROP COLUMN fee;
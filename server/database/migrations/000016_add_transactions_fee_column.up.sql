@@ -0,0 +1,4 @@
+ALTER TABLE transactions
+    ADD COLUMN fee BIGINT CHECK (fee >= 0) NULL;
+
+CREATE INDEX idx_transactions_accepting_block_id ON transactions (accepting_block_id);
@@ -0,0 +1,2 @@
+DROP INDEX idx_blocks_blue_score_id;
+CREATE INDEX idx_blocks_blue_score ON blocks (blue_score);
@@ -0,0 +1,2 @@
+DROP INDEX idx_blocks_blue_score;
+CREATE INDEX idx_blocks_blue_score_id ON blocks (blue_score, id);
@@ -0,0 +1,3 @@
+CREATE INDEX idx_blocks_block_hash_prefix ON blocks (block_hash bpchar_pattern_ops);
+CREATE INDEX idx_transactions_transaction_id_prefix ON transactions (transaction_id bpchar_pattern_ops);
+CREATE INDEX idx_addresses_address_prefix ON addresses (address varchar_pattern_ops);
@@ -0,0 +1,41 @@
+CREATE TABLE api_key_tiers
+(
+    id                  BIGSERIAL,
+    name                VARCHAR(32)                               NOT NULL,
+    requests_per_second INT CHECK (requests_per_second >= 1)      NOT NULL,
+    burst               INT CHECK (burst >= 1)                    NOT NULL,
+    -- A NULL daily quota means the tier is unlimited
+    daily_quota         BIGINT CHECK (daily_quota >= 0)           NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX idx_api_key_tiers_name ON api_key_tiers (name);
+
+-- The keys themselves are not stored, only the hex-encoded SHA-256 of them
+CREATE TABLE api_keys
+(
+    id               BIGSERIAL,
+    key_hash         CHAR(64)     NOT NULL,
+    name             VARCHAR(64)  NOT NULL,
+    api_key_tier_id  BIGINT       NOT NULL,
+    created_at       TIMESTAMP(0) NOT NULL,
+    revoked_at       TIMESTAMP(0) NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_api_keys_api_key_tier_id
+        FOREIGN KEY (api_key_tier_id)
+            REFERENCES api_key_tiers (id)
+);
+
+CREATE UNIQUE INDEX idx_api_keys_key_hash ON api_keys (key_hash);
+
+CREATE TABLE api_key_usage
+(
+    api_key_id    BIGINT                                NOT NULL,
+    day           DATE                                  NOT NULL,
+    request_count BIGINT CHECK (request_count >= 0)     NOT NULL,
+    PRIMARY KEY (api_key_id, day),
+    CONSTRAINT fk_api_key_usage_api_key_id
+        FOREIGN KEY (api_key_id)
+            REFERENCES api_keys (id)
+            ON DELETE CASCADE
+);
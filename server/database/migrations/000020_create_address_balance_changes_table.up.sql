@@ -0,0 +1,62 @@
+-- Every row is the change in the balance of an address in an accepted
+-- transaction, and the balance of the address right after it. Rows are
+-- ordered by the blue score of the accepting block and then by transaction ID.
+CREATE TABLE address_balance_changes
+(
+    address_id         BIGINT       NOT NULL,
+    transaction_id     BIGINT       NOT NULL,
+    accepting_block_id BIGINT       NOT NULL,
+    blue_score         BIGINT       NOT NULL,
+    timestamp          TIMESTAMP(0) NOT NULL,
+    value_change       BIGINT       NOT NULL,
+    balance            BIGINT       NOT NULL,
+    PRIMARY KEY (address_id, transaction_id),
+    CONSTRAINT fk_address_balance_changes_address_id
+        FOREIGN KEY (address_id)
+            REFERENCES addresses (id),
+    CONSTRAINT fk_address_balance_changes_transaction_id
+        FOREIGN KEY (transaction_id)
+            REFERENCES transactions (id),
+    CONSTRAINT fk_address_balance_changes_accepting_block_id
+        FOREIGN KEY (accepting_block_id)
+            REFERENCES blocks (id)
+);
+
+CREATE INDEX idx_address_balance_changes_address_id_blue_score
+    ON address_balance_changes (address_id, blue_score, transaction_id);
+CREATE INDEX idx_address_balance_changes_accepting_block_id ON address_balance_changes (accepting_block_id);
+
+-- Fill the table from the transactions that are already accepted
+INSERT INTO address_balance_changes (address_id, transaction_id, accepting_block_id, blue_score, timestamp,
+                                     value_change, balance)
+SELECT changes.address_id,
+       changes.transaction_id,
+       changes.accepting_block_id,
+       changes.blue_score,
+       changes.timestamp,
+       changes.value_change,
+       SUM(changes.value_change) OVER (PARTITION BY changes.address_id
+           ORDER BY changes.blue_score, changes.transaction_id)
+FROM (
+         SELECT address_values.address_id,
+                address_values.transaction_id,
+                accepting_blocks.id                AS accepting_block_id,
+                accepting_blocks.blue_score,
+                accepting_blocks.timestamp,
+                SUM(address_values.value)::BIGINT AS value_change
+         FROM (
+                  SELECT transaction_outputs.address_id, transaction_outputs.transaction_id, transaction_outputs.value
+                  FROM transaction_outputs
+                  WHERE transaction_outputs.address_id IS NOT NULL
+                  UNION ALL
+                  SELECT previous_outputs.address_id, transaction_inputs.transaction_id, -previous_outputs.value
+                  FROM transaction_inputs
+                           INNER JOIN transaction_outputs AS previous_outputs
+                                      ON previous_outputs.id = transaction_inputs.previous_transaction_output_id
+                  WHERE previous_outputs.address_id IS NOT NULL
+              ) AS address_values
+                  INNER JOIN transactions ON transactions.id = address_values.transaction_id
+                  INNER JOIN blocks AS accepting_blocks ON accepting_blocks.id = transactions.accepting_block_id
+         GROUP BY address_values.address_id, address_values.transaction_id, accepting_blocks.id
+         HAVING SUM(address_values.value) != 0
+     ) AS changes;
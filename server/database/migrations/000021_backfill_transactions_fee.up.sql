@@ -0,0 +1,30 @@
+-- Set the fees of the transactions that were accepted before the fee column
+-- was added. Like in syncd, transactions without inputs (coinbase transactions)
+-- and transactions with inputs whose previous outputs are unknown are skipped.
+UPDATE transactions
+SET fee = fees.fee
+FROM (
+         SELECT inputs.transaction_id,
+                inputs.value - COALESCE(outputs.value, 0) AS fee
+         FROM (
+                  SELECT transaction_inputs.transaction_id,
+                         SUM(previous_outputs.value)  AS value,
+                         COUNT(*)                     AS input_count,
+                         COUNT(previous_outputs.id)   AS known_input_count
+                  FROM transaction_inputs
+                           LEFT JOIN transaction_outputs AS previous_outputs
+                                     ON previous_outputs.id = transaction_inputs.previous_transaction_output_id
+                  GROUP BY transaction_inputs.transaction_id
+              ) AS inputs
+                  LEFT JOIN (
+             SELECT transaction_outputs.transaction_id,
+                    SUM(transaction_outputs.value) AS value
+             FROM transaction_outputs
+             GROUP BY transaction_outputs.transaction_id
+         ) AS outputs ON outputs.transaction_id = inputs.transaction_id
+         WHERE inputs.input_count = inputs.known_input_count
+     ) AS fees
+WHERE transactions.id = fees.transaction_id
+  AND transactions.accepting_block_id IS NOT NULL
+  AND transactions.fee IS NULL
+  AND fees.fee >= 0;
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/someone235/katnip/server/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingQueryHook traces every query as a child of the
+// span in the context.Context that the query runs in
+type tracingQueryHook struct{}
+
+func (tracingQueryHook) BeforeQuery(ctx context.Context, event *pg.QueryEvent) (context.Context, error) {
+	// The statement is traced without its parameters,
+	// so that no data of the query ends up in the traces
+	statement, err := event.UnformattedQuery()
+	if err != nil {
+		statement = ""
+	}
+	ctx, _ = tracing.Tracer().Start(ctx, "query",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBStatementKey.String(statement)))
+	return ctx, nil
+}
+
+func (tracingQueryHook) AfterQuery(ctx context.Context, event *pg.QueryEvent) error {
+	err := event.Err
+	if err == pg.ErrNoRows {
+		// Not finding rows is an expected result
+		err = nil
+	}
+	tracing.EndSpan(trace.SpanFromContext(ctx), err)
+	return nil
+}
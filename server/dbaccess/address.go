@@ -27,3 +27,25 @@ func AddressesByAddressStrings(ctx database.Context, addressStrings []string, pr
 
 	return addresses, nil
 }
+
+// AddressesByIDs retrieves all addresses by their database IDs.
+func AddressesByIDs(ctx database.Context, ids []uint64) ([]*dbmodels.Address, error) {
+	if len(ids) == 0 {
+		return []*dbmodels.Address{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []*dbmodels.Address
+	err = db.Model(&addresses).
+		Where("id IN (?)", pg.In(ids)).
+		Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
@@ -0,0 +1,157 @@
+package dbaccess
+
+import (
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+// AddressBalancesByAddressIDs returns the current balances of the addresses
+// with the given database IDs. Addresses without balance changes are omitted.
+func AddressBalancesByAddressIDs(ctx database.Context, addressIDs []uint64) (map[uint64]int64, error) {
+	if len(addressIDs) == 0 {
+		return map[uint64]int64{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	// Each address reads only its last balance change, from
+	// the end of the (address_id, blue_score, transaction_id) index
+	var lastBalances []struct {
+		AddressID uint64
+		Balance   int64
+	}
+	_, err = db.Query(&lastBalances, `
+		SELECT address_ids.address_id, last_balance_changes.balance
+		FROM unnest(?::BIGINT[]) AS address_ids(address_id)
+		CROSS JOIN LATERAL (
+			SELECT address_balance_changes.balance
+			FROM address_balance_changes
+			WHERE address_balance_changes.address_id = address_ids.address_id
+			ORDER BY address_balance_changes.blue_score DESC, address_balance_changes.transaction_id DESC
+			LIMIT 1
+		) AS last_balance_changes`,
+		pg.Array(addressIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[uint64]int64, len(lastBalances))
+	for _, lastBalance := range lastBalances {
+		balances[lastBalance.AddressID] = lastBalance.Balance
+	}
+	return balances, nil
+}
+
+// DeleteAddressBalanceChangesByAcceptingBlockID deletes the balance changes of
+// the transactions that were accepted by the block with the given ID
+func DeleteAddressBalanceChangesByAcceptingBlockID(ctx database.Context, acceptingBlockID uint64) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(&dbmodels.AddressBalanceChange{}).
+		Where("accepting_block_id = ?", acceptingBlockID).
+		Delete()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddressBalanceAtBlueScore returns the balance of `address` from the
+// transactions that were accepted by blocks with blue score up to `blueScore`
+func AddressBalanceAtBlueScore(ctx database.Context, address string, blueScore uint64) (int64, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		Balance int64
+	}
+	_, err = db.QueryOne(&result, `
+		SELECT COALESCE((
+			SELECT address_balance_changes.balance
+			FROM address_balance_changes
+			INNER JOIN addresses ON addresses.id = address_balance_changes.address_id
+			WHERE addresses.address = ?
+			AND address_balance_changes.blue_score <= ?
+			ORDER BY address_balance_changes.blue_score DESC, address_balance_changes.transaction_id DESC
+			LIMIT 1
+		), 0) AS balance`,
+		address, blueScore)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.Balance, nil
+}
+
+// AddressBalancesAtTimes returns the balance of `address` at each of the given
+// times, from the transactions that were accepted by blocks with timestamps up
+// to that time. The balances are returned in the order of the given times.
+func AddressBalancesAtTimes(ctx database.Context, address string, times []time.Time) ([]int64, error) {
+	if len(times) == 0 {
+		return []int64{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var balances []int64
+	_, err = db.Query(&balances, `
+		SELECT COALESCE((
+			SELECT address_balance_changes.balance
+			FROM address_balance_changes
+			WHERE address_balance_changes.address_id = addresses.id
+			AND address_balance_changes.timestamp <= boundaries.boundary_time
+			ORDER BY address_balance_changes.blue_score DESC, address_balance_changes.transaction_id DESC
+			LIMIT 1
+		), 0) AS balance
+		FROM unnest(?::TIMESTAMP[]) WITH ORDINALITY AS boundaries(boundary_time, boundary_index)
+		LEFT JOIN addresses ON addresses.address = ?
+		ORDER BY boundaries.boundary_index`,
+		pg.Array(times), address)
+	if err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+// AddressFirstBalanceChangeTime returns the timestamp of the block that accepted
+// the first transaction of `address`, or nil if it has no accepted transactions
+func AddressFirstBalanceChangeTime(ctx database.Context, address string) (*time.Time, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var timestamp time.Time
+	err = db.Model(&dbmodels.AddressBalanceChange{}).
+		ColumnExpr("address_balance_change.timestamp").
+		Join("INNER JOIN addresses").
+		JoinOn("addresses.id = address_balance_change.address_id").
+		Where("addresses.address = ?", address).
+		Order("address_balance_change.blue_score ASC", "address_balance_change.transaction_id ASC").
+		Limit(1).
+		Select(&timestamp)
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &timestamp, nil
+}
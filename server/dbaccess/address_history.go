@@ -0,0 +1,81 @@
+package dbaccess
+
+import (
+	"time"
+
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+// AddressHistoryEntry is the change in the balance of an address
+// in an accepted transaction that it's either an input or an output of
+type AddressHistoryEntry struct {
+	ID                      uint64
+	TransactionID           string
+	Fee                     *uint64
+	AcceptingBlockBlueScore uint64
+	AcceptingBlockTimestamp time.Time
+	NetValue                int64
+}
+
+// AddressHistoryAfterCursor returns up to `limit` of the accepted transactions of `address`
+// whose accepting blocks have timestamps between `fromTime` and `toTime` (inclusive, and
+// unbounded when nil), ordered by the blue scores of their accepting blocks and then by ID,
+// starting after the given cursor. If cursor is nil, starts from the first transaction.
+func AddressHistoryAfterCursor(ctx database.Context, address string, fromTime *time.Time, toTime *time.Time,
+	cursor *Cursor, limit uint64) ([]*AddressHistoryEntry, error) {
+
+	if limit == 0 {
+		return []*AddressHistoryEntry{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &AddressTransactionsFilter{
+		FromTime:     fromTime,
+		ToTime:       toTime,
+		AcceptedOnly: true,
+	}
+	query := selectTransactionsByAddress(db.Model(&dbmodels.Transaction{}), address, filter, nil).
+		ColumnExpr("transaction.id, transaction.transaction_id, transaction.fee").
+		ColumnExpr("accepting_blocks.blue_score AS accepting_block_blue_score").
+		ColumnExpr("accepting_blocks.timestamp AS accepting_block_timestamp").
+		ColumnExpr("address_net_values.net_value").
+		Order("accepting_blocks.blue_score ASC", "transaction.id ASC").
+		Limit(int(limit))
+
+	if cursor != nil {
+		query = query.Where("(accepting_blocks.blue_score, transaction.id) > (?, ?)", cursor.BlueScore, cursor.ID)
+	}
+
+	var entries []*AddressHistoryEntry
+	err = query.Select(&entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// AddressBalanceBeforeCursor returns the balance of `address` from the accepted
+// transactions that come before the given cursor, in the order of AddressHistoryAfterCursor
+func AddressBalanceBeforeCursor(ctx database.Context, address string, cursor *Cursor) (int64, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	var balance int64
+	err = selectTransactionsByAddress(db.Model(&dbmodels.Transaction{}), address, &AddressTransactionsFilter{AcceptedOnly: true}, nil).
+		ColumnExpr("COALESCE(SUM(address_net_values.net_value), 0) AS balance").
+		Where("(accepting_blocks.blue_score, transaction.id) < (?, ?)", cursor.BlueScore, cursor.ID).
+		Select(&balance)
+	if err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
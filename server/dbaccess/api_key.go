@@ -0,0 +1,76 @@
+package dbaccess
+
+import (
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+// APIKeyByHash retrieves the API key whose hex-encoded SHA-256 is the given
+// hash, alongside its tier. Revoked keys are not returned.
+func APIKeyByHash(ctx database.Context, keyHash string) (*dbmodels.APIKey, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := &dbmodels.APIKey{}
+	query := db.Model(apiKey)
+	query = preloadFields(query, []dbmodels.FieldName{dbmodels.APIKeyFieldNames.APIKeyTier})
+	err = query.
+		Where("key_hash = ?", keyHash).
+		Where("revoked_at IS NULL").
+		First()
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+// APIKeyRequestCount returns the number of requests
+// that were made with the given API key on the given day
+func APIKeyRequestCount(ctx database.Context, apiKeyID uint64, day time.Time) (uint64, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	usage := &dbmodels.APIKeyUsage{}
+	err = db.Model(usage).
+		Where("api_key_id = ?", apiKeyID).
+		Where("day = ?", day).
+		First()
+	if err == pg.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return usage.RequestCount, nil
+}
+
+// AddAPIKeyRequestCount adds `count` requests to the
+// usage of the given API key on the given day
+func AddAPIKeyRequestCount(ctx database.Context, apiKeyID uint64, day time.Time, count uint64) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(&dbmodels.APIKeyUsage{
+		APIKeyID:     apiKeyID,
+		Day:          day,
+		RequestCount: count,
+	}).
+		OnConflict("(api_key_id, day) DO UPDATE").
+		Set("request_count = api_key_usage.request_count + EXCLUDED.request_count").
+		Insert()
+	return err
+}
@@ -53,6 +53,88 @@ func BlocksByHashes(ctx database.Context, hashes []string, preloadedFields ...db
 	return blocks, nil
 }
 
+// BlocksByIDs retrieves all blocks by their database IDs.
+// If preloadedFields was provided - preloads the requested fields
+func BlocksByIDs(ctx database.Context, ids []uint64, preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Block, error) {
+	if len(ids) == 0 {
+		return []*dbmodels.Block{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []*dbmodels.Block
+	query := db.Model(&blocks).Where("block.id IN (?)", pg.In(ids))
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// ParentBlocksByBlockIDs retrieves the block-parent relations of all the blocks with the given IDs
+func ParentBlocksByBlockIDs(ctx database.Context, blockIDs []uint64) ([]*dbmodels.ParentBlock, error) {
+	return parentBlocksByColumn(ctx, "block_id", blockIDs)
+}
+
+// ParentBlocksByParentBlockIDs retrieves the block-parent relations of all
+// the blocks that have one of the blocks with the given IDs as a parent
+func ParentBlocksByParentBlockIDs(ctx database.Context, parentBlockIDs []uint64) ([]*dbmodels.ParentBlock, error) {
+	return parentBlocksByColumn(ctx, "parent_block_id", parentBlockIDs)
+}
+
+func parentBlocksByColumn(ctx database.Context, column string, ids []uint64) ([]*dbmodels.ParentBlock, error) {
+	if len(ids) == 0 {
+		return []*dbmodels.ParentBlock{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var parentBlocks []*dbmodels.ParentBlock
+	err = db.Model(&parentBlocks).
+		Where(fmt.Sprintf("%s IN (?)", column), pg.In(ids)).
+		Order("block_id ASC", "parent_block_id ASC").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return parentBlocks, nil
+}
+
+// ChildBlocksByHash retrieves all the blocks that have the block with the given
+// `blockHash` as one of their parents
+// If preloadedFields was provided - preloads the requested fields
+func ChildBlocksByHash(ctx database.Context, blockHash string, preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Block, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []*dbmodels.Block
+	query := db.Model(&blocks).
+		Join("INNER JOIN parent_blocks").
+		JoinOn("parent_blocks.block_id = block.id").
+		Join("INNER JOIN blocks AS parents").
+		JoinOn("parents.id = parent_blocks.parent_block_id").
+		Where("parents.block_hash = ?", blockHash).
+		Order("block.id ASC")
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
 // ExistingHashes filters out the non existing hashes from the given list
 func ExistingHashes(ctx database.Context, hashes []string) ([]string, error) {
 	if len(hashes) == 0 {
@@ -108,6 +190,40 @@ func Blocks(ctx database.Context, order Order, skip uint64, limit uint64,
 	return blocks, nil
 }
 
+// BlocksAfterCursor returns up to `limit` blocks ordered by blue score and ID in the given
+// order, starting after the given cursor. If cursor is nil, starts from the first block.
+// If preloadedFields was provided - preloads the requested fields
+func BlocksAfterCursor(ctx database.Context, order Order, cursor *Cursor, limit uint64,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Block, error) {
+
+	if limit == 0 {
+		return []*dbmodels.Block{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []*dbmodels.Block
+	query := db.Model(&blocks).
+		Order(fmt.Sprintf("block.blue_score %s", order), fmt.Sprintf("block.id %s", order)).
+		Limit(int(limit))
+
+	if cursor != nil {
+		query = query.Where(fmt.Sprintf("(block.blue_score, block.id) %s (?, ?)", keysetOperator(order)),
+			cursor.BlueScore, cursor.ID)
+	}
+
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
 // SelectedTip fetches the selected tip from the database
 func SelectedTip(ctx database.Context) (*dbmodels.Block, error) {
 	db, err := ctx.DB()
@@ -0,0 +1,18 @@
+package dbaccess
+
+// Cursor is a position in a list of rows that are ordered by blue score and
+// then by ID. Since both are immutable, a page that starts after a cursor
+// isn't shifted by rows that are inserted concurrently before it.
+type Cursor struct {
+	BlueScore uint64
+	ID        uint64
+}
+
+// keysetOperator returns the comparison operator that
+// selects the rows that come after a cursor in the given order
+func keysetOperator(order Order) string {
+	if order == OrderDescending {
+		return "<"
+	}
+	return ">"
+}
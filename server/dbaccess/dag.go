@@ -0,0 +1,66 @@
+package dbaccess
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+// DAGBlocksByBlueScoreRange retrieves up to `limit` blocks with blue score
+// between `fromBlueScore` and `toBlueScore` (inclusive), ordered by blue score.
+func DAGBlocksByBlueScoreRange(ctx database.Context, fromBlueScore uint64, toBlueScore uint64, limit uint64) ([]*dbmodels.DAGBlock, error) {
+	if limit == 0 {
+		return []*dbmodels.DAGBlock{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var dagBlocks []*dbmodels.DAGBlock
+	err = db.Model(&dbmodels.Block{}).
+		ColumnExpr("block.id, block.block_hash, block.blue_score, block.timestamp, block.is_chain_block").
+		ColumnExpr("merge_set_blocks.is_blue").
+		Join("LEFT JOIN merge_set_blocks").
+		JoinOn("merge_set_blocks.block_id = block.accepting_block_id").
+		JoinOn("merge_set_blocks.merged_block_id = block.id").
+		Where("block.blue_score >= ?", fromBlueScore).
+		Where("block.blue_score <= ?", toBlueScore).
+		Order("block.blue_score ASC", "block.id ASC").
+		Limit(int(limit)).
+		Select(&dagBlocks)
+	if err != nil {
+		return nil, err
+	}
+
+	return dagBlocks, nil
+}
+
+// DAGEdgesByBlockIDs retrieves the edges between the blocks with the given
+// `blockIDs` and their parents
+func DAGEdgesByBlockIDs(ctx database.Context, blockIDs []uint64) ([]*dbmodels.DAGEdge, error) {
+	if len(blockIDs) == 0 {
+		return []*dbmodels.DAGEdge{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var dagEdges []*dbmodels.DAGEdge
+	err = db.Model(&dbmodels.ParentBlock{}).
+		ColumnExpr("blocks.block_hash, parents.block_hash AS parent_block_hash").
+		Join("INNER JOIN blocks").
+		JoinOn("blocks.id = parent_block.block_id").
+		Join("INNER JOIN blocks AS parents").
+		JoinOn("parents.id = parent_block.parent_block_id").
+		Where("parent_block.block_id IN (?)", pg.In(blockIDs)).
+		Select(&dagEdges)
+	if err != nil {
+		return nil, err
+	}
+
+	return dagEdges, nil
+}
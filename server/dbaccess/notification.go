@@ -0,0 +1,66 @@
+package dbaccess
+
+import (
+	"encoding/json"
+
+	"github.com/someone235/katnip/server/database"
+)
+
+// EventsChannel is the Postgres notification channel on which
+// syncd announces the changes it made to the database
+const EventsChannel = "katnip_events"
+
+// Events announced on EventsChannel
+const (
+	// EventBlocksAdded announces blocks that were added to the database
+	EventBlocksAdded = "blocks-added"
+
+	// EventChainBlocksAdded announces blocks that were added to the
+	// selected parent chain, in chain order
+	EventChainBlocksAdded = "chain-blocks-added"
+)
+
+// Postgres limits notification payloads to 8000 bytes,
+// so the block hashes are split between several notifications
+const maxEventNotificationBlockHashes = 100
+
+// EventNotification is the payload of a notification on EventsChannel
+type EventNotification struct {
+	Event       string   `json:"event"`
+	BlockHashes []string `json:"blockHashes"`
+
+	// IsLast is whether this is the last of the notifications
+	// that the block hashes of the event were split between
+	IsLast bool `json:"isLast"`
+}
+
+// NotifyEvent announces the given event on EventsChannel. When called
+// within a database transaction, the notification is sent only once
+// the transaction is committed.
+func NotifyEvent(ctx database.Context, event string, blockHashes []string) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(blockHashes); start += maxEventNotificationBlockHashes {
+		end := start + maxEventNotificationBlockHashes
+		if end > len(blockHashes) {
+			end = len(blockHashes)
+		}
+		payload, err := json.Marshal(&EventNotification{
+			Event:       event,
+			BlockHashes: blockHashes[start:end],
+			IsLast:      end == len(blockHashes),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = db.Exec("SELECT pg_notify(?, ?)", EventsChannel, string(payload))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -25,3 +25,15 @@ func StringToOrder(orderString string) (Order, error) {
 	}
 	return order, nil
 }
+
+// Reverse returns the opposite order of the given order
+func (order Order) Reverse() Order {
+	switch order {
+	case OrderAscending:
+		return OrderDescending
+	case OrderDescending:
+		return OrderAscending
+	default:
+		return OrderUnknown
+	}
+}
@@ -0,0 +1,74 @@
+package dbaccess
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+// InsertOutboxEvents adds the given events to the events outbox
+func InsertOutboxEvents(ctx database.Context, events []*dbmodels.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(&events).Insert()
+	return err
+}
+
+// OutboxEvents retrieves up to `limit` events from the
+// events outbox, ordered by their sequence number
+func OutboxEvents(ctx database.Context, limit uint64) ([]*dbmodels.OutboxEvent, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*dbmodels.OutboxEvent
+	err = db.Model(&events).
+		Order("id ASC").
+		Limit(int(limit)).
+		Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// DeleteOutboxEvents removes the events with the given IDs from the events outbox
+func DeleteOutboxEvents(ctx database.Context, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(&dbmodels.OutboxEvent{}).
+		Where("id IN (?)", pg.In(ids)).
+		Delete()
+	return err
+}
+
+// OutboxEventsCount returns the number of events waiting in the events outbox
+func OutboxEventsCount(ctx database.Context) (uint64, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := db.Model(&dbmodels.OutboxEvent{}).Count()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(count), nil
+}
@@ -0,0 +1,77 @@
+package dbaccess
+
+import (
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+// The prefixes passed to the functions in this file are matched with LIKE,
+// so they must not contain LIKE wildcards. The prefix indexes on the searched
+// columns only serve LIKE when the pattern is anchored to the start, which is
+// why only prefixes are supported.
+
+// BlockHashesByPrefix returns up to `limit` hashes of
+// blocks whose hash starts with the given prefix
+func BlockHashesByPrefix(ctx database.Context, prefix string, limit uint64) ([]string, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var blockHashes []string
+	err = db.Model(&dbmodels.Block{}).
+		Column("block_hash").
+		Where("block_hash LIKE ?", prefix+"%").
+		Order("block_hash ASC").
+		Limit(int(limit)).
+		Select(&blockHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	return blockHashes, nil
+}
+
+// TransactionIDsByPrefix returns up to `limit` IDs of transactions
+// whose ID starts with the given prefix
+func TransactionIDsByPrefix(ctx database.Context, prefix string, limit uint64) ([]string, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var transactionIDs []string
+	err = db.Model(&dbmodels.Transaction{}).
+		Column("transaction_id").
+		Where("transaction_id LIKE ?", prefix+"%").
+		Order("transaction_id ASC").
+		Limit(int(limit)).
+		Select(&transactionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return transactionIDs, nil
+}
+
+// AddressesByPrefix returns up to `limit` addresses
+// that start with the given prefix
+func AddressesByPrefix(ctx database.Context, prefix string, limit uint64) ([]string, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []string
+	err = db.Model(&dbmodels.Address{}).
+		Column("address").
+		Where("address LIKE ?", prefix+"%").
+		Order("address ASC").
+		Limit(int(limit)).
+		Select(&addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
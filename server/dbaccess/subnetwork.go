@@ -29,3 +29,25 @@ func SubnetworksByIDs(ctx database.Context, subnetworkIDs []string, preloadedFie
 
 	return subnetworks, nil
 }
+
+// SubnetworksByDatabaseIDs retrieves all subnetworks by their database IDs.
+func SubnetworksByDatabaseIDs(ctx database.Context, ids []uint64) ([]*dbmodels.Subnetwork, error) {
+	if len(ids) == 0 {
+		return []*dbmodels.Subnetwork{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var subnetworks []*dbmodels.Subnetwork
+	err = db.Model(&subnetworks).
+		Where("id IN (?)", pg.In(ids)).
+		Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return subnetworks, nil
+}
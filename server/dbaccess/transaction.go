@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/go-pg/pg/v9"
-	"github.com/go-pg/pg/v9/orm"
 	"github.com/someone235/katnip/server/database"
 	"github.com/someone235/katnip/server/dbmodels"
 )
@@ -56,11 +55,11 @@ func TransactionByHash(ctx database.Context, transactionHash string, preloadedFi
 	return tx, nil
 }
 
-// TransactionsByAddress retrieves up to `limit` transactions sent to or from `address`,
-// in the requested `order`, skipping the first `skip` blocks
+// TransactionsByAddress retrieves up to `limit` transactions sent to or from `address`
+// that match the given filter, in the requested `order`, skipping the first `skip` blocks
 // If preloadedFields was provided - preloads the requested fields
-func TransactionsByAddress(ctx database.Context, address string, order Order, skip uint64, limit uint64, preloadedFields ...dbmodels.FieldName) (
-	[]*dbmodels.Transaction, error) {
+func TransactionsByAddress(ctx database.Context, address string, filter *AddressTransactionsFilter, order Order,
+	skip uint64, limit uint64, preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Transaction, error) {
 
 	if limit == 0 {
 		return []*dbmodels.Transaction{}, nil
@@ -73,10 +72,8 @@ func TransactionsByAddress(ctx database.Context, address string, order Order, sk
 
 	var txs []*dbmodels.Transaction
 	query := db.Model(&txs)
-	query = joinTxInputsTxOutputsAndAddresses(query).
-		DistinctOn("transaction.id").
-		Where("out_addresses.address = ?", address).
-		WhereOr("in_addresses.address = ?", address).
+	page := &transactionsPage{order: order, skip: skip, limit: limit}
+	query = selectTransactionsByAddress(query, address, filter, page).
 		Limit(int(limit)).
 		Offset(int(skip))
 
@@ -93,6 +90,224 @@ func TransactionsByAddress(ctx database.Context, address string, order Order, sk
 	return txs, nil
 }
 
+// TransactionsByAddressAfterCursor returns up to `limit` transactions sent to or from
+// `address` that match the given filter, ordered by ID in the given order and starting
+// after the given cursor.
+// Transactions are ordered by ID alone, since the blue score at which they are
+// accepted may change, so the blue score of the cursor is ignored.
+// If cursor is nil, starts from the first transaction.
+// If preloadedFields was provided - preloads the requested fields
+func TransactionsByAddressAfterCursor(ctx database.Context, address string, filter *AddressTransactionsFilter,
+	order Order, cursor *Cursor, limit uint64, preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Transaction, error) {
+
+	if limit == 0 {
+		return []*dbmodels.Transaction{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var txs []*dbmodels.Transaction
+	query := db.Model(&txs)
+	page := &transactionsPage{order: order, cursor: cursor, limit: limit}
+	query = selectTransactionsByAddress(query, address, filter, page).
+		Order(fmt.Sprintf("transaction.id %s", order)).
+		Limit(int(limit))
+
+	if cursor != nil {
+		query = query.Where(fmt.Sprintf("transaction.id %s ?", keysetOperator(order)), cursor.ID)
+	}
+
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+// AddressTransaction is a transaction that
+// the address is either an input or an output of
+type AddressTransaction struct {
+	Address string
+	// TransactionID is the database ID of the transaction
+	TransactionID uint64
+}
+
+// AddressTransactionsAfterCursor returns the transactions where any of `addresses` is
+// either an input or an output, for up to `limit` transactions ordered by ID in the
+// given order and starting after the given cursor. A transaction is returned once for
+// every one of the addresses it involves. The blue score of the cursor is ignored.
+// If cursor is nil, starts from the first transaction.
+func AddressTransactionsAfterCursor(ctx database.Context, addresses []string, order Order, cursor *Cursor,
+	limit uint64) ([]*AddressTransaction, error) {
+
+	if len(addresses) == 0 || limit == 0 {
+		return []*AddressTransaction{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var outputsCursorCondition, inputsCursorCondition string
+	cursorID := uint64(0)
+	if cursor != nil {
+		operator := keysetOperator(order)
+		outputsCursorCondition = fmt.Sprintf("AND transaction_outputs.transaction_id %s ?2", operator)
+		inputsCursorCondition = fmt.Sprintf("AND transaction_inputs.transaction_id %s ?2", operator)
+		cursorID = cursor.ID
+	}
+
+	// The page is limited by distinct transactions rather than by
+	// address-transaction pairs, so that a transaction that involves
+	// several addresses isn't split between pages. The cursor and the
+	// limit are applied in each branch of the union, so that a page
+	// doesn't read the whole history of the addresses, and only the
+	// transactions of the page are joined back to their addresses.
+	var addressTransactions []*AddressTransaction
+	_, err = db.Query(&addressTransactions, fmt.Sprintf(`
+		WITH page AS (
+			SELECT page_candidates.transaction_id
+			FROM (
+				(
+					SELECT DISTINCT transaction_outputs.transaction_id
+					FROM addresses
+					INNER JOIN transaction_outputs ON transaction_outputs.address_id = addresses.id
+					WHERE addresses.address IN (?0)
+					%[1]s
+					ORDER BY transaction_outputs.transaction_id %[3]s
+					LIMIT ?1
+				)
+				UNION
+				(
+					SELECT DISTINCT transaction_inputs.transaction_id
+					FROM addresses
+					INNER JOIN transaction_outputs ON transaction_outputs.address_id = addresses.id
+					INNER JOIN transaction_inputs ON transaction_inputs.previous_transaction_output_id = transaction_outputs.id
+					WHERE addresses.address IN (?0)
+					%[2]s
+					ORDER BY transaction_inputs.transaction_id %[3]s
+					LIMIT ?1
+				)
+			) AS page_candidates
+			ORDER BY page_candidates.transaction_id %[3]s
+			LIMIT ?1
+		)
+		SELECT address_transactions.address, address_transactions.transaction_id
+		FROM (
+			SELECT addresses.address, transaction_outputs.transaction_id
+			FROM page
+			INNER JOIN transaction_outputs ON transaction_outputs.transaction_id = page.transaction_id
+			INNER JOIN addresses ON addresses.id = transaction_outputs.address_id
+			WHERE addresses.address IN (?0)
+			UNION
+			SELECT addresses.address, transaction_inputs.transaction_id
+			FROM page
+			INNER JOIN transaction_inputs ON transaction_inputs.transaction_id = page.transaction_id
+			INNER JOIN transaction_outputs ON transaction_outputs.id = transaction_inputs.previous_transaction_output_id
+			INNER JOIN addresses ON addresses.id = transaction_outputs.address_id
+			WHERE addresses.address IN (?0)
+		) AS address_transactions
+		ORDER BY address_transactions.transaction_id %[3]s`, outputsCursorCondition, inputsCursorCondition, order),
+		pg.In(addresses), limit, cursorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return addressTransactions, nil
+}
+
+// TransactionsByDatabaseIDs retrieves all transactions by their database IDs.
+// If preloadedFields was provided - preloads the requested fields
+func TransactionsByDatabaseIDs(ctx database.Context, ids []uint64, preloadedFields ...dbmodels.FieldName) ([]*dbmodels.Transaction, error) {
+	if len(ids) == 0 {
+		return []*dbmodels.Transaction{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions []*dbmodels.Transaction
+	query := db.Model(&transactions).
+		Where("transaction.id IN (?)", pg.In(ids))
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// TransactionBlocksByBlockIDs retrieves the transaction-block relations of all
+// the blocks with the given IDs, ordered by the index of the transactions in their blocks
+func TransactionBlocksByBlockIDs(ctx database.Context, blockIDs []uint64) ([]*dbmodels.TransactionBlock, error) {
+	return transactionBlocksByColumn(ctx, "block_id", blockIDs)
+}
+
+// TransactionBlocksByTransactionIDs retrieves the transaction-block relations
+// of all the transactions with the given database IDs
+func TransactionBlocksByTransactionIDs(ctx database.Context, transactionIDs []uint64) ([]*dbmodels.TransactionBlock, error) {
+	return transactionBlocksByColumn(ctx, "transaction_id", transactionIDs)
+}
+
+func transactionBlocksByColumn(ctx database.Context, column string, ids []uint64) ([]*dbmodels.TransactionBlock, error) {
+	if len(ids) == 0 {
+		return []*dbmodels.TransactionBlock{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var transactionBlocks []*dbmodels.TransactionBlock
+	err = db.Model(&transactionBlocks).
+		Where(fmt.Sprintf("%s IN (?)", column), pg.In(ids)).
+		Order("block_id ASC", "index ASC").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return transactionBlocks, nil
+}
+
+// TransactionInputsByTransactionIDs retrieves the inputs of all the transactions with
+// the given database IDs, ordered by their index
+// If preloadedFields was provided - preloads the requested fields
+func TransactionInputsByTransactionIDs(ctx database.Context, transactionIDs []uint64,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.TransactionInput, error) {
+
+	if len(transactionIDs) == 0 {
+		return []*dbmodels.TransactionInput{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var transactionInputs []*dbmodels.TransactionInput
+	query := db.Model(&transactionInputs).
+		Where("transaction_input.transaction_id IN (?)", pg.In(transactionIDs)).
+		Order("transaction_input.transaction_id ASC", "transaction_input.index ASC")
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return transactionInputs, nil
+}
+
 // TransactionsByAddressCount returns the total number of transactions sent to or from `address`
 func TransactionsByAddressCount(ctx database.Context, address string) (uint64, error) {
 	db, err := ctx.DB()
@@ -370,16 +585,55 @@ func UpdateTransactionAcceptingBlockID(ctx database.Context, transactionID uint6
 	return nil
 }
 
-func joinTxInputsTxOutputsAndAddresses(query *orm.Query) *orm.Query {
-	return query.
-		Join("LEFT JOIN transaction_outputs").
-		JoinOn("transaction_outputs.transaction_id = transaction.id").
-		Join("LEFT JOIN addresses AS out_addresses").
-		JoinOn("out_addresses.id = transaction_outputs.address_id").
-		Join("LEFT JOIN transaction_inputs").
-		JoinOn("transaction_inputs.transaction_id = transaction.id").
-		Join("LEFT JOIN transaction_outputs AS inputs_outs").
-		JoinOn("inputs_outs.id = transaction_inputs.previous_transaction_output_id").
-		Join("LEFT JOIN addresses AS in_addresses").
-		JoinOn("in_addresses.id = inputs_outs.address_id")
+// UpdateTransactionFee sets the fee of the transaction with the given ID
+func UpdateTransactionFee(ctx database.Context, transactionID uint64, fee uint64) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+	_, err = db.
+		Model(&dbmodels.Transaction{}).
+		Where("id = ?", transactionID).
+		Set("fee = ?", fee).
+		Update()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AcceptedTransactionFeeRatePercentiles returns the number of transactions with
+// a known fee that were accepted by chain blocks with a blue score higher than
+// `fromBlueScore`, and the given percentiles, as fractions between 0 and 1, of
+// their fee rates in sompi per gram of mass. The percentiles are nil if there
+// are no such transactions.
+func AcceptedTransactionFeeRatePercentiles(ctx database.Context, fromBlueScore uint64,
+	percentiles []float64) (sampleSize uint64, feeRatePercentiles []float64, err error) {
+
+	db, err := ctx.DB()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var result struct {
+		SampleSize         uint64
+		FeeRatePercentiles []float64 `pg:",array"`
+	}
+	_, err = db.QueryOne(&result, `
+		SELECT COUNT(*) AS sample_size,
+			percentile_cont(?::DOUBLE PRECISION[]) WITHIN GROUP (
+				ORDER BY transactions.fee::DOUBLE PRECISION / transactions.mass
+			) AS fee_rate_percentiles
+		FROM transactions
+		INNER JOIN blocks AS accepting_blocks ON accepting_blocks.id = transactions.accepting_block_id
+		WHERE accepting_blocks.blue_score > ?
+		AND transactions.fee IS NOT NULL
+		AND transactions.mass > 0`,
+		pg.Array(percentiles), fromBlueScore)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return result.SampleSize, result.FeeRatePercentiles, nil
 }
@@ -0,0 +1,147 @@
+package dbaccess
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+)
+
+// TransactionDirection signifies whether an address
+// gained or lost value in a transaction
+type TransactionDirection string
+
+// TransactionDirection constants
+const (
+	TransactionDirectionAny      TransactionDirection = ""
+	TransactionDirectionIncoming TransactionDirection = "incoming"
+	TransactionDirectionOutgoing TransactionDirection = "outgoing"
+)
+
+// AddressTransactionsFilter filters the transactions of an address.
+// The blue score and time ranges are inclusive, and refer to the block
+// that accepted the transaction, so they only match accepted transactions.
+type AddressTransactionsFilter struct {
+	FromBlueScore *uint64
+	ToBlueScore   *uint64
+	FromTime      *time.Time
+	ToTime        *time.Time
+	Direction     TransactionDirection
+	AcceptedOnly  bool
+
+	// MinValue is the minimum absolute change
+	// in the balance of the address
+	MinValue uint64
+}
+
+// transactionsPage is a page of transactions ordered by ID, which starts after
+// `cursor` (or at the first transaction if it's nil) and skips `skip` transactions
+type transactionsPage struct {
+	order  Order
+	cursor *Cursor
+	skip   uint64
+	limit  uint64
+}
+
+// hasConditions returns whether the filter excludes any transactions
+func (filter *AddressTransactionsFilter) hasConditions() bool {
+	return filter != nil && (filter.AcceptedOnly || filter.FromBlueScore != nil || filter.ToBlueScore != nil ||
+		filter.FromTime != nil || filter.ToTime != nil || filter.Direction != TransactionDirectionAny ||
+		filter.MinValue > 0)
+}
+
+// selectTransactionsByAddress restricts the given transactions query to
+// transactions sent to or from `address` that match the given filter.
+// If a page is given, only the transactions that may be in it are
+// considered. The caller still has to order, bound and limit the query.
+func selectTransactionsByAddress(query *orm.Query, address string, filter *AddressTransactionsFilter,
+	page *transactionsPage) *orm.Query {
+
+	query = query.
+		Join(`INNER JOIN (?) AS address_net_values`, pg.Q(addressNetValuesQuery(filter, page), address, pageCursorID(page))).
+		JoinOn("address_net_values.transaction_id = transaction.id")
+
+	if filter == nil {
+		return query
+	}
+
+	hasAcceptingBlockFilter := filter.AcceptedOnly || filter.FromBlueScore != nil || filter.ToBlueScore != nil ||
+		filter.FromTime != nil || filter.ToTime != nil
+	if hasAcceptingBlockFilter {
+		query = query.
+			Join("INNER JOIN blocks AS accepting_blocks").
+			JoinOn("accepting_blocks.id = transaction.accepting_block_id")
+	}
+	if filter.FromBlueScore != nil {
+		query = query.Where("accepting_blocks.blue_score >= ?", *filter.FromBlueScore)
+	}
+	if filter.ToBlueScore != nil {
+		query = query.Where("accepting_blocks.blue_score <= ?", *filter.ToBlueScore)
+	}
+	if filter.FromTime != nil {
+		query = query.Where("accepting_blocks.timestamp >= ?", *filter.FromTime)
+	}
+	if filter.ToTime != nil {
+		query = query.Where("accepting_blocks.timestamp <= ?", *filter.ToTime)
+	}
+
+	switch filter.Direction {
+	case TransactionDirectionIncoming:
+		query = query.Where("address_net_values.net_value > 0")
+	case TransactionDirectionOutgoing:
+		query = query.Where("address_net_values.net_value < 0")
+	}
+	if filter.MinValue > 0 {
+		query = query.Where("ABS(address_net_values.net_value) >= ?", filter.MinValue)
+	}
+
+	return query
+}
+
+// pageCursorID returns the ID of the cursor of the given page, if it has one
+func pageCursorID(page *transactionsPage) uint64 {
+	if page == nil || page.cursor == nil {
+		return 0
+	}
+	return page.cursor.ID
+}
+
+// addressNetValuesQuery returns a query for the change in the balance of
+// the address ?0 in every transaction that the address is either an input
+// or an output of. The cursor of the given page, whose ID is ?1, is applied
+// before the values are aggregated, and so is its limit, when the filter
+// doesn't exclude any of the aggregated transactions, so that a page doesn't
+// aggregate the whole history of the address.
+func addressNetValuesQuery(filter *AddressTransactionsFilter, page *transactionsPage) string {
+	var outputsCursorCondition, inputsCursorCondition, pageClause string
+	if page != nil && page.cursor != nil {
+		operator := keysetOperator(page.order)
+		outputsCursorCondition = fmt.Sprintf("AND transaction_outputs.transaction_id %s ?1", operator)
+		inputsCursorCondition = fmt.Sprintf("AND transaction_inputs.transaction_id %s ?1", operator)
+	}
+	if page != nil && page.order != OrderUnknown && page.limit > 0 && !filter.hasConditions() {
+		// The skipped transactions are aggregated too, since the caller skips them
+		pageClause = fmt.Sprintf("ORDER BY address_values.transaction_id %s LIMIT %d",
+			page.order, page.skip+page.limit)
+	}
+
+	return fmt.Sprintf(`
+		SELECT address_values.transaction_id, SUM(address_values.value) AS net_value
+		FROM (
+			SELECT transaction_outputs.transaction_id, transaction_outputs.value::NUMERIC AS value
+			FROM transaction_outputs
+			INNER JOIN addresses ON addresses.id = transaction_outputs.address_id
+			WHERE addresses.address = ?0
+			%s
+			UNION ALL
+			SELECT transaction_inputs.transaction_id, -inputs_outs.value::NUMERIC AS value
+			FROM transaction_inputs
+			INNER JOIN transaction_outputs AS inputs_outs ON inputs_outs.id = transaction_inputs.previous_transaction_output_id
+			INNER JOIN addresses ON addresses.id = inputs_outs.address_id
+			WHERE addresses.address = ?0
+			%s
+		) AS address_values
+		GROUP BY address_values.transaction_id
+		%s`, outputsCursorCondition, inputsCursorCondition, pageClause)
+}
@@ -0,0 +1,69 @@
+package dbaccess
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddressNetValuesQuery(t *testing.T) {
+	tests := []struct {
+		name                  string
+		filter                *AddressTransactionsFilter
+		page                  *transactionsPage
+		expectedCursorClauses []string
+		expectedPageClause    string
+	}{
+		{
+			name: "no page",
+		},
+		{
+			name:               "first page",
+			page:               &transactionsPage{order: OrderAscending, limit: 10},
+			expectedPageClause: "ORDER BY address_values.transaction_id ASC LIMIT 10",
+		},
+		{
+			name: "page after cursor",
+			page: &transactionsPage{order: OrderDescending, cursor: &Cursor{ID: 5}, limit: 10},
+			expectedCursorClauses: []string{
+				"AND transaction_outputs.transaction_id < ?1",
+				"AND transaction_inputs.transaction_id < ?1",
+			},
+			expectedPageClause: "ORDER BY address_values.transaction_id DESC LIMIT 10",
+		},
+		{
+			name:               "skipped page",
+			page:               &transactionsPage{order: OrderAscending, skip: 20, limit: 10},
+			expectedPageClause: "ORDER BY address_values.transaction_id ASC LIMIT 30",
+		},
+		{
+			name:   "filtered page after cursor",
+			filter: &AddressTransactionsFilter{Direction: TransactionDirectionIncoming},
+			page:   &transactionsPage{order: OrderAscending, cursor: &Cursor{ID: 5}, limit: 10},
+			expectedCursorClauses: []string{
+				"AND transaction_outputs.transaction_id > ?1",
+				"AND transaction_inputs.transaction_id > ?1",
+			},
+		},
+		{
+			name: "unordered page",
+			page: &transactionsPage{order: OrderUnknown, limit: 10},
+		},
+	}
+	for _, test := range tests {
+		query := addressNetValuesQuery(test.filter, test.page)
+		for _, cursorClause := range test.expectedCursorClauses {
+			if !strings.Contains(query, cursorClause) {
+				t.Errorf("%s: expected the query to contain %q but got:\n%s", test.name, cursorClause, query)
+			}
+		}
+		if len(test.expectedCursorClauses) == 0 && strings.Contains(query, "?1") {
+			t.Errorf("%s: expected the query to have no cursor condition but got:\n%s", test.name, query)
+		}
+		if test.expectedPageClause != "" && !strings.Contains(query, test.expectedPageClause) {
+			t.Errorf("%s: expected the query to contain %q but got:\n%s", test.name, test.expectedPageClause, query)
+		}
+		if test.expectedPageClause == "" && strings.Contains(query, "LIMIT") {
+			t.Errorf("%s: expected the query to have no limit but got:\n%s", test.name, query)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package dbaccess
 
 import (
+	"fmt"
+
 	"github.com/go-pg/pg/v9"
 	"github.com/someone235/katnip/server/database"
 	"github.com/someone235/katnip/server/dbmodels"
@@ -37,6 +39,47 @@ func UTXOsByAddress(ctx database.Context, address string, preloadedFields ...dbm
 	return transactionOutputs, nil
 }
 
+// UTXOsByAddressesAfterCursor retrieves up to `limit` transaction outputs incoming to
+// any of `addresses`, ordered by ID in the given order and starting after the given
+// cursor. The blue score of the cursor is ignored. If cursor is nil, starts from the
+// first transaction output.
+// If preloadedFields was provided - preloads the requested fields
+func UTXOsByAddressesAfterCursor(ctx database.Context, addresses []string, order Order, cursor *Cursor, limit uint64,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.TransactionOutput, error) {
+
+	if len(addresses) == 0 || limit == 0 {
+		return []*dbmodels.TransactionOutput{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+	var transactionOutputs []*dbmodels.TransactionOutput
+	query := db.Model(&transactionOutputs).
+		Join("LEFT JOIN addresses").
+		JoinOn("addresses.id = transaction_output.address_id").
+		Join("INNER JOIN transactions").
+		JoinOn("transaction_output.transaction_id = transactions.id").
+		Where("addresses.address IN (?)", pg.In(addresses)).
+		Where("transaction_output.is_spent = ?", false).
+		Where("transactions.accepting_block_id IS NOT NULL").
+		Order(fmt.Sprintf("transaction_output.id %s", order)).
+		Limit(int(limit))
+
+	if cursor != nil {
+		query = query.Where(fmt.Sprintf("transaction_output.id %s ?", keysetOperator(order)), cursor.ID)
+	}
+
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return transactionOutputs, nil
+}
+
 // TransactionOutputsByOutpoints retrieves all transaction outputs referenced by `outpoints`.
 // If preloadedFields was provided - preloads the requested fields
 func TransactionOutputsByOutpoints(ctx database.Context, outpoints []*Outpoint) ([]*dbmodels.TransactionOutput, error) {
@@ -69,6 +112,60 @@ func TransactionOutputsByOutpoints(ctx database.Context, outpoints []*Outpoint)
 	return dbPreviousTransactionsOutputs, nil
 }
 
+// TransactionOutputsByIDs retrieves all transaction outputs by their database IDs.
+// If preloadedFields was provided - preloads the requested fields
+func TransactionOutputsByIDs(ctx database.Context, ids []uint64,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.TransactionOutput, error) {
+
+	if len(ids) == 0 {
+		return []*dbmodels.TransactionOutput{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var transactionOutputs []*dbmodels.TransactionOutput
+	query := db.Model(&transactionOutputs).
+		Where("transaction_output.id IN (?)", pg.In(ids))
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return transactionOutputs, nil
+}
+
+// TransactionOutputsByTransactionIDs retrieves the outputs of all the transactions
+// with the given database IDs, ordered by their index
+// If preloadedFields was provided - preloads the requested fields
+func TransactionOutputsByTransactionIDs(ctx database.Context, transactionIDs []uint64,
+	preloadedFields ...dbmodels.FieldName) ([]*dbmodels.TransactionOutput, error) {
+
+	if len(transactionIDs) == 0 {
+		return []*dbmodels.TransactionOutput{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var transactionOutputs []*dbmodels.TransactionOutput
+	query := db.Model(&transactionOutputs).
+		Where("transaction_output.transaction_id IN (?)", pg.In(transactionIDs)).
+		Order("transaction_output.transaction_id ASC", "transaction_output.index ASC")
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return transactionOutputs, nil
+}
+
 // UpdateTransactionOutputIsSpent updates transaction-output `txOutID` by setting its IsSpent field to `isSpent`
 func UpdateTransactionOutputIsSpent(ctx database.Context, txOutID uint64, isSpent bool) error {
 	db, err := ctx.DB()
@@ -0,0 +1,242 @@
+package dbaccess
+
+import (
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+// WebhookConfirmation is a transaction that reached the confirmation
+// threshold of a webhook subscription
+type WebhookConfirmation struct {
+	WebhookSubscriptionID uint64
+	TransactionID         string
+}
+
+// InsertWebhookSubscription inserts the given webhook subscription
+// to the database and sets its ID
+func InsertWebhookSubscription(ctx database.Context, subscription *dbmodels.WebhookSubscription) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(subscription).Returning("id").Insert()
+	return err
+}
+
+// WebhookSubscriptionByID retrieves the webhook subscription with the given ID
+func WebhookSubscriptionByID(ctx database.Context, id uint64) (*dbmodels.WebhookSubscription, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &dbmodels.WebhookSubscription{}
+	err = db.Model(subscription).
+		Where("id = ?", id).
+		First()
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// DeleteWebhookSubscription deletes the webhook subscription with the given ID
+// alongside all its deliveries
+func DeleteWebhookSubscription(ctx database.Context, id uint64) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(&dbmodels.WebhookSubscription{}).
+		Where("id = ?", id).
+		Delete()
+	return err
+}
+
+// WebhookSubscriptionsByAddressesOrTransactionIDs retrieves all the webhook subscriptions
+// without a confirmation threshold that watch one of the given addresses or transaction IDs
+func WebhookSubscriptionsByAddressesOrTransactionIDs(ctx database.Context, addresses []string,
+	transactionIDs []string) ([]*dbmodels.WebhookSubscription, error) {
+
+	if len(addresses) == 0 && len(transactionIDs) == 0 {
+		return []*dbmodels.WebhookSubscription{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var subscriptions []*dbmodels.WebhookSubscription
+	err = db.Model(&subscriptions).
+		Where("confirmations IS NULL").
+		WhereGroup(func(query *orm.Query) (*orm.Query, error) {
+			if len(addresses) > 0 {
+				query = query.WhereOr("address IN (?)", pg.In(addresses))
+			}
+			if len(transactionIDs) > 0 {
+				query = query.WhereOr("transaction_id IN (?)", pg.In(transactionIDs))
+			}
+			return query, nil
+		}).
+		Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// WebhookConfirmationsByBlueScoreRange retrieves all the transactions that reached the
+// confirmation threshold of a webhook subscription watching them when the selected tip
+// blue score advanced from `fromBlueScore` (exclusive) to `toBlueScore` (inclusive)
+func WebhookConfirmationsByBlueScoreRange(ctx database.Context, fromBlueScore uint64,
+	toBlueScore uint64) ([]*WebhookConfirmation, error) {
+
+	if toBlueScore <= fromBlueScore {
+		return []*WebhookConfirmation{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	// A transaction has blue_score(selected tip) - blue_score(accepting block) + 1
+	// confirmations, so it reaches the threshold at
+	// blue_score(accepting block) + confirmations - 1
+	var confirmations []*WebhookConfirmation
+	_, err = db.Query(&confirmations, `
+		SELECT webhook_subscriptions.id AS webhook_subscription_id, transactions.transaction_id
+		FROM webhook_subscriptions
+		INNER JOIN transactions ON transactions.transaction_id = webhook_subscriptions.transaction_id
+		INNER JOIN blocks AS accepting_blocks ON accepting_blocks.id = transactions.accepting_block_id
+		WHERE webhook_subscriptions.confirmations IS NOT NULL
+		AND accepting_blocks.blue_score + webhook_subscriptions.confirmations - 1 > ?0
+		AND accepting_blocks.blue_score + webhook_subscriptions.confirmations - 1 <= ?1
+		UNION
+		SELECT webhook_subscriptions.id AS webhook_subscription_id, transactions.transaction_id
+		FROM webhook_subscriptions
+		INNER JOIN addresses ON addresses.address = webhook_subscriptions.address
+		INNER JOIN transaction_outputs ON transaction_outputs.address_id = addresses.id
+		INNER JOIN transactions ON transactions.id = transaction_outputs.transaction_id
+		INNER JOIN blocks AS accepting_blocks ON accepting_blocks.id = transactions.accepting_block_id
+		WHERE webhook_subscriptions.confirmations IS NOT NULL
+		AND accepting_blocks.blue_score + webhook_subscriptions.confirmations - 1 > ?0
+		AND accepting_blocks.blue_score + webhook_subscriptions.confirmations - 1 <= ?1
+		UNION
+		SELECT webhook_subscriptions.id AS webhook_subscription_id, transactions.transaction_id
+		FROM webhook_subscriptions
+		INNER JOIN addresses ON addresses.address = webhook_subscriptions.address
+		INNER JOIN transaction_outputs ON transaction_outputs.address_id = addresses.id
+		INNER JOIN transaction_inputs ON transaction_inputs.previous_transaction_output_id = transaction_outputs.id
+		INNER JOIN transactions ON transactions.id = transaction_inputs.transaction_id
+		INNER JOIN blocks AS accepting_blocks ON accepting_blocks.id = transactions.accepting_block_id
+		WHERE webhook_subscriptions.confirmations IS NOT NULL
+		AND accepting_blocks.blue_score + webhook_subscriptions.confirmations - 1 > ?0
+		AND accepting_blocks.blue_score + webhook_subscriptions.confirmations - 1 <= ?1`,
+		fromBlueScore, toBlueScore)
+	if err != nil {
+		return nil, err
+	}
+
+	return confirmations, nil
+}
+
+// InsertWebhookDeliveries queues the given webhook deliveries. Deliveries
+// that would notify the same confirmation twice are silently dropped.
+func InsertWebhookDeliveries(ctx database.Context, deliveries []*dbmodels.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(&deliveries).
+		OnConflict("DO NOTHING").
+		Insert()
+	return err
+}
+
+// ClaimDueWebhookDeliveries returns the IDs of up to `limit` pending deliveries
+// that are due at `now`, and postpones their next attempt by `lease`, so that
+// other workers won't pick them up while they're being delivered.
+func ClaimDueWebhookDeliveries(ctx database.Context, now time.Time, lease time.Duration, limit uint64) ([]uint64, error) {
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint64
+	_, err = db.Query(&ids, `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = ?0
+		WHERE id IN (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status = ?1
+			AND next_attempt_at <= ?2
+			ORDER BY next_attempt_at
+			LIMIT ?3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id`,
+		now.Add(lease), dbmodels.WebhookDeliveryStatusPending, now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// WebhookDeliveriesByIDs retrieves all the webhook deliveries with the given IDs
+// If preloadedFields was provided - preloads the requested fields
+func WebhookDeliveriesByIDs(ctx database.Context, ids []uint64, preloadedFields ...dbmodels.FieldName) ([]*dbmodels.WebhookDelivery, error) {
+	if len(ids) == 0 {
+		return []*dbmodels.WebhookDelivery{}, nil
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []*dbmodels.WebhookDelivery
+	query := db.Model(&deliveries).
+		Where("webhook_delivery.id IN (?)", pg.In(ids))
+	query = preloadFields(query, preloadedFields)
+	err = query.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// UpdateWebhookDeliveryAttempt updates the status, attempts count,
+// next attempt time and last error of the given delivery
+func UpdateWebhookDeliveryAttempt(ctx database.Context, delivery *dbmodels.WebhookDelivery) error {
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(delivery).
+		Column("status", "attempts", "next_attempt_at", "last_error").
+		WherePK().
+		Update()
+	return err
+}
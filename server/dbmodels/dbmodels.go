@@ -26,6 +26,7 @@ type Block struct {
 	TransactionCount     uint16         `pg:",use_zero"`
 	Difficulty           float64        `pg:",use_zero"`
 	ParentBlocks         []*Block       `pg:"many2many:parent_blocks,joinFK:parent_block_id"`
+	ChildBlocks          []*Block       `pg:"many2many:parent_blocks,fk:parent_block_id,joinFK:block_id"`
 	AcceptedBlocks       []*Block       `pg:"many2many:accepted_blocks,joinFK:accepted_block_id"`
 	Transactions         []*Transaction `pg:"many2many:transactions_to_blocks,joinFK:transaction_id"`
 }
@@ -33,9 +34,11 @@ type Block struct {
 // BlockFieldNames is a list of FieldNames for the 'Block' object
 var BlockFieldNames = struct {
 	ParentBlocks,
+	ChildBlocks,
 	Transactions FieldName
 }{
 	ParentBlocks: "ParentBlocks",
+	ChildBlocks:  "ChildBlocks",
 	Transactions: "Transactions",
 }
 
@@ -78,6 +81,24 @@ var AcceptedBlockFieldNames = struct {
 	AcceptedBlock: "AcceptedBlock",
 }
 
+// MergeSetBlock is the database model for the 'merge_set_blocks' table
+type MergeSetBlock struct {
+	BlockID       uint64 `pg:",use_zero"`
+	Block         Block
+	MergedBlockID uint64 `pg:",use_zero"`
+	MergedBlock   Block
+	IsBlue        bool `pg:",use_zero"`
+}
+
+// MergeSetBlockFieldNames is a list of FieldNames for the 'MergeSetBlock' object
+var MergeSetBlockFieldNames = struct {
+	Block       FieldName
+	MergedBlock FieldName
+}{
+	Block:       "Block",
+	MergedBlock: "MergedBlock",
+}
+
 // RawBlock is the database model for the 'raw_blocks' table
 type RawBlock struct {
 	BlockID   uint64
@@ -116,6 +137,7 @@ type Transaction struct {
 	Blocks             []Block `pg:"many2many:transactions_to_blocks"`
 	TransactionOutputs []TransactionOutput
 	TransactionInputs  []TransactionInput
+	Fee                *uint64
 }
 
 // TransactionFieldNames is a list of FieldNames for the 'Transaction' object
@@ -234,6 +256,125 @@ type Address struct {
 	Address string `pg:",use_zero"`
 }
 
+// WebhookSubscription is the database model for the 'webhook_subscriptions' table
+type WebhookSubscription struct {
+	ID            uint64 `pg:",pk"`
+	URL           string `pg:",use_zero"`
+	Secret        string `pg:",use_zero"`
+	Address       *string
+	TransactionID *string
+	Confirmations *uint64
+	CreatedAt     time.Time `pg:",use_zero"`
+}
+
+// WebhookDelivery statuses
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusDelivered = "delivered"
+	WebhookDeliveryStatusDead      = "dead"
+)
+
+// WebhookDelivery is the database model for the 'webhook_deliveries' table
+type WebhookDelivery struct {
+	ID                    uint64 `pg:",pk"`
+	WebhookSubscriptionID uint64 `pg:",use_zero"`
+	WebhookSubscription   *WebhookSubscription
+	Event                 string `pg:",use_zero"`
+	TransactionID         *string
+	Payload               string    `pg:",use_zero"`
+	Status                string    `pg:",use_zero"`
+	Attempts              uint32    `pg:",use_zero"`
+	NextAttemptAt         time.Time `pg:",use_zero"`
+	LastError             *string
+	CreatedAt             time.Time `pg:",use_zero"`
+}
+
+// WebhookDeliveryFieldNames is a list of FieldNames for the 'WebhookDelivery' object
+var WebhookDeliveryFieldNames = struct {
+	WebhookSubscription FieldName
+}{
+	WebhookSubscription: "WebhookSubscription",
+}
+
+// OutboxEvent is the database model for the 'events_outbox' table.
+// Its ID is the sequence number of the event.
+type OutboxEvent struct {
+	tableName struct{}  `pg:"events_outbox"`
+	ID        uint64    `pg:",pk"`
+	Topic     string    `pg:",use_zero"`
+	Payload   string    `pg:",use_zero"`
+	CreatedAt time.Time `pg:",use_zero"`
+}
+
+// APIKeyTier is the database model for the 'api_key_tiers' table.
+// A nil DailyQuota means that the tier is unlimited.
+type APIKeyTier struct {
+	ID                uint64 `pg:",pk"`
+	Name              string `pg:",use_zero"`
+	RequestsPerSecond uint32 `pg:",use_zero"`
+	Burst             uint32 `pg:",use_zero"`
+	DailyQuota        *uint64
+}
+
+// APIKey is the database model for the 'api_keys' table
+type APIKey struct {
+	ID           uint64 `pg:",pk"`
+	KeyHash      string `pg:",use_zero"`
+	Name         string `pg:",use_zero"`
+	APIKeyTierID uint64 `pg:",use_zero"`
+	APIKeyTier   *APIKeyTier
+	CreatedAt    time.Time `pg:",use_zero"`
+	RevokedAt    *time.Time
+}
+
+// APIKeyFieldNames is a list of FieldNames for the 'APIKey' object
+var APIKeyFieldNames = struct {
+	APIKeyTier FieldName
+}{
+	APIKeyTier: "APIKeyTier",
+}
+
+// APIKeyUsage is the database model for the 'api_key_usage' table
+type APIKeyUsage struct {
+	tableName    struct{}  `pg:"api_key_usage"`
+	APIKeyID     uint64    `pg:",pk"`
+	Day          time.Time `pg:",pk"`
+	RequestCount uint64    `pg:",use_zero"`
+}
+
+// AddressBalanceChange is the database model for the 'address_balance_changes' table.
+// Balance is the balance of the address right after the change.
+type AddressBalanceChange struct {
+	AddressID        uint64    `pg:",pk"`
+	TransactionID    uint64    `pg:",pk"`
+	AcceptingBlockID uint64    `pg:",use_zero"`
+	BlueScore        uint64    `pg:",use_zero"`
+	Timestamp        time.Time `pg:",use_zero"`
+	ValueChange      int64     `pg:",use_zero"`
+	Balance          int64     `pg:",use_zero"`
+}
+
+// DAGBlock is a compact representation of a block, used to visualize the DAG.
+// It's selected from the 'blocks' table and has no table of its own.
+type DAGBlock struct {
+	ID           uint64
+	BlockHash    string
+	BlueScore    uint64
+	Timestamp    time.Time
+	IsChainBlock bool
+
+	// IsBlue is the color of the block in the merge set of its
+	// accepting block. It's nil if the block is not accepted yet.
+	IsBlue *bool
+}
+
+// DAGEdge is a compact representation of the relation between a block and one of its parents.
+// It's selected from the 'parent_blocks' table and has no table of its own.
+type DAGEdge struct {
+	BlockHash       string
+	ParentBlockHash string
+}
+
 // PrefixFieldNames returns the given fields prefixed
 // with the given prefix and a dot.
 func PrefixFieldNames(prefix FieldName, fields []FieldName) []FieldName {
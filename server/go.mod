@@ -8,9 +8,20 @@ require (
 	github.com/golang-migrate/migrate/v4 v4.7.1
 	github.com/gorilla/handlers v1.4.2
 	github.com/gorilla/mux v1.7.3
+	github.com/gorilla/websocket v1.4.2
+	github.com/graph-gophers/graphql-go v1.3.0
 	github.com/jessevdk/go-flags v1.4.0
 	github.com/kaspanet/kaspad v0.10.4
+	github.com/opentracing/opentracing-go v1.2.0 // indirect
 	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.12.2
+	go.opentelemetry.io/otel v1.14.0
+	go.opentelemetry.io/otel/exporters/jaeger v1.14.0
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.14.0
+	go.opentelemetry.io/otel/sdk v1.14.0
+	go.opentelemetry.io/otel/trace v1.14.0
+	google.golang.org/grpc v1.33.1
+	google.golang.org/protobuf v1.26.0
 )
 
 replace github.com/kaspanet/kaspad => ../../../kaspanet/kaspad
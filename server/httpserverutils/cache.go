@@ -0,0 +1,181 @@
+package httpserverutils
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	contextKeyResponseCache contextKey = "RESPONSE_CACHE"
+
+	// immutableMaxAge is the max-age of immutable responses, as
+	// recommended for responses that never expire
+	immutableMaxAge = 365 * 24 * time.Hour
+
+	// maxCachedResponseSize is the size of the largest
+	// response body that the response cache keeps
+	maxCachedResponseSize = 1024 * 1024
+)
+
+// cachedResponse is a response body with the headers it's served with
+type cachedResponse struct {
+	key          string
+	body         []byte
+	etag         string
+	cacheControl string
+	isImmutable  bool
+	expiresAt    time.Time
+}
+
+// ResponseCache is an in-process LRU cache of the responses of GET requests
+// whose handlers return a CacheableResponse. Immutable responses are kept
+// until they're evicted, and other responses until their MaxAge passes or
+// until InvalidateMutable is called.
+type ResponseCache struct {
+	lock     sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	lru      *list.List
+}
+
+// NewResponseCache returns a new ResponseCache that
+// holds up to `capacity` responses
+func NewResponseCache(capacity int) *ResponseCache {
+	return &ResponseCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Middleware is a middleware that makes the cache available
+// to the handlers that are made by MakeHandler
+func (c *ResponseCache) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(context.WithValue(r.Context(), contextKeyResponseCache, c))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// InvalidateMutable removes all the responses that aren't immutable.
+// It's called whenever the DAG changes.
+func (c *ResponseCache) InvalidateMutable() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for key, element := range c.entries {
+		if !element.Value.(*cachedResponse).isImmutable {
+			c.lru.Remove(element)
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *ResponseCache) get(key string, now time.Time) (*cachedResponse, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	response := element.Value.(*cachedResponse)
+	if !response.isImmutable && !now.Before(response.expiresAt) {
+		c.lru.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.lru.MoveToFront(element)
+	return response, true
+}
+
+func (c *ResponseCache) add(response *cachedResponse) {
+	if len(response.body) > maxCachedResponseSize {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if element, ok := c.entries[response.key]; ok {
+		element.Value = response
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.entries[response.key] = c.lru.PushFront(response)
+	for c.lru.Len() > c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedResponse).key)
+	}
+}
+
+func responseCacheFromContext(ctx *ServerContext) *ResponseCache {
+	cache, _ := ctx.Value(contextKeyResponseCache).(*ResponseCache)
+	return cache
+}
+
+// newCachedResponse returns the given response body with its ETag and,
+// if it's cacheable, its Cache-Control header
+func newCachedResponse(key string, body []byte, cacheableResponse *CacheableResponse, now time.Time) *cachedResponse {
+	bodyHash := sha256.Sum256(body)
+	response := &cachedResponse{
+		key:  key,
+		body: body,
+		etag: fmt.Sprintf(`"%s"`, hex.EncodeToString(bodyHash[:16])),
+	}
+	if cacheableResponse == nil {
+		return response
+	}
+	if cacheableResponse.IsImmutable {
+		response.isImmutable = true
+		response.cacheControl = fmt.Sprintf("public, max-age=%d, immutable", int64(immutableMaxAge.Seconds()))
+	} else {
+		response.cacheControl = fmt.Sprintf("public, max-age=%d", int64(cacheableResponse.MaxAge.Seconds()))
+		response.expiresAt = now.Add(cacheableResponse.MaxAge)
+	}
+	return response
+}
+
+// send sends the response, or 304 Not Modified if the
+// request's If-None-Match header matches its ETag
+func (response *cachedResponse) send(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("ETag", response.etag)
+	if response.cacheControl != "" {
+		w.Header().Set("Cache-Control", response.cacheControl)
+	}
+	if etagMatches(r.Header.Get("If-None-Match"), response.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	_, err := w.Write(response.body)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// etagMatches returns whether the given If-None-Match header matches
+// the given ETag. If-None-Match uses the weak comparison, so weak
+// validators match as well.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package httpserverutils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := NewResponseCache(2)
+
+	immutable := newCachedResponse("/block/a", []byte(`{}`), &CacheableResponse{IsImmutable: true}, now)
+	mutable := newCachedResponse("/blocks", []byte(`[]`), &CacheableResponse{MaxAge: time.Second}, now)
+	cache.add(immutable)
+	cache.add(mutable)
+
+	if _, ok := cache.get("/blocks", now.Add(time.Second)); ok {
+		t.Errorf("a response was served after its max-age")
+	}
+
+	cache.add(mutable)
+	cache.InvalidateMutable()
+	if _, ok := cache.get("/blocks", now); ok {
+		t.Errorf("a mutable response was served after it was invalidated")
+	}
+	if _, ok := cache.get("/block/a", now.Add(time.Hour)); !ok {
+		t.Errorf("an immutable response was not served")
+	}
+
+	// The immutable response was used last, so the
+	// first of the new responses evicts it
+	cache.add(newCachedResponse("/block/b", []byte(`{}`), &CacheableResponse{IsImmutable: true}, now))
+	cache.add(newCachedResponse("/block/c", []byte(`{}`), &CacheableResponse{IsImmutable: true}, now))
+	if _, ok := cache.get("/block/a", now); ok {
+		t.Errorf("the least recently used response was not evicted")
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	etag := `"abc"`
+	tests := []struct {
+		ifNoneMatch     string
+		expectedMatches bool
+	}{
+		{"", false},
+		{"*", true},
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"xyz", "abc"`, true},
+		{`"xyz"`, false},
+	}
+	for _, test := range tests {
+		matches := etagMatches(test.ifNoneMatch, etag)
+		if matches != test.expectedMatches {
+			t.Errorf("If-None-Match %s: expected %t but got %t", test.ifNoneMatch, test.expectedMatches, matches)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package httpserverutils
 import (
 	"context"
 	"fmt"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 type contextKey string
@@ -40,7 +42,14 @@ func (ctx *ServerContext) requestID() uint64 {
 	return uint64ID
 }
 
+// getLogString prefixes the log with the request ID, and with
+// the trace ID if the request is traced, so that the logs of a
+// request can be found from its trace
 func (ctx *ServerContext) getLogString(format string, params ...interface{}) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.IsSampled() {
+		return fmt.Sprintf("RID %d (trace %s): ", ctx.requestID(), spanContext.TraceID()) + fmt.Sprintf(format, params...)
+	}
 	return fmt.Sprintf("RID %d: ", ctx.requestID()) + fmt.Sprintf(format, params...)
 }
 
@@ -0,0 +1,98 @@
+package httpserverutils
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	requestsCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "katnip_http_requests_total",
+		Help: "Number of HTTP requests by route and status",
+	}, []string{"method", "route", "status"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "katnip_http_request_duration_seconds",
+		Help:    "Latency of HTTP requests by route and status",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 15),
+	}, []string{"method", "route", "status"})
+)
+
+// MetricsMiddleware is a middleware that counts the requests and measures their
+// latency by route and status. Routes are identified by their path templates, so
+// that requests for different blocks or addresses are counted together.
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+
+		route := routeTemplate(r)
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		labels := prometheus.Labels{"method": r.Method, "route": route, "status": strconv.Itoa(status)}
+		requestsCount.With(labels).Inc()
+		requestDuration.With(labels).Observe(time.Since(start).Seconds())
+	})
+}
+
+// routeTemplate returns the path template of the route
+// that the request matched, or "unknown" if it matched none
+func routeTemplate(r *http.Request) string {
+	currentRoute := mux.CurrentRoute(r)
+	if currentRoute == nil {
+		return "unknown"
+	}
+	pathTemplate, err := currentRoute.GetPathTemplate()
+	if err != nil {
+		return "unknown"
+	}
+	return pathTemplate
+}
+
+// statusRecorder is an http.ResponseWriter that records the status of the
+// response. It lets streams flush and websockets hijack the connection
+// through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer doesn't support hijacking")
+	}
+	r.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
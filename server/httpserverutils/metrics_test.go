@@ -0,0 +1,40 @@
+package httpserverutils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(MetricsMiddleware)
+	router.HandleFunc("/block/{blockHash}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	router.HandleFunc("/blocks", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	for _, path := range []string{"/block/a", "/block/b", "/blocks"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	tests := []struct {
+		route         string
+		status        string
+		expectedCount float64
+	}{
+		{"/block/{blockHash}", "404", 2},
+		{"/blocks", "200", 1},
+	}
+	for _, test := range tests {
+		count := testutil.ToFloat64(requestsCount.WithLabelValues(http.MethodGet, test.route, test.status))
+		if count != test.expectedCount {
+			t.Errorf("%s %s: expected %f requests but got %f", test.route, test.status, test.expectedCount, count)
+		}
+	}
+}
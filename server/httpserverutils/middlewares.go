@@ -4,17 +4,20 @@ import (
 	"github.com/pkg/errors"
 	"net/http"
 	"runtime/debug"
+	"sync/atomic"
 )
 
-var nextRequestID uint64 = 1
+// lastRequestID is the ID of the last request. It's
+// incremented atomically, since requests are concurrent.
+var lastRequestID uint64
 
 // AddRequestMetadataMiddleware is a middleware that adds some
 // metadata to the context of every request.
 func AddRequestMetadataMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		rCtx := ToServerContext(r.Context()).SetRequestID(nextRequestID)
+		requestID := atomic.AddUint64(&lastRequestID, 1)
+		rCtx := ToServerContext(r.Context()).SetRequestID(requestID)
 		r = r.WithContext(rCtx)
-		nextRequestID++
 		next.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,236 @@
+package httpserverutils
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// APIKeyHeader is the request header that holds the API key
+const APIKeyHeader = "X-API-Key"
+
+// bucketCleanupInterval is how often buckets that
+// are full again are removed from the limiter
+const bucketCleanupInterval = time.Minute
+
+// RateLimit is the sustained rate and the burst
+// of requests that a client is allowed to make
+type RateLimit struct {
+	RequestsPerSecond float64
+	Burst             uint32
+}
+
+// APIKey is an API key with its own rate limit. A nil
+// DailyQuota means that the key has no daily quota.
+type APIKey struct {
+	ID         uint64
+	RateLimit  RateLimit
+	DailyQuota *uint64
+}
+
+// APIKeyStore looks API keys up and counts their usage
+type APIKeyStore interface {
+	// CachedAPIKey returns the API key with the given value if it was looked
+	// up recently, without looking it up again. isCached is false if it
+	// wasn't, and apiKey is nil if it was but there's no such key.
+	CachedAPIKey(key string) (apiKey *APIKey, isCached bool)
+
+	// APIKey returns the API key with the given value,
+	// or nil if there's no such key
+	APIKey(key string) (*APIKey, error)
+
+	// UseQuota counts a request made with the given API key at `now`, and
+	// returns false without counting it if the key exceeded its daily quota
+	UseQuota(apiKey *APIKey, now time.Time) (bool, error)
+}
+
+// RateLimiter limits the rate of requests with a token bucket per
+// client. Clients are identified by their API key if they have one,
+// and otherwise by their IP.
+type RateLimiter struct {
+	ipRateLimit     RateLimit
+	apiKeyStore     APIKeyStore
+	useForwardedFor bool
+
+	bucketsLock       sync.Mutex
+	buckets           map[string]*tokenBucket
+	lastBucketCleanup time.Time
+}
+
+// NewRateLimiter returns a new RateLimiter that allows each IP the given rate
+// limit, and each API key of the given store its own rate limit and quota.
+// If useForwardedFor is set, IPs are taken from the X-Forwarded-For header
+// that's set by a reverse proxy.
+func NewRateLimiter(ipRateLimit RateLimit, apiKeyStore APIKeyStore, useForwardedFor bool) *RateLimiter {
+	return &RateLimiter{
+		ipRateLimit:       ipRateLimit,
+		apiKeyStore:       apiKeyStore,
+		useForwardedFor:   useForwardedFor,
+		buckets:           make(map[string]*tokenBucket),
+		lastBucketCleanup: time.Now(),
+	}
+}
+
+// Middleware is a middleware that responds with 429 Too Many Requests
+// and a Retry-After header to requests that exceed their rate limit
+// or quota, and with 401 Unauthorized to requests with unknown API keys.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := ToServerContext(r.Context())
+
+		clientIP := l.ClientIP(r.Header.Get("X-Forwarded-For"), r.RemoteAddr)
+		retryAfter, err := l.Limit(clientIP, r.Header.Get(APIKeyHeader), time.Now())
+		if err != nil {
+			if retryAfter > 0 {
+				w.Header().Set("Retry-After", fmt.Sprintf("%d", int64(math.Ceil(retryAfter.Seconds()))))
+			}
+			SendErr(ctx, w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Limit counts a request made at `now` by the client with the given IP and
+// API key, which is empty if the client has none. If the request exceeds the
+// client's rate limit or quota, it returns a 429 HandlerError and how long
+// to wait before retrying. If the API key is unknown, it returns a 401
+// HandlerError.
+// API keys that weren't looked up recently and unknown API keys are charged
+// to the rate limit of the IP as well, so that requests with made up keys are
+// limited like requests without keys, and are limited before they're looked up.
+func (l *RateLimiter) Limit(clientIP string, key string, now time.Time) (time.Duration, error) {
+	ipClientKey := "ip:" + clientIP
+	if key == "" {
+		return l.take(ipClientKey, l.ipRateLimit, now)
+	}
+
+	apiKey, isCached := l.apiKeyStore.CachedAPIKey(key)
+	if !isCached || apiKey == nil {
+		retryAfter, err := l.take(ipClientKey, l.ipRateLimit, now)
+		if err != nil {
+			return retryAfter, err
+		}
+	}
+	if !isCached {
+		var err error
+		apiKey, err = l.apiKeyStore.APIKey(key)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if apiKey == nil {
+		return 0, NewHandlerError(http.StatusUnauthorized, errors.New("the API key is invalid"))
+	}
+
+	retryAfter, err := l.take(fmt.Sprintf("key:%d", apiKey.ID), apiKey.RateLimit, now)
+	if err != nil {
+		return retryAfter, err
+	}
+
+	isAllowed, err := l.apiKeyStore.UseQuota(apiKey, now)
+	if err != nil {
+		return 0, err
+	}
+	if !isAllowed {
+		utcNow := now.UTC()
+		nextDay := time.Date(utcNow.Year(), utcNow.Month(), utcNow.Day()+1, 0, 0, 0, 0, time.UTC)
+		return nextDay.Sub(utcNow), NewHandlerError(http.StatusTooManyRequests, errors.New("daily quota exceeded"))
+	}
+	return 0, nil
+}
+
+// ClientIP returns the IP of the client that connected from remoteAddr,
+// or the IP in the given X-Forwarded-For header if the limiter uses it
+func (l *RateLimiter) ClientIP(forwardedFor string, remoteAddr string) string {
+	if l.useForwardedFor && forwardedFor != "" {
+		// The proxy appends the address it got the request from,
+		// so the last address is the only one that can be trusted
+		addresses := strings.Split(forwardedFor, ",")
+		return strings.TrimSpace(addresses[len(addresses)-1])
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+// take takes a token from the bucket of the given client, and returns
+// a 429 HandlerError and how long to wait if there's none
+func (l *RateLimiter) take(clientKey string, rateLimit RateLimit, now time.Time) (time.Duration, error) {
+	l.bucketsLock.Lock()
+	defer l.bucketsLock.Unlock()
+
+	if now.Sub(l.lastBucketCleanup) >= bucketCleanupInterval {
+		for key, bucket := range l.buckets {
+			if bucket.isFull(now) {
+				delete(l.buckets, key)
+			}
+		}
+		l.lastBucketCleanup = now
+	}
+
+	bucket, ok := l.buckets[clientKey]
+	if !ok || bucket.rateLimit != rateLimit {
+		bucket = newTokenBucket(rateLimit, now)
+		l.buckets[clientKey] = bucket
+	}
+	isAllowed, retryAfter := bucket.take(now)
+	if !isAllowed {
+		return retryAfter, NewHandlerError(http.StatusTooManyRequests, errors.New("rate limit exceeded"))
+	}
+	return 0, nil
+}
+
+// tokenBucket allows up to rateLimit.Burst requests at once,
+// and refills at rateLimit.RequestsPerSecond
+type tokenBucket struct {
+	rateLimit  RateLimit
+	tokens     float64
+	lastRefill time.Time
+}
+
+func newTokenBucket(rateLimit RateLimit, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rateLimit:  rateLimit,
+		tokens:     float64(rateLimit.Burst),
+		lastRefill: now,
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	b.tokens = math.Min(float64(b.rateLimit.Burst), b.tokens+elapsed*b.rateLimit.RequestsPerSecond)
+	b.lastRefill = now
+}
+
+// take takes a token from the bucket if there is one, and otherwise
+// returns how long it takes until the next token is available
+func (b *tokenBucket) take(now time.Time) (bool, time.Duration) {
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	if b.rateLimit.RequestsPerSecond <= 0 {
+		return false, bucketCleanupInterval
+	}
+	missingSeconds := (1 - b.tokens) / b.rateLimit.RequestsPerSecond
+	return false, time.Duration(missingSeconds * float64(time.Second))
+}
+
+func (b *tokenBucket) isFull(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= float64(b.rateLimit.Burst)
+}
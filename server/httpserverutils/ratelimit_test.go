@@ -0,0 +1,124 @@
+package httpserverutils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(0, 0)
+	bucket := newTokenBucket(RateLimit{RequestsPerSecond: 2, Burst: 3}, now)
+
+	for i := 0; i < 3; i++ {
+		if isAllowed, _ := bucket.take(now); !isAllowed {
+			t.Fatalf("request %d of the burst was not allowed", i)
+		}
+	}
+	isAllowed, retryAfter := bucket.take(now)
+	if isAllowed {
+		t.Fatalf("a request beyond the burst was allowed")
+	}
+	if retryAfter != 500*time.Millisecond {
+		t.Fatalf("expected to retry after 500ms but got %s", retryAfter)
+	}
+
+	now = now.Add(retryAfter)
+	if isAllowed, _ := bucket.take(now); !isAllowed {
+		t.Fatalf("a request after the bucket refilled was not allowed")
+	}
+
+	now = now.Add(time.Hour)
+	if !bucket.isFull(now) {
+		t.Fatalf("the bucket is not full after an hour")
+	}
+}
+
+type fakeAPIKeyStore struct {
+	apiKey     *APIKey
+	used       uint64
+	lookups    int
+	cachedKeys map[string]struct{}
+}
+
+func (s *fakeAPIKeyStore) CachedAPIKey(key string) (*APIKey, bool) {
+	if _, ok := s.cachedKeys[key]; !ok {
+		return nil, false
+	}
+	return s.knownAPIKey(key), true
+}
+
+func (s *fakeAPIKeyStore) APIKey(key string) (*APIKey, error) {
+	s.lookups++
+	s.cachedKeys[key] = struct{}{}
+	return s.knownAPIKey(key), nil
+}
+
+func (s *fakeAPIKeyStore) knownAPIKey(key string) *APIKey {
+	if key != "valid" {
+		return nil
+	}
+	return s.apiKey
+}
+
+func (s *fakeAPIKeyStore) UseQuota(apiKey *APIKey, _ time.Time) (bool, error) {
+	if apiKey.DailyQuota != nil && s.used >= *apiKey.DailyQuota {
+		return false, nil
+	}
+	s.used++
+	return true, nil
+}
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	dailyQuota := uint64(2)
+	store := &fakeAPIKeyStore{
+		apiKey:     &APIKey{ID: 1, RateLimit: RateLimit{RequestsPerSecond: 1, Burst: 10}, DailyQuota: &dailyQuota},
+		cachedKeys: make(map[string]struct{}),
+	}
+	limiter := NewRateLimiter(RateLimit{RequestsPerSecond: 1, Burst: 1}, store, false)
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(ip string, apiKey string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.RemoteAddr = ip + ":1234"
+		if apiKey != "" {
+			request.Header.Set(APIKeyHeader, apiKey)
+		}
+		request = request.WithContext(ToServerContext(request.Context()).SetRequestID(1))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	tests := []struct {
+		name            string
+		ip              string
+		apiKey          string
+		expectedCode    int
+		expectedLookups int
+	}{
+		{"first anonymous request", "192.0.2.1", "", http.StatusOK, 0},
+		{"anonymous request beyond the burst", "192.0.2.1", "", http.StatusTooManyRequests, 0},
+		{"request with an unknown key beyond the burst of the IP", "192.0.2.1", "made-up", http.StatusTooManyRequests, 0},
+		{"first request with a key", "192.0.2.2", "valid", http.StatusOK, 1},
+		{"second request with a key beyond the burst of the IP", "192.0.2.2", "valid", http.StatusOK, 1},
+		{"request with a key beyond the quota", "192.0.2.2", "valid", http.StatusTooManyRequests, 1},
+		{"request with an invalid key", "192.0.2.3", "invalid", http.StatusUnauthorized, 2},
+		{"request with an invalid key beyond the burst of the IP", "192.0.2.3", "invalid", http.StatusTooManyRequests, 2},
+	}
+	for _, test := range tests {
+		recorder := serve(test.ip, test.apiKey)
+		if recorder.Code != test.expectedCode {
+			t.Errorf("%s: expected code %d but got %d", test.name, test.expectedCode, recorder.Code)
+		}
+		if recorder.Code == http.StatusTooManyRequests && recorder.Header().Get("Retry-After") == "" {
+			t.Errorf("%s: Retry-After is not set", test.name)
+		}
+		if store.lookups != test.expectedLookups {
+			t.Errorf("%s: expected %d API key lookups but got %d", test.name, test.expectedLookups, store.lookups)
+		}
+	}
+}
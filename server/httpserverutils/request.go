@@ -1,10 +1,12 @@
 package httpserverutils
 
 import (
+	"encoding/json"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 	"io/ioutil"
 	"net/http"
+	"time"
 )
 
 // HandlerFunc is a handler function that is passed to the
@@ -13,6 +15,25 @@ import (
 type HandlerFunc func(ctx *ServerContext, r *http.Request, routeParams map[string]string, queryParams map[string]string, requestBody []byte) (
 	interface{}, error)
 
+// CacheableResponse is a handler response that
+// clients and proxies are allowed to cache for
+// up to MaxAge, or forever if IsImmutable is set.
+type CacheableResponse struct {
+	Response    interface{}
+	MaxAge      time.Duration
+	IsImmutable bool
+}
+
+// UnwrapResponse returns the response that's wrapped by
+// the given handler response if it's a CacheableResponse,
+// and otherwise the given response itself.
+func UnwrapResponse(response interface{}) interface{} {
+	if cacheableResponse, ok := response.(*CacheableResponse); ok {
+		return cacheableResponse.Response
+	}
+	return response
+}
+
 // MakeHandler is a wrapper function that takes a handler in the form of HandlerFunc
 // and returns a function that can be used as a handler in mux.Router.HandleFunc.
 func MakeHandler(handler HandlerFunc) func(http.ResponseWriter, *http.Request) {
@@ -34,14 +55,43 @@ func MakeHandler(handler HandlerFunc) func(http.ResponseWriter, *http.Request) {
 			return
 		}
 
+		// GET responses are served from the response cache if
+		// it's enabled, and are sent with an ETag
+		cache := responseCacheFromContext(ctx)
+		cacheKey := r.URL.RequestURI()
+		if r.Method == http.MethodGet && cache != nil {
+			if cached, ok := cache.get(cacheKey, time.Now()); ok {
+				cached.send(w, r)
+				return
+			}
+		}
+
 		response, err := handler(ctx, r, mux.Vars(r), flattenedQueryParams, requestBody)
 		if err != nil {
 			SendErr(ctx, w, err)
 			return
 		}
-		if response != nil {
+		cacheableResponse, isCacheable := response.(*CacheableResponse)
+		if isCacheable {
+			response = cacheableResponse.Response
+		}
+		if response == nil {
+			return
+		}
+		if r.Method != http.MethodGet {
 			SendJSONResponse(w, response)
+			return
+		}
+
+		body, err := json.Marshal(response)
+		if err != nil {
+			panic(err)
+		}
+		cached := newCachedResponse(cacheKey, body, cacheableResponse, time.Now())
+		if isCacheable && cache != nil {
+			cache.add(cached)
 		}
+		cached.send(w, r)
 	}
 }
 
@@ -15,7 +15,8 @@ const timeout = 10 * time.Second
 type Client struct {
 	*rpcclient.RPCClient
 
-	OnBlockAdded chan *appmessage.BlockAddedNotificationMessage
+	OnBlockAdded                        chan *appmessage.BlockAddedNotificationMessage
+	OnVirtualSelectedParentChainChanged chan *appmessage.VirtualSelectedParentChainChangedNotificationMessage
 }
 
 var clientInstance *Client
@@ -43,8 +44,9 @@ func NewClient(cfg *config.CommonConfigFlags, subscribeToNotifications bool) (*C
 
 	const channelCapacity = 1_000_000
 	client := &Client{
-		RPCClient:    rpcClient,
-		OnBlockAdded: make(chan *appmessage.BlockAddedNotificationMessage, channelCapacity),
+		RPCClient:                           rpcClient,
+		OnBlockAdded:                        make(chan *appmessage.BlockAddedNotificationMessage, channelCapacity),
+		OnVirtualSelectedParentChainChanged: make(chan *appmessage.VirtualSelectedParentChainChangedNotificationMessage, channelCapacity),
 	}
 
 	if subscribeToNotifications {
@@ -54,6 +56,14 @@ func NewClient(cfg *config.CommonConfigFlags, subscribeToNotifications bool) (*C
 		if err != nil {
 			return nil, errors.Wrapf(err, "error requesting block-added notifications")
 		}
+
+		err = rpcClient.RegisterForVirtualSelectedParentChainChangedNotifications(
+			func(notification *appmessage.VirtualSelectedParentChainChangedNotificationMessage) {
+				client.OnVirtualSelectedParentChainChanged <- notification
+			})
+		if err != nil {
+			return nil, errors.Wrapf(err, "error requesting virtual-selected-parent-chain-changed notifications")
+		}
 	}
 
 	clientInstance = client
@@ -0,0 +1,19 @@
+package kaspadrpc
+
+import (
+	"context"
+
+	"github.com/someone235/katnip/server/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartSpan starts a span for a call of the given RPC method of the
+// node, as a child of the span in ctx, and returns a function that
+// ends it and records the error of the call if it's not nil
+func StartSpan(ctx context.Context, method string) func(err error) {
+	_, endSpan := tracing.StartSpan(ctx, "kaspad/"+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.RPCSystemKey.String("kaspad"), semconv.RPCMethodKey.String(method)))
+	return endSpan
+}
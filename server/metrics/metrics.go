@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/someone235/katnip/server/database"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+// Start serves the metrics of all the registered collectors, along with the
+// database connection pool stats, in the Prometheus text format under /metrics
+// on the given address. It returns a function to shutdown the metrics server.
+func Start(listenAddr string) func() {
+	prometheus.MustRegister(&dbPoolCollector{})
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	httpServer := &http.Server{
+		Addr:    listenAddr,
+		Handler: mux,
+	}
+	spawn("metrics-Start", func() {
+		log.Infof("Serving metrics on %s", listenAddr)
+		err := httpServer.ListenAndServe()
+		if err != http.ErrServerClosed {
+			log.Errorf("%s", err)
+		}
+	})
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		err := httpServer.Shutdown(ctx)
+		if err != nil {
+			log.Errorf("Error shutting down the metrics server: %s", err)
+		}
+	}
+}
+
+var (
+	dbPoolHitsDesc = prometheus.NewDesc("katnip_db_pool_hits_total",
+		"Number of times a free connection was found in the database connection pool", nil, nil)
+	dbPoolMissesDesc = prometheus.NewDesc("katnip_db_pool_misses_total",
+		"Number of times a free connection was not found in the database connection pool", nil, nil)
+	dbPoolTimeoutsDesc = prometheus.NewDesc("katnip_db_pool_timeouts_total",
+		"Number of times a wait for a database connection timed out", nil, nil)
+	dbPoolConnectionsDesc = prometheus.NewDesc("katnip_db_pool_connections",
+		"Number of connections in the database connection pool", nil, nil)
+	dbPoolIdleConnectionsDesc = prometheus.NewDesc("katnip_db_pool_idle_connections",
+		"Number of idle connections in the database connection pool", nil, nil)
+	dbPoolStaleConnectionsDesc = prometheus.NewDesc("katnip_db_pool_stale_connections_total",
+		"Number of stale connections that were removed from the database connection pool", nil, nil)
+)
+
+// dbPoolCollector collects the stats of the database
+// connection pool whenever the metrics are scraped
+type dbPoolCollector struct{}
+
+func (c *dbPoolCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- dbPoolHitsDesc
+	descs <- dbPoolMissesDesc
+	descs <- dbPoolTimeoutsDesc
+	descs <- dbPoolConnectionsDesc
+	descs <- dbPoolIdleConnectionsDesc
+	descs <- dbPoolStaleConnectionsDesc
+}
+
+func (c *dbPoolCollector) Collect(metrics chan<- prometheus.Metric) {
+	db, err := database.DBInstance()
+	if err != nil {
+		// The database is not connected, so there are no stats to report
+		return
+	}
+	stats := db.PoolStats()
+	metrics <- prometheus.MustNewConstMetric(dbPoolHitsDesc, prometheus.CounterValue, float64(stats.Hits))
+	metrics <- prometheus.MustNewConstMetric(dbPoolMissesDesc, prometheus.CounterValue, float64(stats.Misses))
+	metrics <- prometheus.MustNewConstMetric(dbPoolTimeoutsDesc, prometheus.CounterValue, float64(stats.Timeouts))
+	metrics <- prometheus.MustNewConstMetric(dbPoolConnectionsDesc, prometheus.GaugeValue, float64(stats.TotalConns))
+	metrics <- prometheus.MustNewConstMetric(dbPoolIdleConnectionsDesc, prometheus.GaugeValue, float64(stats.IdleConns))
+	metrics <- prometheus.MustNewConstMetric(dbPoolStaleConnectionsDesc, prometheus.CounterValue, float64(stats.StaleConns))
+}
@@ -0,0 +1,247 @@
+package apikeys
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/httpserverutils"
+)
+
+const (
+	// apiKeyCacheDuration is how long API keys are cached,
+	// and so how long it takes for revocations to apply
+	apiKeyCacheDuration = time.Minute
+	maxCachedAPIKeys    = 10000
+
+	// maxCachedUnknownAPIKeys is the number of unknown API keys that are
+	// cached. They're cached apart from the known API keys, so that
+	// requests with made up keys can't evict the known ones.
+	maxCachedUnknownAPIKeys = 10000
+
+	flushInterval = 10 * time.Second
+)
+
+type cachedAPIKey struct {
+	apiKey    *httpserverutils.APIKey
+	expiresAt time.Time
+}
+
+type usageKey struct {
+	apiKeyID uint64
+	day      time.Time
+}
+
+// usage is the number of requests made with an API key on a day.
+// unflushed is the part of count that isn't in the database yet.
+type usage struct {
+	count     uint64
+	unflushed uint64
+}
+
+// Store is an httpserverutils.APIKeyStore that's backed by the
+// api_keys tables. Request counts are kept in memory and written to
+// the database periodically, so quotas are enforced per serverd
+// instance from the counts that were written by the time each day's
+// count was first loaded.
+type Store struct {
+	keysLock    sync.Mutex
+	keys        map[string]*cachedAPIKey
+	unknownKeys map[string]*cachedAPIKey
+
+	usageLock sync.Mutex
+	usage     map[usageKey]*usage
+}
+
+// NewStore returns a new Store
+func NewStore() *Store {
+	return &Store{
+		keys:        make(map[string]*cachedAPIKey),
+		unknownKeys: make(map[string]*cachedAPIKey),
+		usage:       make(map[usageKey]*usage),
+	}
+}
+
+// CachedAPIKey returns the API key with the given value if it was looked up
+// by APIKey within apiKeyCacheDuration, without looking it up again
+func (s *Store) CachedAPIKey(key string) (apiKey *httpserverutils.APIKey, isCached bool) {
+	keyHash := hashAPIKey(key)
+	now := time.Now()
+
+	s.keysLock.Lock()
+	defer s.keysLock.Unlock()
+	if cached, ok := s.keys[keyHash]; ok && now.Before(cached.expiresAt) {
+		return cached.apiKey, true
+	}
+	if cached, ok := s.unknownKeys[keyHash]; ok && now.Before(cached.expiresAt) {
+		return nil, true
+	}
+	return nil, false
+}
+
+// APIKey returns the API key with the given value,
+// or nil if there's no such key or if it was revoked
+func (s *Store) APIKey(key string) (*httpserverutils.APIKey, error) {
+	if apiKey, isCached := s.CachedAPIKey(key); isCached {
+		return apiKey, nil
+	}
+
+	keyHash := hashAPIKey(key)
+	dbAPIKey, err := dbaccess.APIKeyByHash(database.NoTx(), keyHash)
+	if err != nil {
+		return nil, err
+	}
+	var apiKey *httpserverutils.APIKey
+	if dbAPIKey != nil {
+		apiKey = &httpserverutils.APIKey{
+			ID: dbAPIKey.ID,
+			RateLimit: httpserverutils.RateLimit{
+				RequestsPerSecond: float64(dbAPIKey.APIKeyTier.RequestsPerSecond),
+				Burst:             dbAPIKey.APIKeyTier.Burst,
+			},
+			DailyQuota: dbAPIKey.APIKeyTier.DailyQuota,
+		}
+	}
+
+	now := time.Now()
+	s.keysLock.Lock()
+	defer s.keysLock.Unlock()
+	// Unknown keys are cached as well, so that repeated requests with
+	// the same invalid key don't hit the database
+	if apiKey == nil {
+		cacheAPIKey(s.unknownKeys, maxCachedUnknownAPIKeys, keyHash, nil, now)
+	} else {
+		cacheAPIKey(s.keys, maxCachedAPIKeys, keyHash, apiKey, now)
+	}
+	return apiKey, nil
+}
+
+// cacheAPIKey adds the given API key to the given cache. The cache is
+// emptied if it's full even after its expired keys are removed.
+func cacheAPIKey(cache map[string]*cachedAPIKey, maxSize int, keyHash string,
+	apiKey *httpserverutils.APIKey, now time.Time) {
+
+	if len(cache) >= maxSize {
+		for cachedKeyHash, cached := range cache {
+			if !now.Before(cached.expiresAt) {
+				delete(cache, cachedKeyHash)
+			}
+		}
+		if len(cache) >= maxSize {
+			for cachedKeyHash := range cache {
+				delete(cache, cachedKeyHash)
+			}
+		}
+	}
+	cache[keyHash] = &cachedAPIKey{
+		apiKey:    apiKey,
+		expiresAt: now.Add(apiKeyCacheDuration),
+	}
+}
+
+func hashAPIKey(key string) string {
+	keyHashBytes := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(keyHashBytes[:])
+}
+
+// UseQuota counts a request made with the given API key at `now`, and
+// returns false without counting it if the key exceeded its daily quota
+func (s *Store) UseQuota(apiKey *httpserverutils.APIKey, now time.Time) (bool, error) {
+	key := usageKey{apiKeyID: apiKey.ID, day: dayOf(now)}
+
+	s.usageLock.Lock()
+	keyUsage, ok := s.usage[key]
+	s.usageLock.Unlock()
+	if !ok {
+		count, err := dbaccess.APIKeyRequestCount(database.NoTx(), key.apiKeyID, key.day)
+		if err != nil {
+			return false, err
+		}
+		s.usageLock.Lock()
+		keyUsage, ok = s.usage[key]
+		if !ok {
+			keyUsage = &usage{count: count}
+			s.usage[key] = keyUsage
+		}
+		s.usageLock.Unlock()
+	}
+
+	s.usageLock.Lock()
+	defer s.usageLock.Unlock()
+	if apiKey.DailyQuota != nil && keyUsage.count >= *apiKey.DailyQuota {
+		return false, nil
+	}
+	keyUsage.count++
+	keyUsage.unflushed++
+	return true, nil
+}
+
+// Start starts writing the request counts to the database periodically,
+// and returns a function that stops it and writes the remaining counts.
+func (s *Store) Start() func() {
+	stopChan := make(chan struct{})
+	stoppedChan := make(chan struct{})
+	spawn("apikeys-Start", func() {
+		defer close(stoppedChan)
+		ticker := time.NewTicker(flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.flush(time.Now())
+			case <-stopChan:
+				s.flush(time.Now())
+				return
+			}
+		}
+	})
+
+	return func() {
+		close(stopChan)
+		<-stoppedChan
+	}
+}
+
+// flush writes the unflushed request counts to the database,
+// and forgets the counts of the days before `now`
+func (s *Store) flush(now time.Time) {
+	today := dayOf(now)
+
+	s.usageLock.Lock()
+	unflushedCounts := make(map[usageKey]uint64)
+	for key, keyUsage := range s.usage {
+		if keyUsage.unflushed > 0 {
+			unflushedCounts[key] = keyUsage.unflushed
+			keyUsage.unflushed = 0
+		}
+	}
+	s.usageLock.Unlock()
+
+	failedCounts := make(map[usageKey]uint64)
+	for key, count := range unflushedCounts {
+		err := dbaccess.AddAPIKeyRequestCount(database.NoTx(), key.apiKeyID, key.day, count)
+		if err != nil {
+			log.Errorf("Error writing the request count of API key %d: %s", key.apiKeyID, err)
+			failedCounts[key] = count
+		}
+	}
+
+	s.usageLock.Lock()
+	defer s.usageLock.Unlock()
+	for key, count := range failedCounts {
+		s.usage[key].unflushed += count
+	}
+	for key, keyUsage := range s.usage {
+		if key.day.Before(today) && keyUsage.unflushed == 0 {
+			delete(s.usage, key)
+		}
+	}
+}
+
+func dayOf(t time.Time) time.Time {
+	utcTime := t.UTC()
+	return time.Date(utcTime.Year(), utcTime.Month(), utcTime.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,11 @@
+package apikeys
+
+import (
+	"github.com/kaspanet/kaspad/util/panics"
+	"github.com/someone235/katnip/server/logger"
+)
+
+var (
+	log   = logger.Logger("APIK")
+	spawn = panics.GoroutineWrapperFunc(log)
+)
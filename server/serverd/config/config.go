@@ -2,12 +2,15 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 	"github.com/kaspanet/kaspad/util"
+	"github.com/pkg/errors"
 	"github.com/someone235/katnip/server/config"
 	"github.com/someone235/katnip/server/version"
 )
@@ -19,9 +22,15 @@ const (
 
 var (
 	// Default configuration options
-	defaultLogDir     = util.AppDir("serverd", false)
-	defaultHTTPListen = "0.0.0.0:8080"
-	activeConfig      *Config
+	defaultLogDir                   = util.AppDir("serverd", false)
+	defaultHTTPListen               = "0.0.0.0:8080"
+	defaultGRPCListen               = "0.0.0.0:8081"
+	defaultRateLimit                = 10.0
+	defaultRateLimitBurst           = uint32(20)
+	defaultResponseCacheSize        = 10000
+	defaultReadinessMaxTipAge       = 10 * time.Minute
+	defaultReadinessMaxBlueScoreLag = uint64(600)
+	activeConfig                    *Config
 )
 
 // ActiveConfig returns the active configuration struct
@@ -31,14 +40,35 @@ func ActiveConfig() *Config {
 
 // Config defines the configuration options for the API server.
 type Config struct {
-	HTTPListen string `long:"listen" description:"HTTP address to listen on (default: 0.0.0.0:8080)"`
+	HTTPListen               string        `long:"listen" description:"HTTP address to listen on (default: 0.0.0.0:8080)"`
+	GRPCListen               string        `long:"grpclisten" description:"gRPC address to listen on (default: 0.0.0.0:8081)"`
+	RateLimit                float64       `long:"ratelimit" description:"Requests per second allowed to each IP without an API key (default: 10)"`
+	RateLimitBurst           uint32        `long:"ratelimitburst" description:"Requests allowed at once to each IP without an API key (default: 20)"`
+	ForwardedFor             bool          `long:"forwardedfor" description:"Identify clients by the X-Forwarded-For header, when running behind a reverse proxy"`
+	ResponseCacheSize        int           `long:"responsecachesize" description:"Number of responses to keep in the response cache, or 0 to disable it (default: 10000)"`
+	ReadinessMaxTipAge       time.Duration `long:"readinessmaxtipage" description:"Age of the selected tip beyond which /health/ready fails (default: 10m)"`
+	ReadinessMaxBlueScoreLag uint64        `long:"readinessmaxbluescorelag" description:"Number of blue scores the selected tip may be behind the node before /health/ready fails (default: 600)"`
+	WebhookAllowedNetworks   []string      `long:"webhookallowednetwork" description:"Allow webhooks to be delivered to the given private network, in CIDR notation, e.g. 10.0.0.0/8. May be given more than once"`
 	config.CommonConfigFlags
+
+	webhookAllowedIPNets []*net.IPNet
+}
+
+// WebhookAllowedIPNets returns the parsed --webhookallowednetwork networks
+func (cfg *Config) WebhookAllowedIPNets() []*net.IPNet {
+	return cfg.webhookAllowedIPNets
 }
 
 // Parse parses the CLI arguments and returns a config struct.
 func Parse() error {
 	activeConfig = &Config{
-		HTTPListen: defaultHTTPListen,
+		HTTPListen:               defaultHTTPListen,
+		GRPCListen:               defaultGRPCListen,
+		RateLimit:                defaultRateLimit,
+		RateLimitBurst:           defaultRateLimitBurst,
+		ResponseCacheSize:        defaultResponseCacheSize,
+		ReadinessMaxTipAge:       defaultReadinessMaxTipAge,
+		ReadinessMaxBlueScoreLag: defaultReadinessMaxBlueScoreLag,
 	}
 	parser := flags.NewParser(activeConfig, flags.HelpFlag)
 
@@ -56,6 +86,29 @@ func Parse() error {
 		return err
 	}
 
+	if activeConfig.RateLimit <= 0 {
+		return errors.New("--ratelimit must be positive")
+	}
+	if activeConfig.RateLimitBurst == 0 {
+		return errors.New("--ratelimitburst must be positive")
+	}
+
+	if activeConfig.ResponseCacheSize < 0 {
+		return errors.New("--responsecachesize must not be negative")
+	}
+
+	if activeConfig.ReadinessMaxTipAge <= 0 {
+		return errors.New("--readinessmaxtipage must be positive")
+	}
+
+	for _, network := range activeConfig.WebhookAllowedNetworks {
+		_, ipNet, err := net.ParseCIDR(network)
+		if err != nil {
+			return errors.Errorf("--webhookallowednetwork %s is not in CIDR notation", network)
+		}
+		activeConfig.webhookAllowedIPNets = append(activeConfig.webhookAllowedIPNets, ipNet)
+	}
+
 	err = activeConfig.ResolveCommonFlags(parser, defaultLogDir, logFilename, errLogFilename, false)
 	if err != nil {
 		return err
@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/httpserverutils"
+)
+
+// Balance history intervals
+const (
+	BalanceHistoryIntervalHour  = "hour"
+	BalanceHistoryIntervalDay   = "day"
+	BalanceHistoryIntervalWeek  = "week"
+	BalanceHistoryIntervalMonth = "month"
+)
+
+// maxBalanceHistoryPoints is the maximum number
+// of balances in a balance history response
+const maxBalanceHistoryPoints = 1000
+
+// GetAddressBalanceHandler returns the balance of the given address from the
+// transactions that were accepted by blocks with blue score up to atBlueScore,
+// or its current balance if atBlueScore is nil.
+func GetAddressBalanceHandler(ctx context.Context, address string, atBlueScore *uint64) (interface{}, error) {
+	if err := validateAddress(address); err != nil {
+		return nil, err
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTxWithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	blueScore := selectedTipBlueScore
+	if atBlueScore != nil {
+		if *atBlueScore > selectedTipBlueScore {
+			return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+				errors.Errorf("the given blue score is higher than the blue score of the selected tip (%d)",
+					selectedTipBlueScore))
+		}
+		blueScore = *atBlueScore
+	}
+
+	balance, err := dbaccess.AddressBalanceAtBlueScore(database.NoTxWithContext(ctx), address, blueScore)
+	if err != nil {
+		return nil, err
+	}
+
+	// The balance at a final blue score never changes
+	response := tipDataResponse(&apimodels.AddressBalanceResponse{
+		Address:   address,
+		BlueScore: blueScore,
+		Balance:   balance,
+	})
+	response.IsImmutable = isFinal(blueScore, selectedTipBlueScore)
+	return response, nil
+}
+
+// GetAddressBalanceHistoryHandler returns the balances of the given address at
+// the boundaries of the given interval, from fromTime up to toTime. The balance
+// at a boundary is from the transactions that were accepted by blocks with
+// timestamps up to it. The history starts at the start of the interval that
+// fromTime is in, or if fromTime is nil, that the first transaction of the
+// address is in. If toTime is nil, it ends now.
+func GetAddressBalanceHistoryHandler(ctx context.Context, address string, interval string,
+	fromTime *time.Time, toTime *time.Time) (interface{}, error) {
+
+	if err := validateAddress(address); err != nil {
+		return nil, err
+	}
+
+	if !isBalanceHistoryInterval(interval) {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("unknown interval '%s'. Available intervals: %s, %s, %s, %s", interval,
+				BalanceHistoryIntervalHour, BalanceHistoryIntervalDay, BalanceHistoryIntervalWeek,
+				BalanceHistoryIntervalMonth))
+	}
+
+	historyResponse := &apimodels.AddressBalanceHistoryResponse{
+		Address:  address,
+		Interval: interval,
+		Balances: []*apimodels.AddressBalancePointResponse{},
+	}
+
+	to := time.Now().UTC()
+	if toTime != nil {
+		to = *toTime
+	}
+	var from time.Time
+	if fromTime != nil {
+		from = *fromTime
+	} else {
+		firstBalanceChangeTime, err := dbaccess.AddressFirstBalanceChangeTime(database.NoTxWithContext(ctx), address)
+		if err != nil {
+			return nil, err
+		}
+		if firstBalanceChangeTime == nil {
+			return tipDataResponse(historyResponse), nil
+		}
+		from = *firstBalanceChangeTime
+	}
+
+	boundaries, err := balanceHistoryBoundaries(from, to, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := dbaccess.AddressBalancesAtTimes(database.NoTxWithContext(ctx), address, boundaries)
+	if err != nil {
+		return nil, err
+	}
+	if len(balances) != len(boundaries) {
+		return nil, errors.Errorf("got %d balances for %d balance history boundaries", len(balances), len(boundaries))
+	}
+
+	for i, boundary := range boundaries {
+		historyResponse.Balances = append(historyResponse.Balances, &apimodels.AddressBalancePointResponse{
+			Timestamp: uint64(boundary.Unix()),
+			Balance:   balances[i],
+		})
+	}
+	return tipDataResponse(historyResponse), nil
+}
+
+func isBalanceHistoryInterval(interval string) bool {
+	switch interval {
+	case BalanceHistoryIntervalHour, BalanceHistoryIntervalDay, BalanceHistoryIntervalWeek, BalanceHistoryIntervalMonth:
+		return true
+	default:
+		return false
+	}
+}
+
+// balanceHistoryBoundaries returns the boundaries of the given interval from
+// the start of the interval that `from` is in up to `to` (inclusive)
+func balanceHistoryBoundaries(from time.Time, to time.Time, interval string) ([]time.Time, error) {
+	if from.After(to) {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.New("the given from time is later than the given to time"))
+	}
+
+	// Every boundary is stepped from the start rather than from the previous
+	// boundary, so that months that are shorter than others don't shift it
+	start := truncateToInterval(from, interval)
+	boundaries := make([]time.Time, 0)
+	for i := 0; ; i++ {
+		boundary := addIntervals(start, interval, i)
+		if boundary.After(to) {
+			break
+		}
+		if len(boundaries) == maxBalanceHistoryPoints {
+			return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+				errors.Errorf("more than %d balances were requested. Use a longer interval "+
+					"or a shorter time range", maxBalanceHistoryPoints))
+		}
+		boundaries = append(boundaries, boundary)
+	}
+	return boundaries, nil
+}
+
+// truncateToInterval returns the start of the interval that t is in, in UTC.
+// Weeks start on Monday.
+func truncateToInterval(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	switch interval {
+	case BalanceHistoryIntervalHour:
+		return t.Truncate(time.Hour)
+	case BalanceHistoryIntervalWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		daysSinceMonday := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -daysSinceMonday)
+	case BalanceHistoryIntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// addIntervals returns t plus n of the given interval
+func addIntervals(t time.Time, interval string, n int) time.Time {
+	switch interval {
+	case BalanceHistoryIntervalHour:
+		return t.Add(time.Duration(n) * time.Hour)
+	case BalanceHistoryIntervalWeek:
+		return t.AddDate(0, 0, 7*n)
+	case BalanceHistoryIntervalMonth:
+		return t.AddDate(0, n, 0)
+	default:
+		return t.AddDate(0, 0, n)
+	}
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTruncateToInterval(t *testing.T) {
+	// A Wednesday
+	timestamp := time.Date(2021, time.March, 17, 13, 45, 30, 0, time.UTC)
+	tests := []struct {
+		interval string
+		expected time.Time
+	}{
+		{BalanceHistoryIntervalHour, time.Date(2021, time.March, 17, 13, 0, 0, 0, time.UTC)},
+		{BalanceHistoryIntervalDay, time.Date(2021, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{BalanceHistoryIntervalWeek, time.Date(2021, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		{BalanceHistoryIntervalMonth, time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		truncated := truncateToInterval(timestamp, test.interval)
+		if !truncated.Equal(test.expected) {
+			t.Errorf("%s: expected %s but got %s", test.interval, test.expected, truncated)
+		}
+	}
+}
+
+func TestBalanceHistoryBoundaries(t *testing.T) {
+	from := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	boundaries, err := balanceHistoryBoundaries(from, from.AddDate(0, 2, 0), BalanceHistoryIntervalMonth)
+	if err != nil {
+		t.Fatalf("balanceHistoryBoundaries: %s", err)
+	}
+	if len(boundaries) != 3 || !boundaries[1].Equal(time.Date(2021, time.February, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected monthly boundaries %v", boundaries)
+	}
+
+	boundaries, err = balanceHistoryBoundaries(time.Date(2021, time.January, 31, 12, 0, 0, 0, time.UTC),
+		time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC), BalanceHistoryIntervalMonth)
+	if err != nil {
+		t.Fatalf("balanceHistoryBoundaries: %s", err)
+	}
+	if len(boundaries) != 3 || !boundaries[0].Equal(from) ||
+		!boundaries[1].Equal(time.Date(2021, time.February, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected monthly boundaries from the end of a month %v", boundaries)
+	}
+
+	boundaries, err = balanceHistoryBoundaries(from.Add(30*time.Minute), from.Add(90*time.Minute), BalanceHistoryIntervalDay)
+	if err != nil {
+		t.Fatalf("balanceHistoryBoundaries: %s", err)
+	}
+	if len(boundaries) != 1 || !boundaries[0].Equal(from) {
+		t.Errorf("unexpected daily boundaries %v", boundaries)
+	}
+
+	_, err = balanceHistoryBoundaries(from, from.AddDate(10, 0, 0), BalanceHistoryIntervalHour)
+	if err == nil {
+		t.Errorf("more than %d boundaries were returned", maxBalanceHistoryPoints)
+	}
+
+	_, err = balanceHistoryBoundaries(from, from.Add(-time.Hour), BalanceHistoryIntervalDay)
+	if err == nil {
+		t.Errorf("boundaries were returned for a from time that is later than the to time")
+	}
+}
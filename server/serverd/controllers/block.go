@@ -1,10 +1,12 @@
 package controllers
 
 import (
+	"context"
 	"encoding/hex"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/someone235/katnip/server/database"
 	"net/http"
+	"sort"
 
 	"github.com/someone235/katnip/server/apimodels"
 	"github.com/someone235/katnip/server/dbaccess"
@@ -15,18 +17,35 @@ import (
 	"github.com/someone235/katnip/server/httpserverutils"
 )
 
-const maxGetBlocksLimit = 100
+const (
+	maxGetBlocksLimit           = 100
+	maxBlockNeighbourhoodDepth  = 10
+	maxBlockNeighbourhoodBlocks = 500
+)
 
-// GetBlockByHashHandler returns a block by a given hash.
-func GetBlockByHashHandler(blockHash string) (interface{}, error) {
+// blockWithChildrenPreloadedFields are the fields to preload for the endpoints
+// that return the children of blocks. The children aren't recommended for
+// preloading in general, since they require an extra join.
+var blockWithChildrenPreloadedFields = append([]dbmodels.FieldName{dbmodels.BlockFieldNames.ChildBlocks},
+	dbmodels.BlockRecommendedPreloadedFields...)
+
+func validateBlockHash(blockHash string) error {
 	if bytes, err := hex.DecodeString(blockHash); err != nil || len(bytes) != externalapi.DomainHashSize {
-		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+		return httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
 			errors.Errorf("the given block hash is not a hex-encoded %d-byte hash", externalapi.DomainHashSize))
 	}
+	return nil
+}
+
+// GetBlockByHashHandler returns a block by a given hash.
+func GetBlockByHashHandler(ctx context.Context, blockHash string) (interface{}, error) {
+	if err := validateBlockHash(blockHash); err != nil {
+		return nil, err
+	}
 
 	preloadedFields := append([]dbmodels.FieldName{dbmodels.BlockFieldNames.Transactions},
-		dbmodels.BlockRecommendedPreloadedFields...)
-	block, err := dbaccess.BlockByHash(database.NoTx(), blockHash, preloadedFields...)
+		blockWithChildrenPreloadedFields...)
+	block, err := dbaccess.BlockByHash(database.NoTxWithContext(ctx), blockHash, preloadedFields...)
 	if err != nil {
 		return nil, err
 	}
@@ -34,17 +53,124 @@ func GetBlockByHashHandler(blockHash string) (interface{}, error) {
 		return nil, httpserverutils.NewHandlerError(http.StatusNotFound, errors.New("no block with the given block hash was found"))
 	}
 
-	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTxWithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
 
 	blockRes := apimodels.ConvertBlockModelToBlockResponse(block, selectedTipBlueScore)
-	return blockRes, nil
+	return blockResponse(blockRes, selectedTipBlueScore), nil
+}
+
+// GetBlockChildrenHandler returns all the blocks that have the block
+// with the given hash as one of their parents.
+func GetBlockChildrenHandler(ctx context.Context, blockHash string) (interface{}, error) {
+	if err := validateBlockHash(blockHash); err != nil {
+		return nil, err
+	}
+
+	blockExists, err := dbaccess.DoesBlockExist(database.NoTxWithContext(ctx), blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if !blockExists {
+		return nil, httpserverutils.NewHandlerError(http.StatusNotFound, errors.New("no block with the given block hash was found"))
+	}
+
+	children, err := dbaccess.ChildBlocksByHash(database.NoTxWithContext(ctx), blockHash, blockWithChildrenPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTxWithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	childResponses := make([]*apimodels.BlockResponse, len(children))
+	for i, child := range children {
+		childResponses[i] = apimodels.ConvertBlockModelToBlockResponse(child, selectedTipBlueScore)
+	}
+
+	return childResponses, nil
+}
+
+// GetBlockNeighbourhoodHandler returns the block with the given hash along with
+// all the blocks that are reachable from it by walking up to `depth` parent or
+// child edges in the DAG.
+func GetBlockNeighbourhoodHandler(ctx context.Context, blockHash string, depth int64) (interface{}, error) {
+	if err := validateBlockHash(blockHash); err != nil {
+		return nil, err
+	}
+
+	if depth > maxBlockNeighbourhoodDepth || depth < 0 {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.Errorf("depth higher than %d or lower than 0 was requested", maxBlockNeighbourhoodDepth))
+	}
+
+	block, err := dbaccess.BlockByHash(database.NoTxWithContext(ctx), blockHash, blockWithChildrenPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, httpserverutils.NewHandlerError(http.StatusNotFound, errors.New("no block with the given block hash was found"))
+	}
+
+	blocks := []*dbmodels.Block{block}
+	visited := map[string]struct{}{block.BlockHash: {}}
+	frontier := []*dbmodels.Block{block}
+	for currentDepth := int64(0); currentDepth < depth && len(frontier) > 0; currentDepth++ {
+		neighbourHashes := make([]string, 0)
+		for _, frontierBlock := range frontier {
+			for _, neighbours := range [][]*dbmodels.Block{frontierBlock.ParentBlocks, frontierBlock.ChildBlocks} {
+				for _, neighbour := range neighbours {
+					if _, ok := visited[neighbour.BlockHash]; ok {
+						continue
+					}
+					visited[neighbour.BlockHash] = struct{}{}
+					neighbourHashes = append(neighbourHashes, neighbour.BlockHash)
+				}
+			}
+		}
+
+		if len(visited) > maxBlockNeighbourhoodBlocks {
+			return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+				errors.Errorf("the neighbourhood of depth %d contains more than %d blocks", depth, maxBlockNeighbourhoodBlocks))
+		}
+
+		frontier, err = dbaccess.BlocksByHashes(database.NoTxWithContext(ctx), neighbourHashes, blockWithChildrenPreloadedFields...)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, frontier...)
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].BlueScore != blocks[j].BlueScore {
+			return blocks[i].BlueScore < blocks[j].BlueScore
+		}
+		return blocks[i].ID < blocks[j].ID
+	})
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTxWithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	blockResponses := make([]*apimodels.BlockResponse, len(blocks))
+	for i, block := range blocks {
+		blockResponses[i] = apimodels.ConvertBlockModelToBlockResponse(block, selectedTipBlueScore)
+	}
+
+	return &apimodels.BlockNeighbourhoodResponse{
+		BlockHash: blockHash,
+		Depth:     uint64(depth),
+		Blocks:    blockResponses,
+	}, nil
 }
 
 // GetBlocksHandler searches for all blocks
-func GetBlocksHandler(orderString string, skip, limit int64) (interface{}, error) {
+func GetBlocksHandler(ctx context.Context, orderString string, skip, limit int64) (interface{}, error) {
 	if limit > maxGetBlocksLimit || limit < 1 {
 		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
 			errors.Errorf("limit higher than %d or lower than 1 was requested", maxGetBlocksLimit))
@@ -60,12 +186,12 @@ func GetBlocksHandler(orderString string, skip, limit int64) (interface{}, error
 		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity, err)
 	}
 
-	blocks, err := dbaccess.Blocks(database.NoTx(), order, uint64(skip), uint64(limit), dbmodels.BlockRecommendedPreloadedFields...)
+	blocks, err := dbaccess.Blocks(database.NoTxWithContext(ctx), order, uint64(skip), uint64(limit), dbmodels.BlockRecommendedPreloadedFields...)
 	if err != nil {
 		return nil, err
 	}
 
-	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTxWithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -75,10 +201,73 @@ func GetBlocksHandler(orderString string, skip, limit int64) (interface{}, error
 		blockResponses[i] = apimodels.ConvertBlockModelToBlockResponse(block, selectedTipBlueScore)
 	}
 
-	return blockResponses, nil
+	return tipDataResponse(blockResponses), nil
+}
+
+// GetBlocksPageHandler returns a page of blocks ordered by blue score,
+// alongside cursors to the pages before and after it. An empty cursor
+// fetches the first page in the given order.
+func GetBlocksPageHandler(ctx context.Context, orderString string, cursorString string, limit int64) (interface{}, error) {
+	if limit > maxGetBlocksLimit || limit < 1 {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.Errorf("limit higher than %d or lower than 1 was requested", maxGetBlocksLimit))
+	}
+
+	order, err := dbaccess.StringToOrder(orderString)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity, err)
+	}
+
+	cursor, err := decodePageCursor(cursorString)
+	if err != nil {
+		return nil, err
+	}
+
+	queryOrder, position := pageQuery(order, cursor)
+	blocks, err := dbaccess.BlocksAfterCursor(database.NoTxWithContext(ctx), queryOrder, position, uint64(limit)+1,
+		dbmodels.BlockRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(blocks) > int(limit)
+	if hasMore {
+		blocks = blocks[:limit]
+	}
+	if pageIsReversed(cursor) {
+		for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+			blocks[i], blocks[j] = blocks[j], blocks[i]
+		}
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTxWithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	blockResponses := make([]*apimodels.BlockResponse, len(blocks))
+	positions := make([]*dbaccess.Cursor, len(blocks))
+	for i, block := range blocks {
+		blockResponses[i] = apimodels.ConvertBlockModelToBlockResponse(block, selectedTipBlueScore)
+		positions[i] = &dbaccess.Cursor{BlueScore: block.BlueScore, ID: block.ID}
+	}
+
+	next, prev, err := pageCursors(order, cursor, positions, hasMore)
+	if err != nil {
+		return nil, err
+	}
+
+	return tipDataResponse(&apimodels.PaginatedResponse{
+		Items: blockResponses,
+		Next:  next,
+		Prev:  prev,
+	}), nil
 }
 
 // GetBlockCountHandler returns the total number of blocks.
-func GetBlockCountHandler() (interface{}, error) {
-	return dbaccess.BlocksCount(database.NoTx())
+func GetBlockCountHandler(ctx context.Context) (interface{}, error) {
+	count, err := dbaccess.BlocksCount(database.NoTxWithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return tipDataResponse(count), nil
 }
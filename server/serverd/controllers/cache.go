@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/httpserverutils"
+	"github.com/someone235/katnip/server/serverd/config"
+)
+
+// tipDataMaxAge is how long responses that change
+// with the tip of the DAG may be cached
+const tipDataMaxAge = 2 * time.Second
+
+// finalityDepth returns the number of blue scores below the selected
+// tip beyond which the DAG, and so the data of its blocks, never changes
+func finalityDepth() uint64 {
+	params := config.ActiveConfig().NetParams()
+	return uint64(params.FinalityDuration / params.TargetTimePerBlock)
+}
+
+func isFinal(blueScore uint64, selectedTipBlueScore uint64) bool {
+	return blueScore+finalityDepth() < selectedTipBlueScore
+}
+
+func tipDataResponse(response interface{}) *httpserverutils.CacheableResponse {
+	return &httpserverutils.CacheableResponse{
+		Response: response,
+		MaxAge:   tipDataMaxAge,
+	}
+}
+
+// blockResponse marks the given block as immutable if it's final
+func blockResponse(block *apimodels.BlockResponse, selectedTipBlueScore uint64) *httpserverutils.CacheableResponse {
+	response := tipDataResponse(block)
+	response.IsImmutable = isFinal(block.BlueScore, selectedTipBlueScore)
+	return response
+}
+
+// transactionResponse returns the given transaction as a response that's never
+// immutable, even if it was accepted by a final block. Its outputs may still be
+// spent, and the transactions that spend them may be accepted by blocks that
+// aren't final yet, so a reorg may unspend them again.
+func transactionResponse(transaction *apimodels.TransactionResponse) *httpserverutils.CacheableResponse {
+	return tipDataResponse(transaction)
+}
@@ -1,14 +1,18 @@
 package controllers
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/kaspanet/kaspad/util"
 	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/apimodels"
 	"github.com/someone235/katnip/server/httpserverutils"
 	"github.com/someone235/katnip/server/serverd/config"
 )
 
+const maxAddressesPerRequest = 1000
+
 func validateAddress(address string) error {
 	_, err := util.DecodeAddress(address, config.ActiveConfig().ActiveNetParams.Prefix)
 	if err != nil {
@@ -19,3 +23,38 @@ func validateAddress(address string) error {
 
 	return nil
 }
+
+// parseAddressesRequest parses and validates the addresses of the given
+// AddressesRequest body, and returns them without duplicates
+func parseAddressesRequest(requestBody []byte) ([]string, error) {
+	request := &apimodels.AddressesRequest{}
+	err := json.Unmarshal(requestBody, request)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
+			errors.Wrap(err, "error unmarshalling request body"),
+			"The request body is not json-formatted")
+	}
+
+	if len(request.Addresses) == 0 {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.New("no addresses were requested"))
+	}
+	if len(request.Addresses) > maxAddressesPerRequest {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("more than %d addresses were requested", maxAddressesPerRequest))
+	}
+
+	addresses := make([]string, 0, len(request.Addresses))
+	addressSet := make(map[string]struct{}, len(request.Addresses))
+	for _, address := range request.Addresses {
+		if _, exists := addressSet[address]; exists {
+			continue
+		}
+		if err := validateAddress(address); err != nil {
+			return nil, err
+		}
+		addressSet[address] = struct{}{}
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
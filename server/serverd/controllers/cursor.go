@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/httpserverutils"
+)
+
+// pageCursor is the content of the opaque cursor tokens that are
+// handed to clients of paginated lists. It's a position in the
+// list, the order of the list, and the direction to page in.
+type pageCursor struct {
+	BlueScore uint64         `json:"blueScore"`
+	ID        uint64         `json:"id"`
+	Order     dbaccess.Order `json:"order"`
+	Backward  bool           `json:"backward"`
+}
+
+func (cursor *pageCursor) position() *dbaccess.Cursor {
+	return &dbaccess.Cursor{
+		BlueScore: cursor.BlueScore,
+		ID:        cursor.ID,
+	}
+}
+
+func encodePageCursor(cursor *pageCursor) (*string, error) {
+	cursorBytes, err := json.Marshal(cursor)
+	if err != nil {
+		return nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(cursorBytes)
+	return &token, nil
+}
+
+// decodePageCursor decodes the given cursor token. An empty
+// token is the cursor of the first page, and decodes to nil.
+func decodePageCursor(token string) (*pageCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	invalidCursorError := func(err error) error {
+		return httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
+			errors.Wrap(err, "error decoding cursor"), "The given cursor is invalid")
+	}
+	cursorBytes, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalidCursorError(err)
+	}
+	cursor := &pageCursor{}
+	err = json.Unmarshal(cursorBytes, cursor)
+	if err != nil {
+		return nil, invalidCursorError(err)
+	}
+	if _, err := dbaccess.StringToOrder(string(cursor.Order)); err != nil {
+		return nil, invalidCursorError(err)
+	}
+	return cursor, nil
+}
+
+// pageQuery returns the order in which the rows of a page should be
+// queried, and the position to query them from, for the given cursor.
+// Backward pages are queried in reverse order, and should be reversed
+// back with the result of pageIsReversed.
+func pageQuery(order dbaccess.Order, cursor *pageCursor) (dbaccess.Order, *dbaccess.Cursor) {
+	if cursor == nil {
+		return order, nil
+	}
+	if cursor.Backward {
+		return cursor.Order.Reverse(), cursor.position()
+	}
+	return cursor.Order, cursor.position()
+}
+
+func pageIsReversed(cursor *pageCursor) bool {
+	return cursor != nil && cursor.Backward
+}
+
+// pageCursors returns the next and prev cursor tokens of a page, given the
+// cursor it was fetched with, the positions of its items in list order, and
+// whether more rows were found past the page in the direction it was fetched.
+func pageCursors(order dbaccess.Order, cursor *pageCursor, positions []*dbaccess.Cursor,
+	hasMore bool) (next *string, prev *string, err error) {
+
+	if cursor != nil {
+		order = cursor.Order
+	}
+
+	// An empty page has no items to point at,
+	// so both its cursors point where it was fetched
+	var first, last *dbaccess.Cursor
+	if len(positions) > 0 {
+		first, last = positions[0], positions[len(positions)-1]
+	} else if cursor != nil {
+		first, last = cursor.position(), cursor.position()
+	}
+
+	hasNext := hasMore
+	hasPrev := cursor != nil
+	if pageIsReversed(cursor) {
+		hasNext, hasPrev = hasPrev, hasMore
+	}
+
+	if hasNext && last != nil {
+		next, err = encodePageCursor(&pageCursor{BlueScore: last.BlueScore, ID: last.ID, Order: order})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if hasPrev && first != nil {
+		prev, err = encodePageCursor(&pageCursor{BlueScore: first.BlueScore, ID: first.ID, Order: order, Backward: true})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return next, prev, nil
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/httpserverutils"
+)
+
+const (
+	maxDAGBlueScoreRange     = 1000
+	maxDAGBlocks             = 2000
+	defaultDAGBlueScoreRange = 100
+
+	// stableDAGBlueScoreDepth is the distance from the selected tip
+	// beyond which a section of the DAG is not expected to change
+	stableDAGBlueScoreDepth = 1000
+
+	stableDAGMaxAge   = 24 * time.Hour
+	unstableDAGMaxAge = time.Second
+)
+
+// GetDAGHandler returns the blocks with blue score between `fromBlueScore` and
+// `toBlueScore` (inclusive) and the edges between them and their parents.
+// If `toBlueScore` is nil, the blue score of the selected tip is used.
+// If `fromBlueScore` is nil, the DAG of the last defaultDAGBlueScoreRange
+// blue scores up to `toBlueScore` is returned.
+func GetDAGHandler(ctx context.Context, fromBlueScore, toBlueScore *uint64) (interface{}, error) {
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTxWithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if toBlueScore == nil {
+		toBlueScore = &selectedTipBlueScore
+	}
+	if fromBlueScore == nil {
+		defaultFromBlueScore := uint64(0)
+		if *toBlueScore > defaultDAGBlueScoreRange {
+			defaultFromBlueScore = *toBlueScore - defaultDAGBlueScoreRange
+		}
+		fromBlueScore = &defaultFromBlueScore
+	}
+
+	if *fromBlueScore > *toBlueScore {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.New("fromBlueScore is higher than toBlueScore"))
+	}
+	if *toBlueScore-*fromBlueScore > maxDAGBlueScoreRange {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.Errorf("a blue score range larger than %d was requested", maxDAGBlueScoreRange))
+	}
+
+	// Fetch one extra block to find out whether the result is truncated
+	dagBlocks, err := dbaccess.DAGBlocksByBlueScoreRange(database.NoTxWithContext(ctx), *fromBlueScore, *toBlueScore, maxDAGBlocks+1)
+	if err != nil {
+		return nil, err
+	}
+	isTruncated := len(dagBlocks) > maxDAGBlocks
+	if isTruncated {
+		dagBlocks = dagBlocks[:maxDAGBlocks]
+	}
+
+	blockIDs := make([]uint64, len(dagBlocks))
+	for i, dagBlock := range dagBlocks {
+		blockIDs[i] = dagBlock.ID
+	}
+	dagEdges, err := dbaccess.DAGEdgesByBlockIDs(database.NoTxWithContext(ctx), blockIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	dagResponse := &apimodels.DAGResponse{
+		FromBlueScore: *fromBlueScore,
+		ToBlueScore:   *toBlueScore,
+		Truncated:     isTruncated,
+		Blocks:        make([]*apimodels.DAGBlock, len(dagBlocks)),
+		Edges:         make([]*apimodels.DAGEdge, len(dagEdges)),
+	}
+	for i, dagBlock := range dagBlocks {
+		dagResponse.Blocks[i] = apimodels.ConvertDAGBlockToDAGBlockResponse(dagBlock)
+	}
+	for i, dagEdge := range dagEdges {
+		dagResponse.Edges[i] = apimodels.ConvertDAGEdgeToDAGEdgeResponse(dagEdge)
+	}
+
+	maxAge := unstableDAGMaxAge
+	if *toBlueScore+stableDAGBlueScoreDepth < selectedTipBlueScore {
+		maxAge = stableDAGMaxAge
+	}
+
+	return &httpserverutils.CacheableResponse{
+		Response: dagResponse,
+		MaxAge:   maxAge,
+	}, nil
+}
@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/httpserverutils"
+)
+
+// Address history export formats
+const (
+	AddressHistoryFormatCSV  = "csv"
+	AddressHistoryFormatJSON = "json"
+)
+
+// addressHistoryBatchSize is the number of transactions
+// that are fetched from the database at a time
+const addressHistoryBatchSize = 1000
+
+var addressHistoryCSVHeader = []string{"timestamp", "transactionId", "direction", "netAmount", "fee", "runningBalance"}
+
+// ExportAddressHistoryHandler writes the accepted transactions of the given address,
+// whose accepting blocks have timestamps between fromTime and toTime, to w in the
+// given format, ordered by the blue scores of their accepting blocks. Transactions
+// are fetched and written in batches, so the history is never held in memory.
+// Errors are returned only until the response status is sent. After that, they
+// are logged and the export is cut short, which leaves a JSON export unterminated.
+func ExportAddressHistoryHandler(ctx *httpserverutils.ServerContext, w http.ResponseWriter, address string,
+	format string, fromTime *time.Time, toTime *time.Time) error {
+
+	if err := validateAddress(address); err != nil {
+		return err
+	}
+
+	historyWriter, err := newAddressHistoryWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	if fromTime != nil && toTime != nil && fromTime.After(*toTime) {
+		return httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.New("the given from time is later than the given to time"))
+	}
+
+	// The whole export reads one snapshot, so that a reorg that syncd
+	// applies meanwhile can't make the batches disagree with each other
+	dbTx, err := database.NewSnapshotTxWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbTx.RollbackUnlessCommitted()
+
+	entries, err := dbaccess.AddressHistoryAfterCursor(dbTx, address, fromTime, toTime, nil, addressHistoryBatchSize)
+	if err != nil {
+		return err
+	}
+
+	// The running balance starts from the balance before the first exported
+	// transaction, so that it's the actual balance even if fromTime is set
+	var balance int64
+	if len(entries) > 0 {
+		balance, err = dbaccess.AddressBalanceBeforeCursor(dbTx, address, addressHistoryEntryPosition(entries[0]))
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Header().Set("Content-Type", historyWriter.contentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-history.%s"`,
+		strings.ReplaceAll(address, ":", "_"), format))
+	w.WriteHeader(http.StatusOK)
+
+	err = writeAddressHistory(dbTx, historyWriter, address, fromTime, toTime, entries, balance)
+	if err != nil {
+		ctx.Errorf("Error exporting the history of address %s: %s", address, err)
+	}
+	return nil
+}
+
+// writeAddressHistory writes the given first batch of history entries, and the batches
+// that follow it, which it reads in dbCtx, starting the running balance from `balance`
+func writeAddressHistory(dbCtx database.Context, historyWriter addressHistoryWriter, address string,
+	fromTime *time.Time, toTime *time.Time, entries []*dbaccess.AddressHistoryEntry, balance int64) error {
+
+	err := historyWriter.start()
+	if err != nil {
+		return err
+	}
+
+	for len(entries) > 0 {
+		for _, entry := range entries {
+			balance += entry.NetValue
+			err := historyWriter.writeEntry(convertAddressHistoryEntry(entry, balance))
+			if err != nil {
+				return err
+			}
+		}
+		err = historyWriter.flush()
+		if err != nil {
+			return err
+		}
+
+		if len(entries) < addressHistoryBatchSize {
+			break
+		}
+		entries, err = dbaccess.AddressHistoryAfterCursor(dbCtx, address, fromTime, toTime,
+			addressHistoryEntryPosition(entries[len(entries)-1]), addressHistoryBatchSize)
+		if err != nil {
+			return err
+		}
+	}
+
+	return historyWriter.finish()
+}
+
+func addressHistoryEntryPosition(entry *dbaccess.AddressHistoryEntry) *dbaccess.Cursor {
+	return &dbaccess.Cursor{
+		BlueScore: entry.AcceptingBlockBlueScore,
+		ID:        entry.ID,
+	}
+}
+
+// convertAddressHistoryEntry converts the given history entry to its
+// response, with the given balance of the address after it
+func convertAddressHistoryEntry(entry *dbaccess.AddressHistoryEntry, runningBalance int64) *apimodels.AddressHistoryEntryResponse {
+	response := &apimodels.AddressHistoryEntryResponse{
+		Timestamp:      uint64(entry.AcceptingBlockTimestamp.Unix()),
+		TransactionID:  entry.TransactionID,
+		Direction:      string(dbaccess.TransactionDirectionIncoming),
+		NetAmount:      entry.NetValue,
+		RunningBalance: runningBalance,
+	}
+	if entry.NetValue < 0 {
+		response.Direction = string(dbaccess.TransactionDirectionOutgoing)
+		if entry.Fee != nil {
+			response.Fee = *entry.Fee
+		}
+	}
+	return response
+}
+
+// addressHistoryWriter writes the entries of an address history export in some format
+type addressHistoryWriter interface {
+	contentType() string
+	start() error
+	writeEntry(entry *apimodels.AddressHistoryEntryResponse) error
+
+	// flush sends everything that was written so far to the client
+	flush() error
+
+	// finish writes the end of the export and flushes it
+	finish() error
+}
+
+func newAddressHistoryWriter(w io.Writer, format string) (addressHistoryWriter, error) {
+	switch format {
+	case AddressHistoryFormatCSV:
+		return &csvAddressHistoryWriter{w: w, csvWriter: csv.NewWriter(w)}, nil
+	case AddressHistoryFormatJSON:
+		return &jsonAddressHistoryWriter{w: w}, nil
+	default:
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("unknown format '%s'. Available formats: %s, %s", format,
+				AddressHistoryFormatCSV, AddressHistoryFormatJSON))
+	}
+}
+
+// flushWriter sends what was written to w to the client,
+// if w is a response writer that supports streaming
+func flushWriter(w io.Writer) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// csvAddressHistoryWriter writes a CSV export with a header row. Timestamps
+// are written in RFC 3339 format, in UTC.
+type csvAddressHistoryWriter struct {
+	w         io.Writer
+	csvWriter *csv.Writer
+}
+
+func (writer *csvAddressHistoryWriter) contentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (writer *csvAddressHistoryWriter) start() error {
+	return writer.csvWriter.Write(addressHistoryCSVHeader)
+}
+
+func (writer *csvAddressHistoryWriter) writeEntry(entry *apimodels.AddressHistoryEntryResponse) error {
+	return writer.csvWriter.Write([]string{
+		time.Unix(int64(entry.Timestamp), 0).UTC().Format(time.RFC3339),
+		entry.TransactionID,
+		entry.Direction,
+		strconv.FormatInt(entry.NetAmount, 10),
+		strconv.FormatUint(entry.Fee, 10),
+		strconv.FormatInt(entry.RunningBalance, 10),
+	})
+}
+
+func (writer *csvAddressHistoryWriter) flush() error {
+	writer.csvWriter.Flush()
+	err := writer.csvWriter.Error()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	flushWriter(writer.w)
+	return nil
+}
+
+func (writer *csvAddressHistoryWriter) finish() error {
+	return writer.flush()
+}
+
+// jsonAddressHistoryWriter writes a JSON export as an array of entries
+type jsonAddressHistoryWriter struct {
+	w            io.Writer
+	writtenCount int
+}
+
+func (writer *jsonAddressHistoryWriter) contentType() string {
+	return "application/json; charset=utf-8"
+}
+
+func (writer *jsonAddressHistoryWriter) start() error {
+	_, err := io.WriteString(writer.w, "[")
+	return errors.WithStack(err)
+}
+
+func (writer *jsonAddressHistoryWriter) writeEntry(entry *apimodels.AddressHistoryEntryResponse) error {
+	if writer.writtenCount > 0 {
+		_, err := io.WriteString(writer.w, ",")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	entryJSON, err := json.Marshal(entry)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = writer.w.Write(entryJSON)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	writer.writtenCount++
+	return nil
+}
+
+func (writer *jsonAddressHistoryWriter) flush() error {
+	flushWriter(writer.w)
+	return nil
+}
+
+func (writer *jsonAddressHistoryWriter) finish() error {
+	_, err := io.WriteString(writer.w, "]\n")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	flushWriter(writer.w)
+	return nil
+}
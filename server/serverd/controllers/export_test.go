@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/someone235/katnip/server/dbaccess"
+)
+
+func TestAddressHistoryWriters(t *testing.T) {
+	fee := uint64(10)
+	entries := []*dbaccess.AddressHistoryEntry{
+		{ID: 1, TransactionID: "a", AcceptingBlockTimestamp: time.Unix(0, 0), NetValue: 100},
+		{ID: 2, TransactionID: "b", Fee: &fee, AcceptingBlockTimestamp: time.Unix(60, 0), NetValue: -40},
+	}
+
+	tests := []struct {
+		format         string
+		expectedOutput string
+	}{
+		{
+			format: AddressHistoryFormatCSV,
+			expectedOutput: "timestamp,transactionId,direction,netAmount,fee,runningBalance\n" +
+				"1970-01-01T00:00:00Z,a,incoming,100,0,105\n" +
+				"1970-01-01T00:01:00Z,b,outgoing,-40,10,65\n",
+		},
+		{
+			format: AddressHistoryFormatJSON,
+			expectedOutput: `[{"timestamp":0,"transactionId":"a","direction":"incoming","netAmount":100,"fee":0,"runningBalance":105},` +
+				`{"timestamp":60,"transactionId":"b","direction":"outgoing","netAmount":-40,"fee":10,"runningBalance":65}]` + "\n",
+		},
+	}
+	for _, test := range tests {
+		output := &bytes.Buffer{}
+		historyWriter, err := newAddressHistoryWriter(output, test.format)
+		if err != nil {
+			t.Fatalf("%s: newAddressHistoryWriter: %s", test.format, err)
+		}
+		err = writeAddressHistory(nil, historyWriter, "", nil, nil, entries, 5)
+		if err != nil {
+			t.Fatalf("%s: writeAddressHistory: %s", test.format, err)
+		}
+		if output.String() != test.expectedOutput {
+			t.Errorf("%s: expected output:\n%s\nbut got:\n%s", test.format, test.expectedOutput, output.String())
+		}
+	}
+
+	_, err := newAddressHistoryWriter(&bytes.Buffer{}, "xml")
+	if err == nil {
+		t.Errorf("an unknown format was accepted")
+	}
+}
@@ -1,13 +1,163 @@
 package controllers
 
-import "github.com/someone235/katnip/server/apimodels"
+import (
+	"context"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/httpserverutils"
+	"github.com/someone235/katnip/server/kaspadrpc"
+	"github.com/someone235/katnip/server/serverd/config"
+)
+
+const (
+	// feeEstimateBlueScoreWindow is the number of blue scores below the
+	// selected tip whose accepted transactions are sampled for estimation
+	feeEstimateBlueScoreWindow = 1000
+
+	// feeEstimateTargetSampleSize is the number of sampled transactions
+	// above which the estimates are reported with full confidence
+	feeEstimateTargetSampleSize = 100
+
+	// minimumFeeRate is the minimum relay fee rate of the node,
+	// in sompi per gram of mass
+	minimumFeeRate = 1
+)
+
+// feeEstimatePriority defines, for one priority, the percentile of recently
+// accepted fee rates and the number of blocks in which the mempool must be
+// cleared up to the estimated fee rate
+type feeEstimatePriority struct {
+	percentile float64
+	blocks     uint64
+}
+
+var (
+	highFeeEstimatePriority   = &feeEstimatePriority{percentile: 90, blocks: 1}
+	normalFeeEstimatePriority = &feeEstimatePriority{percentile: 50, blocks: 10}
+	lowFeeEstimatePriority    = &feeEstimatePriority{percentile: 10, blocks: 100}
+)
+
+// mempoolFeeRate is the fee rate and mass of a transaction in the mempool
+type mempoolFeeRate struct {
+	feeRate float64
+	mass    uint64
+}
 
 // GetFeeEstimatesHandler returns the fee estimates for different priorities
-// for accepting a transaction in the DAG.
-func GetFeeEstimatesHandler() (interface{}, error) {
-	return &apimodels.FeeEstimateResponse{
-		HighPriority:   3,
-		NormalPriority: 2,
-		LowPriority:    1,
-	}, nil
+// for accepting a transaction in the DAG, in sompi per gram of mass.
+// The estimates are derived from the fee rates of the transactions that were
+// accepted recently, and are raised by the fee rate that is required to get
+// ahead of the transactions that are currently waiting in the mempool.
+func GetFeeEstimatesHandler(ctx context.Context) (interface{}, error) {
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTxWithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	fromBlueScore := uint64(0)
+	if selectedTipBlueScore > feeEstimateBlueScoreWindow {
+		fromBlueScore = selectedTipBlueScore - feeEstimateBlueScoreWindow
+	}
+
+	priorities := []*feeEstimatePriority{highFeeEstimatePriority, normalFeeEstimatePriority, lowFeeEstimatePriority}
+	percentiles := make([]float64, len(priorities))
+	for i, priority := range priorities {
+		percentiles[i] = priority.percentile / 100
+	}
+	sampleSize, acceptedFeeRates, err := dbaccess.AcceptedTransactionFeeRatePercentiles(
+		database.NoTxWithContext(ctx), fromBlueScore, percentiles)
+	if err != nil {
+		return nil, err
+	}
+	// There are no percentiles when there are no samples, in
+	// which case the estimates fall back to the minimum fee rate
+	if len(acceptedFeeRates) != len(priorities) {
+		acceptedFeeRates = make([]float64, len(priorities))
+	}
+
+	mempoolFeeRates, err := getMempoolFeeRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mempoolMass := uint64(0)
+	for _, entry := range mempoolFeeRates {
+		mempoolMass += entry.mass
+	}
+
+	maxBlockMass := config.ActiveConfig().NetParams().MaxBlockMass
+	return tipDataResponse(&apimodels.FeeEstimateResponse{
+		HighPriority:    estimateFeeRate(acceptedFeeRates[0], mempoolFeeRates, maxBlockMass, highFeeEstimatePriority),
+		NormalPriority:  estimateFeeRate(acceptedFeeRates[1], mempoolFeeRates, maxBlockMass, normalFeeEstimatePriority),
+		LowPriority:     estimateFeeRate(acceptedFeeRates[2], mempoolFeeRates, maxBlockMass, lowFeeEstimatePriority),
+		Confidence:      math.Min(1, float64(sampleSize)/feeEstimateTargetSampleSize),
+		SampleSize:      sampleSize,
+		FromBlueScore:   fromBlueScore,
+		ToBlueScore:     selectedTipBlueScore,
+		MempoolSize:     uint64(len(mempoolFeeRates)),
+		MempoolMass:     mempoolMass,
+		MempoolPressure: float64(mempoolMass) / float64(maxBlockMass),
+	}), nil
+}
+
+// getMempoolFeeRates returns the fee rates of the transactions
+// in the mempool of the node, in descending order
+func getMempoolFeeRates(ctx context.Context) ([]*mempoolFeeRate, error) {
+	client, err := kaspadrpc.GetClient()
+	if err != nil {
+		return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusServiceUnavailable,
+			err, "The node is not available")
+	}
+
+	endSpan := kaspadrpc.StartSpan(ctx, "GetMempoolEntries")
+	response, err := client.GetMempoolEntries()
+	endSpan(err)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusBadGateway,
+			err, "Could not get the mempool entries of the node")
+	}
+
+	feeRates := make([]*mempoolFeeRate, 0, len(response.Entries))
+	for _, entry := range response.Entries {
+		if entry.Transaction.VerboseData == nil || entry.Transaction.VerboseData.Mass == 0 {
+			continue
+		}
+		mass := entry.Transaction.VerboseData.Mass
+		feeRates = append(feeRates, &mempoolFeeRate{
+			feeRate: float64(entry.Fee) / float64(mass),
+			mass:    mass,
+		})
+	}
+	sort.Slice(feeRates, func(i, j int) bool {
+		return feeRates[i].feeRate > feeRates[j].feeRate
+	})
+
+	return feeRates, nil
+}
+
+// estimateFeeRate returns the fee rate for the given priority. It is the highest
+// of the minimum relay fee rate, the given priority's percentile of the recently
+// accepted fee rates, and the fee rate that gets a transaction ahead of the mempool
+// transactions that don't fit in the priority's number of blocks, assuming the
+// highest paying transactions are picked first.
+// mempoolFeeRates must be sorted in descending order.
+func estimateFeeRate(acceptedFeeRate float64, mempoolFeeRates []*mempoolFeeRate, maxBlockMass uint64,
+	priority *feeEstimatePriority) float64 {
+
+	feeRate := math.Max(minimumFeeRate, acceptedFeeRate)
+
+	capacity := maxBlockMass * priority.blocks
+	cumulativeMass := uint64(0)
+	for _, entry := range mempoolFeeRates {
+		cumulativeMass += entry.mass
+		if cumulativeMass >= capacity {
+			feeRate = math.Max(feeRate, entry.feeRate)
+			break
+		}
+	}
+
+	return feeRate
 }
@@ -0,0 +1,34 @@
+package controllers
+
+import "testing"
+
+func TestEstimateFeeRate(t *testing.T) {
+	congestedMempool := []*mempoolFeeRate{
+		{feeRate: 50, mass: 400},
+		{feeRate: 30, mass: 400},
+		{feeRate: 10, mass: 400},
+	}
+
+	tests := []struct {
+		name            string
+		acceptedFeeRate float64
+		mempoolFeeRates []*mempoolFeeRate
+		priority        *feeEstimatePriority
+		expectedFeeRate float64
+	}{
+		{"no data", 0, nil, normalFeeEstimatePriority, minimumFeeRate},
+		{"below the minimum fee rate", 0.5, nil, lowFeeEstimatePriority, minimumFeeRate},
+		{"accepted fee rate", 13, nil, highFeeEstimatePriority, 13},
+		{"mempool fits in one block", 13, congestedMempool[:1], highFeeEstimatePriority, 13},
+		{"congested mempool", 13, congestedMempool, highFeeEstimatePriority, 30},
+		{"congested mempool clears in time", 3, congestedMempool, normalFeeEstimatePriority, 3},
+	}
+
+	const maxBlockMass = 700
+	for _, test := range tests {
+		feeRate := estimateFeeRate(test.acceptedFeeRate, test.mempoolFeeRates, maxBlockMass, test.priority)
+		if feeRate != test.expectedFeeRate {
+			t.Errorf("%s: Expected fee rate %f but got %f", test.name, test.expectedFeeRate, feeRate)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/httpserverutils"
+	"github.com/someone235/katnip/server/kaspadrpc"
+	"github.com/someone235/katnip/server/serverd/config"
+)
+
+// nodeCallTimeout bounds each node call of the sync status, so that an
+// unresponsive node doesn't make the health checks outlast their probes
+const nodeCallTimeout = 2 * time.Second
+
+// GetSyncStatusHandler returns how far the database is synced with the node
+func GetSyncStatusHandler(ctx context.Context) (interface{}, error) {
+	syncStatus, _, err := getSyncStatus(ctx, time.Now(), true)
+	if err != nil {
+		return nil, err
+	}
+	return tipDataResponse(syncStatus), nil
+}
+
+// GetReadinessHandler returns an error with 503 Service Unavailable if the
+// database is not available, if the selected tip is older than the configured
+// max tip age, or if it's further behind the node's virtual selected parent than
+// the configured max blue score lag. An unavailable node doesn't fail the check,
+// since most of the API is served from the database alone. The node is called
+// once, for its blue score, and its call is bounded by nodeCallTimeout.
+func GetReadinessHandler(ctx context.Context) error {
+	_, problems, err := getSyncStatus(ctx, time.Now(), false)
+	if err != nil {
+		return httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusServiceUnavailable,
+			err, "The database is not available")
+	}
+	if len(problems) > 0 {
+		return httpserverutils.NewHandlerError(http.StatusServiceUnavailable,
+			errors.Errorf("Katnip server is not ready: %s", strings.Join(problems, "; ")))
+	}
+	return nil
+}
+
+// getSyncStatus returns the sync status at `now` and the reasons why the
+// database is not synced according to the configured thresholds. The block
+// and header counts of the node are only fetched if withNodeDAGInfo is set.
+func getSyncStatus(ctx context.Context, now time.Time, withNodeDAGInfo bool) (
+	*apimodels.SyncStatusResponse, []string, error) {
+
+	selectedTip, err := dbaccess.SelectedTip(database.NoTxWithContext(ctx))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	syncStatus := &apimodels.SyncStatusResponse{}
+	if selectedTip != nil {
+		syncStatus.SelectedTipHash = selectedTip.BlockHash
+		syncStatus.SelectedTipBlueScore = selectedTip.BlueScore
+		syncStatus.SelectedTipTimestamp = uint64(selectedTip.Timestamp.Unix())
+		if selectedTipAge := now.Sub(selectedTip.Timestamp); selectedTipAge > 0 {
+			syncStatus.SelectedTipAge = uint64(selectedTipAge.Seconds())
+		}
+	}
+
+	// Errors from the node are not returned, and
+	// leave the node fields of the sync status nil
+	client, err := kaspadrpc.GetClient()
+	if err == nil {
+		var blueScoreResponse *appmessage.GetVirtualSelectedParentBlueScoreResponseMessage
+		err := callNodeWithTimeout(ctx, "GetVirtualSelectedParentBlueScore", func() (err error) {
+			blueScoreResponse, err = client.GetVirtualSelectedParentBlueScore()
+			return err
+		})
+		if err == nil {
+			syncStatus.NodeVirtualSelectedParentBlueScore = &blueScoreResponse.BlueScore
+			blueScoreLag := uint64(0)
+			if blueScoreResponse.BlueScore > syncStatus.SelectedTipBlueScore {
+				blueScoreLag = blueScoreResponse.BlueScore - syncStatus.SelectedTipBlueScore
+			}
+			syncStatus.BlueScoreLag = &blueScoreLag
+		}
+
+		if withNodeDAGInfo {
+			var dagInfoResponse *appmessage.GetBlockDAGInfoResponseMessage
+			err := callNodeWithTimeout(ctx, "GetBlockDAGInfo", func() (err error) {
+				dagInfoResponse, err = client.GetBlockDAGInfo()
+				return err
+			})
+			if err == nil {
+				syncStatus.NodeBlockCount = &dagInfoResponse.BlockCount
+				syncStatus.NodeHeaderCount = &dagInfoResponse.HeaderCount
+			}
+		}
+	}
+
+	problems := syncProblems(syncStatus, config.ActiveConfig().ReadinessMaxTipAge,
+		config.ActiveConfig().ReadinessMaxBlueScoreLag)
+	syncStatus.IsSynced = len(problems) == 0
+	return syncStatus, problems, nil
+}
+
+// callNodeWithTimeout calls the node with the given call, and returns an error
+// without waiting for it to finish if it takes longer than nodeCallTimeout. The
+// call must not be used after this returns with an error.
+func callNodeWithTimeout(ctx context.Context, name string, call func() error) error {
+	errChan := make(chan error, 1)
+	endSpan := kaspadrpc.StartSpan(ctx, name)
+	go func() {
+		err := call()
+		endSpan(err)
+		errChan <- err
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-time.After(nodeCallTimeout):
+		return errors.Errorf("%s timed out after %s", name, nodeCallTimeout)
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	}
+}
+
+// syncProblems returns the reasons why the given sync status
+// is not synced according to the given thresholds
+func syncProblems(syncStatus *apimodels.SyncStatusResponse, maxTipAge time.Duration, maxBlueScoreLag uint64) []string {
+	if syncStatus.SelectedTipHash == "" {
+		return []string{"no blocks were synced yet"}
+	}
+
+	var problems []string
+	selectedTipAge := time.Duration(syncStatus.SelectedTipAge) * time.Second
+	if selectedTipAge > maxTipAge {
+		problems = append(problems, fmt.Sprintf("the selected tip is %s old", selectedTipAge))
+	}
+	if syncStatus.BlueScoreLag != nil && *syncStatus.BlueScoreLag > maxBlueScoreLag {
+		problems = append(problems, fmt.Sprintf("the selected tip is %d blue scores behind the node",
+			*syncStatus.BlueScoreLag))
+	}
+	return problems
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/someone235/katnip/server/apimodels"
+)
+
+func TestSyncProblems(t *testing.T) {
+	const maxTipAge = 10 * time.Minute
+	const maxBlueScoreLag = 600
+	smallLag := uint64(10)
+	largeLag := uint64(1000)
+
+	tests := []struct {
+		name                string
+		syncStatus          *apimodels.SyncStatusResponse
+		expectedProblemsLen int
+	}{
+		{"empty database", &apimodels.SyncStatusResponse{}, 1},
+		{"synced", &apimodels.SyncStatusResponse{SelectedTipHash: "a", SelectedTipAge: 1, BlueScoreLag: &smallLag}, 0},
+		{"node unavailable", &apimodels.SyncStatusResponse{SelectedTipHash: "a", SelectedTipAge: 1}, 0},
+		{"old selected tip", &apimodels.SyncStatusResponse{SelectedTipHash: "a", SelectedTipAge: 3600, BlueScoreLag: &smallLag}, 1},
+		{"lagging behind the node", &apimodels.SyncStatusResponse{SelectedTipHash: "a", SelectedTipAge: 1, BlueScoreLag: &largeLag}, 1},
+		{"stalled", &apimodels.SyncStatusResponse{SelectedTipHash: "a", SelectedTipAge: 3600, BlueScoreLag: &largeLag}, 2},
+	}
+	for _, test := range tests {
+		problems := syncProblems(test.syncStatus, maxTipAge, maxBlueScoreLag)
+		if len(problems) != test.expectedProblemsLen {
+			t.Errorf("%s: expected %d problems but got %v", test.name, test.expectedProblemsLen, problems)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/httpserverutils"
+	"github.com/someone235/katnip/server/serverd/config"
+)
+
+const (
+	// minSearchPrefixLength is the minimal number of characters of a
+	// hash prefix, or of an address prefix after its network prefix
+	minSearchPrefixLength = 8
+
+	maxSearchResultsPerType = 10
+
+	// addressCharset is the set of characters that may appear in
+	// an address after its network prefix
+	addressCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+// GetSearchHandler classifies the given query as a hash, a hash
+// prefix, an address or an address prefix, and returns the blocks,
+// transactions and addresses that match it.
+func GetSearchHandler(ctx context.Context, query string) (interface{}, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	var results []*apimodels.SearchResultResponse
+	var err error
+	switch {
+	case isHex(query):
+		results, err = searchHash(ctx, query)
+	case strings.HasPrefix(query, config.ActiveConfig().ActiveNetParams.Prefix.String()+":"):
+		results, err = searchAddress(ctx, query)
+	default:
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.New("the search query is neither a hex-encoded hash nor an address"))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &apimodels.SearchResponse{
+		Query:   query,
+		Results: results,
+	}, nil
+}
+
+func isHex(query string) bool {
+	if len(query) == 0 || len(query) > externalapi.DomainHashSize*2 {
+		return false
+	}
+	// An odd-length prefix can't be decoded on its own, so
+	// it's checked for hex characters with a padding digit
+	_, err := hex.DecodeString(query + strings.Repeat("0", len(query)%2))
+	return err == nil
+}
+
+// searchHash returns the blocks and transactions whose hash or ID
+// starts with the given hex string. Transactions are also matched
+// by their full transaction hash.
+func searchHash(ctx context.Context, query string) ([]*apimodels.SearchResultResponse, error) {
+	if len(query) < minSearchPrefixLength {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("hash prefixes must be at least %d characters long", minSearchPrefixLength))
+	}
+
+	results := make([]*apimodels.SearchResultResponse, 0)
+	blockHashes, err := dbaccess.BlockHashesByPrefix(database.NoTxWithContext(ctx), query, maxSearchResultsPerType)
+	if err != nil {
+		return nil, err
+	}
+	for _, blockHash := range blockHashes {
+		results = append(results, &apimodels.SearchResultResponse{
+			Type:  apimodels.SearchResultTypeBlock,
+			Value: blockHash,
+		})
+	}
+
+	transactionIDs, err := dbaccess.TransactionIDsByPrefix(database.NoTxWithContext(ctx), query, maxSearchResultsPerType)
+	if err != nil {
+		return nil, err
+	}
+	if len(query) == externalapi.DomainHashSize*2 {
+		tx, err := dbaccess.TransactionByHash(database.NoTxWithContext(ctx), query)
+		if err != nil {
+			return nil, err
+		}
+		if tx != nil && tx.TransactionID != query {
+			transactionIDs = append(transactionIDs, tx.TransactionID)
+		}
+	}
+	for _, transactionID := range transactionIDs {
+		results = append(results, &apimodels.SearchResultResponse{
+			Type:  apimodels.SearchResultTypeTransaction,
+			Value: transactionID,
+		})
+	}
+
+	return results, nil
+}
+
+// searchAddress returns the addresses that start with the given address prefix
+func searchAddress(ctx context.Context, query string) ([]*apimodels.SearchResultResponse, error) {
+	payload := query[strings.Index(query, ":")+1:]
+	if len(payload) < minSearchPrefixLength {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("address prefixes must have at least %d characters after the network prefix",
+				minSearchPrefixLength))
+	}
+	for _, char := range payload {
+		if !strings.ContainsRune(addressCharset, char) {
+			return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+				errors.Errorf("'%c' is not a valid address character", char))
+		}
+	}
+
+	addresses, err := dbaccess.AddressesByPrefix(database.NoTxWithContext(ctx), query, maxSearchResultsPerType)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*apimodels.SearchResultResponse, len(addresses))
+	for i, address := range addresses {
+		results[i] = &apimodels.SearchResultResponse{
+			Type:  apimodels.SearchResultTypeAddress,
+			Value: address,
+		}
+	}
+	return results, nil
+}
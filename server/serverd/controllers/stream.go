@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/httpserverutils"
+	"github.com/someone235/katnip/server/serverd/stream"
+)
+
+// NewStreamFilter returns a stream filter for the given comma-separated topics,
+// address and minimum transaction value. If no topics are given, all the topics
+// are selected.
+func NewStreamFilter(topics string, address string, minValue uint64) (*stream.Filter, error) {
+	filter := &stream.Filter{
+		Topics:   make(map[string]struct{}),
+		Address:  address,
+		MinValue: minValue,
+	}
+
+	knownTopics := make(map[string]struct{}, len(stream.Topics))
+	for _, topic := range stream.Topics {
+		knownTopics[topic] = struct{}{}
+	}
+	if topics == "" {
+		filter.Topics = knownTopics
+	} else {
+		for _, topic := range strings.Split(topics, ",") {
+			if _, ok := knownTopics[topic]; !ok {
+				return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+					errors.Errorf("unknown topic '%s'. Available topics: %s", topic, strings.Join(stream.Topics, ", ")))
+			}
+			filter.Topics[topic] = struct{}{}
+		}
+	}
+
+	if address != "" {
+		if err := validateAddress(address); err != nil {
+			return nil, err
+		}
+	}
+
+	return filter, nil
+}
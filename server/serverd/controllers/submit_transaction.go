@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/httpserverutils"
+	"github.com/someone235/katnip/server/kaspadrpc"
+)
+
+// maxRawTransactionSize is the maximum size of a hex-encoded
+// transaction that is accepted for submission
+const maxRawTransactionSize = 1_000_000
+
+// transactionRejection maps a rejection reason reported by
+// the node to the error that is returned to the client
+type transactionRejection struct {
+	reason        string
+	code          int
+	clientMessage string
+}
+
+// transactionRejections are checked in order against the rejection
+// message of the node, so more specific reasons should come first
+var transactionRejections = []*transactionRejection{
+	{
+		reason:        "already have transaction",
+		code:          http.StatusConflict,
+		clientMessage: "The transaction is already in the mempool",
+	},
+	{
+		reason:        "already spent by transaction",
+		code:          http.StatusConflict,
+		clientMessage: "The transaction double spends an output that is already spent in the mempool",
+	},
+	{
+		reason:        "orphan",
+		code:          http.StatusUnprocessableEntity,
+		clientMessage: "The transaction spends outputs that are unknown or already spent",
+	},
+	{
+		reason:        "under the required amount",
+		code:          http.StatusUnprocessableEntity,
+		clientMessage: "The transaction fee is too low",
+	},
+	{
+		reason:        "is not standard",
+		code:          http.StatusUnprocessableEntity,
+		clientMessage: "The transaction is not standard",
+	},
+	{
+		reason:        "Could not parse transaction",
+		code:          http.StatusUnprocessableEntity,
+		clientMessage: "The node could not parse the transaction",
+	},
+	{
+		reason:        "Rejected transaction",
+		code:          http.StatusUnprocessableEntity,
+		clientMessage: "The transaction was rejected by the node",
+	},
+}
+
+// PostTransactionHandler relays the given serialized transaction
+// to the node, and returns its transaction ID.
+func PostTransactionHandler(ctx context.Context, requestBody []byte) (interface{}, error) {
+	rawTransaction := &apimodels.RawTransaction{}
+	err := json.Unmarshal(requestBody, rawTransaction)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
+			errors.Wrap(err, "error unmarshalling request body"),
+			"The request body is not json-formatted")
+	}
+
+	transaction, err := deserializeRawTransaction(rawTransaction.RawTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kaspadrpc.GetClient()
+	if err != nil {
+		return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusServiceUnavailable,
+			err, "The node is not available")
+	}
+
+	transactionID := consensushashing.TransactionID(transaction).String()
+	endSpan := kaspadrpc.StartSpan(ctx, "SubmitTransaction")
+	response, err := client.SubmitTransaction(appmessage.DomainTransactionToRPCTransaction(transaction))
+	endSpan(err)
+	if err != nil {
+		return nil, convertTransactionRejectionToHandlerError(transactionID, err)
+	}
+
+	return &apimodels.SubmitTransactionResponse{
+		TransactionID: response.TransactionID,
+	}, nil
+}
+
+// deserializeRawTransaction decodes the given hex-encoded transaction,
+// as produced by `kaspawallet sign`, and validates its basic structure
+func deserializeRawTransaction(rawTransaction string) (*externalapi.DomainTransaction, error) {
+	if len(rawTransaction) == 0 {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.New("rawTransaction is required"))
+	}
+	if len(rawTransaction) > maxRawTransactionSize {
+		return nil, httpserverutils.NewHandlerError(http.StatusRequestEntityTooLarge,
+			errors.Errorf("rawTransaction is longer than %d characters", maxRawTransactionSize))
+	}
+
+	transactionBytes, err := hex.DecodeString(rawTransaction)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
+			errors.Wrap(err, "error decoding rawTransaction"),
+			"rawTransaction is not hex-encoded")
+	}
+
+	transaction, err := libkaspawallet.ExtractTransaction(transactionBytes)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
+			errors.Wrap(err, "error deserializing rawTransaction"),
+			"rawTransaction is not a valid fully signed transaction")
+	}
+
+	if transaction.SubnetworkID.Equal(&subnetworks.SubnetworkIDCoinbase) {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.New("coinbase transactions cannot be submitted"))
+	}
+	if len(transaction.Inputs) == 0 {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.New("the transaction has no inputs"))
+	}
+	if len(transaction.Outputs) == 0 {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.New("the transaction has no outputs"))
+	}
+
+	outpointSet := make(map[externalapi.DomainOutpoint]struct{}, len(transaction.Inputs))
+	for _, input := range transaction.Inputs {
+		if _, exists := outpointSet[input.PreviousOutpoint]; exists {
+			return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+				errors.Errorf("the transaction spends the output %s more than once", &input.PreviousOutpoint))
+		}
+		outpointSet[input.PreviousOutpoint] = struct{}{}
+	}
+	for i, output := range transaction.Outputs {
+		if output.Value == 0 {
+			return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+				errors.Errorf("output %d of the transaction has no value", i))
+		}
+	}
+
+	return transaction, nil
+}
+
+// convertTransactionRejectionToHandlerError converts an error returned
+// by the node's submit-transaction RPC to a HandlerError. The node only
+// reports the rejection reason as text, so it's matched by its message.
+func convertTransactionRejectionToHandlerError(transactionID string, err error) error {
+	for _, rejection := range transactionRejections {
+		if strings.Contains(err.Error(), rejection.reason) {
+			return httpserverutils.NewHandlerErrorWithCustomClientMessage(rejection.code,
+				errors.Wrapf(err, "error submitting transaction %s", transactionID),
+				rejection.clientMessage+": "+rejectionReason(err))
+		}
+	}
+	return httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusBadGateway,
+		errors.Wrapf(err, "error submitting transaction %s", transactionID),
+		"Could not relay the transaction to the node")
+}
+
+// rejectionReason strips the RPC client prefix
+// from the rejection message of the node
+func rejectionReason(err error) string {
+	const rpcErrorPrefix = "received error response from RPC: "
+	message := err.Error()
+	if index := strings.Index(message, rpcErrorPrefix); index != -1 {
+		return message[index+len(rpcErrorPrefix):]
+	}
+	return message
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"encoding/hex"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"net/http"
@@ -17,7 +18,7 @@ import (
 const maxGetTransactionsLimit = 1000
 
 // GetTransactionByIDHandler returns a transaction by a given transaction ID.
-func GetTransactionByIDHandler(txID string) (interface{}, error) {
+func GetTransactionByIDHandler(ctx context.Context, txID string) (interface{}, error) {
 	if bytes, err := hex.DecodeString(txID); err != nil || len(bytes) != externalapi.DomainHashSize {
 		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
 			errors.Errorf("The given txid is not a hex-encoded %d-byte hash", externalapi.DomainHashSize))
@@ -26,7 +27,7 @@ func GetTransactionByIDHandler(txID string) (interface{}, error) {
 	preloadedFields := append([]dbmodels.FieldName{dbmodels.TransactionFieldNames.Blocks},
 		dbmodels.TransactionRecommendedPreloadedFields...)
 
-	tx, err := dbaccess.TransactionByID(database.NoTx(), txID, preloadedFields...)
+	tx, err := dbaccess.TransactionByID(database.NoTxWithContext(ctx), txID, preloadedFields...)
 	if err != nil {
 		return nil, err
 	}
@@ -34,23 +35,23 @@ func GetTransactionByIDHandler(txID string) (interface{}, error) {
 		return nil, httpserverutils.NewHandlerError(http.StatusNotFound, errors.New("no transaction with the given txid was found"))
 	}
 
-	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTxWithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
 
 	txResponse := apimodels.ConvertTxModelToTxResponse(tx, selectedTipBlueScore)
-	return txResponse, nil
+	return transactionResponse(txResponse), nil
 }
 
 // GetTransactionByHashHandler returns a transaction by a given transaction hash.
-func GetTransactionByHashHandler(txHash string) (interface{}, error) {
+func GetTransactionByHashHandler(ctx context.Context, txHash string) (interface{}, error) {
 	if bytes, err := hex.DecodeString(txHash); err != nil || len(bytes) != externalapi.DomainHashSize {
 		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
 			errors.Errorf("The given txhash is not a hex-encoded %d-byte hash", externalapi.DomainHashSize))
 	}
 
-	tx, err := dbaccess.TransactionByHash(database.NoTx(), txHash, dbmodels.TransactionRecommendedPreloadedFields...)
+	tx, err := dbaccess.TransactionByHash(database.NoTxWithContext(ctx), txHash, dbmodels.TransactionRecommendedPreloadedFields...)
 	if err != nil {
 		return nil, err
 	}
@@ -58,18 +59,21 @@ func GetTransactionByHashHandler(txHash string) (interface{}, error) {
 		return nil, httpserverutils.NewHandlerError(http.StatusNotFound, errors.New("no transaction with the given txhash was found"))
 	}
 
-	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTxWithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
 
 	txResponse := apimodels.ConvertTxModelToTxResponse(tx, selectedTipBlueScore)
-	return txResponse, nil
+	return transactionResponse(txResponse), nil
 }
 
 // GetTransactionsByAddressHandler searches for all transactions
-// where the given address is either an input or an output.
-func GetTransactionsByAddressHandler(address string, skip, limit int64) (interface{}, error) {
+// where the given address is either an input or an output, and
+// that match the given filter.
+func GetTransactionsByAddressHandler(ctx context.Context, address string, filter *dbaccess.AddressTransactionsFilter, orderString string,
+	skip, limit int64) (interface{}, error) {
+
 	if limit > maxGetTransactionsLimit || limit < 1 {
 		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
 			errors.Errorf("limit higher than %d or lower than 1 was requested", maxGetTransactionsLimit))
@@ -84,13 +88,22 @@ func GetTransactionsByAddressHandler(address string, skip, limit int64) (interfa
 		return nil, err
 	}
 
-	txs, err := dbaccess.TransactionsByAddress(database.NoTx(), address, dbaccess.OrderAscending, uint64(skip), uint64(limit),
+	if err := validateAddressTransactionsFilter(filter); err != nil {
+		return nil, err
+	}
+
+	order, err := dbaccess.StringToOrder(orderString)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity, err)
+	}
+
+	txs, err := dbaccess.TransactionsByAddress(database.NoTxWithContext(ctx), address, filter, order, uint64(skip), uint64(limit),
 		dbmodels.TransactionRecommendedPreloadedFields...)
 	if err != nil {
 		return nil, err
 	}
 
-	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTxWithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -98,25 +111,242 @@ func GetTransactionsByAddressHandler(address string, skip, limit int64) (interfa
 	txResponses := make([]*apimodels.TransactionResponse, len(txs))
 	for i, tx := range txs {
 		txResponses[i] = apimodels.ConvertTxModelToTxResponse(tx, selectedTipBlueScore)
+		netValue := addressNetValue(tx, address)
+		txResponses[i].NetValue = &netValue
 	}
 
 	return txResponses, nil
 }
 
+// GetTransactionsByAddressPageHandler returns a page of the transactions
+// where the given address is either an input or an output, and that match
+// the given filter, alongside cursors to the pages before and after it.
+// The cursors are only valid for the same filter. An empty cursor fetches
+// the first page in the given order.
+func GetTransactionsByAddressPageHandler(ctx context.Context, address string, filter *dbaccess.AddressTransactionsFilter, orderString string,
+	cursorString string, limit int64) (interface{}, error) {
+
+	if limit > maxGetTransactionsLimit || limit < 1 {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.Errorf("limit higher than %d or lower than 1 was requested", maxGetTransactionsLimit))
+	}
+
+	if err := validateAddress(address); err != nil {
+		return nil, err
+	}
+
+	if err := validateAddressTransactionsFilter(filter); err != nil {
+		return nil, err
+	}
+
+	order, err := dbaccess.StringToOrder(orderString)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity, err)
+	}
+
+	cursor, err := decodePageCursor(cursorString)
+	if err != nil {
+		return nil, err
+	}
+
+	queryOrder, position := pageQuery(order, cursor)
+	txs, err := dbaccess.TransactionsByAddressAfterCursor(database.NoTxWithContext(ctx), address, filter, queryOrder, position,
+		uint64(limit)+1, dbmodels.TransactionRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(txs) > int(limit)
+	if hasMore {
+		txs = txs[:limit]
+	}
+	if pageIsReversed(cursor) {
+		for i, j := 0, len(txs)-1; i < j; i, j = i+1, j-1 {
+			txs[i], txs[j] = txs[j], txs[i]
+		}
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTxWithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	txResponses := make([]*apimodels.TransactionResponse, len(txs))
+	positions := make([]*dbaccess.Cursor, len(txs))
+	for i, tx := range txs {
+		txResponses[i] = apimodels.ConvertTxModelToTxResponse(tx, selectedTipBlueScore)
+		netValue := addressNetValue(tx, address)
+		txResponses[i].NetValue = &netValue
+		positions[i] = &dbaccess.Cursor{ID: tx.ID}
+	}
+
+	next, prev, err := pageCursors(order, cursor, positions, hasMore)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apimodels.PaginatedResponse{
+		Items: txResponses,
+		Next:  next,
+		Prev:  prev,
+	}, nil
+}
+
+// PostTransactionsByAddressesHandler returns a page of the transactions where any
+// of the addresses in the given request body is either an input or an output,
+// grouped by address, alongside cursors to the pages before and after it.
+// A transaction that involves several of the addresses is listed under each of
+// them. The cursors are only valid for the same addresses. An empty cursor
+// fetches the first page.
+func PostTransactionsByAddressesHandler(ctx context.Context, requestBody []byte, cursorString string, limit int64) (interface{}, error) {
+	if limit > maxGetTransactionsLimit || limit < 1 {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.Errorf("limit higher than %d or lower than 1 was requested", maxGetTransactionsLimit))
+	}
+
+	addresses, err := parseAddressesRequest(requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := decodePageCursor(cursorString)
+	if err != nil {
+		return nil, err
+	}
+
+	queryOrder, position := pageQuery(dbaccess.OrderAscending, cursor)
+	addressTransactions, err := dbaccess.AddressTransactionsAfterCursor(database.NoTxWithContext(ctx), addresses, queryOrder, position,
+		uint64(limit)+1)
+	if err != nil {
+		return nil, err
+	}
+
+	// addressTransactions are ordered by transaction,
+	// so the IDs of the page are collected in order
+	transactionIDs := make([]uint64, 0, limit+1)
+	for _, addressTransaction := range addressTransactions {
+		if len(transactionIDs) == 0 || transactionIDs[len(transactionIDs)-1] != addressTransaction.TransactionID {
+			transactionIDs = append(transactionIDs, addressTransaction.TransactionID)
+		}
+	}
+	hasMore := len(transactionIDs) > int(limit)
+	if hasMore {
+		transactionIDs = transactionIDs[:limit]
+	}
+	if pageIsReversed(cursor) {
+		for i, j := 0, len(transactionIDs)-1; i < j; i, j = i+1, j-1 {
+			transactionIDs[i], transactionIDs[j] = transactionIDs[j], transactionIDs[i]
+		}
+	}
+
+	txs, err := dbaccess.TransactionsByDatabaseIDs(database.NoTxWithContext(ctx), transactionIDs,
+		dbmodels.TransactionRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTxWithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	idsToTxResponses := make(map[uint64]*apimodels.TransactionResponse, len(txs))
+	for _, tx := range txs {
+		idsToTxResponses[tx.ID] = apimodels.ConvertTxModelToTxResponse(tx, selectedTipBlueScore)
+	}
+
+	addressesToTxIDs := make(map[string]map[uint64]struct{}, len(addresses))
+	for _, addressTransaction := range addressTransactions {
+		if _, ok := addressesToTxIDs[addressTransaction.Address]; !ok {
+			addressesToTxIDs[addressTransaction.Address] = make(map[uint64]struct{})
+		}
+		addressesToTxIDs[addressTransaction.Address][addressTransaction.TransactionID] = struct{}{}
+	}
+
+	addressTransactionsResponses := make([]*apimodels.AddressTransactionsResponse, len(addresses))
+	for i, address := range addresses {
+		txResponses := make([]*apimodels.TransactionResponse, 0)
+		for _, transactionID := range transactionIDs {
+			if _, ok := addressesToTxIDs[address][transactionID]; !ok {
+				continue
+			}
+			if txResponse, ok := idsToTxResponses[transactionID]; ok {
+				txResponses = append(txResponses, txResponse)
+			}
+		}
+		addressTransactionsResponses[i] = &apimodels.AddressTransactionsResponse{
+			Address:      address,
+			Transactions: txResponses,
+		}
+	}
+
+	positions := make([]*dbaccess.Cursor, len(transactionIDs))
+	for i, transactionID := range transactionIDs {
+		positions[i] = &dbaccess.Cursor{ID: transactionID}
+	}
+	next, prev, err := pageCursors(dbaccess.OrderAscending, cursor, positions, hasMore)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apimodels.PaginatedResponse{
+		Items: addressTransactionsResponses,
+		Next:  next,
+		Prev:  prev,
+	}, nil
+}
+
+func validateAddressTransactionsFilter(filter *dbaccess.AddressTransactionsFilter) error {
+	switch filter.Direction {
+	case dbaccess.TransactionDirectionAny, dbaccess.TransactionDirectionIncoming, dbaccess.TransactionDirectionOutgoing:
+	default:
+		return httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.Errorf("'%s' is not a valid direction", filter.Direction))
+	}
+
+	if filter.FromBlueScore != nil && filter.ToBlueScore != nil && *filter.FromBlueScore > *filter.ToBlueScore {
+		return httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.New("fromBlueScore is higher than toBlueScore"))
+	}
+	if filter.FromTime != nil && filter.ToTime != nil && filter.FromTime.After(*filter.ToTime) {
+		return httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.New("fromTime is later than toTime"))
+	}
+	return nil
+}
+
+// addressNetValue returns the change in the balance of the given address
+// in the given transaction. It requires the addresses of the outputs and
+// of the previous outputs of the inputs of the transaction to be preloaded.
+func addressNetValue(tx *dbmodels.Transaction, address string) int64 {
+	netValue := int64(0)
+	for _, output := range tx.TransactionOutputs {
+		if output.Address != nil && output.Address.Address == address {
+			netValue += int64(output.Value)
+		}
+	}
+	for _, input := range tx.TransactionInputs {
+		previousOutput := input.PreviousTransactionOutput
+		if previousOutput != nil && previousOutput.Address != nil && previousOutput.Address.Address == address {
+			netValue -= int64(previousOutput.Value)
+		}
+	}
+	return netValue
+}
+
 // GetTransactionCountByAddressHandler returns the total
 // number of transactions by address.
-func GetTransactionCountByAddressHandler(address string) (interface{}, error) {
+func GetTransactionCountByAddressHandler(ctx context.Context, address string) (interface{}, error) {
 	if err := validateAddress(address); err != nil {
 		return nil, err
 	}
 
-	return dbaccess.TransactionsByAddressCount(database.NoTx(), address)
+	return dbaccess.TransactionsByAddressCount(database.NoTxWithContext(ctx), address)
 }
 
 // GetTransactionsByBlockHashHandler retrieves all transactions
 // included by the block with the given blockHash.
-func GetTransactionsByBlockHashHandler(blockHash string) (interface{}, error) {
-	txs, err := dbaccess.TransactionsByBlockHash(database.NoTx(), blockHash, dbmodels.TransactionRecommendedPreloadedFields...)
+func GetTransactionsByBlockHashHandler(ctx context.Context, blockHash string) (interface{}, error) {
+	txs, err := dbaccess.TransactionsByBlockHash(database.NoTxWithContext(ctx), blockHash, dbmodels.TransactionRecommendedPreloadedFields...)
 	if err != nil {
 		return nil, err
 	}
@@ -124,7 +354,7 @@ func GetTransactionsByBlockHashHandler(blockHash string) (interface{}, error) {
 		return nil, httpserverutils.NewHandlerError(http.StatusNotFound, errors.New("no block with the given block hash was found"))
 	}
 
-	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTxWithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -141,18 +371,18 @@ func GetTransactionsByBlockHashHandler(blockHash string) (interface{}, error) {
 
 // GetTransactionDoubleSpends returns array of transactions that spend
 // at least one of the same inputs as the given transaction
-func GetTransactionDoubleSpends(txID string) (interface{}, error) {
+func GetTransactionDoubleSpends(ctx context.Context, txID string) (interface{}, error) {
 	if bytes, err := hex.DecodeString(txID); err != nil || len(bytes) != externalapi.DomainHashSize {
 		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
 			errors.Errorf("The given txid is not a hex-encoded %d-byte hash", externalapi.DomainHashSize))
 	}
 
-	txs, err := dbaccess.TransactionDoubleSpends(database.NoTx(), txID)
+	txs, err := dbaccess.TransactionDoubleSpends(database.NoTxWithContext(ctx), txID)
 	if err != nil {
 		return nil, err
 	}
 
-	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTxWithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+func TestAddressNetValue(t *testing.T) {
+	address := &dbmodels.Address{Address: "kaspa:a"}
+	otherAddress := &dbmodels.Address{Address: "kaspa:b"}
+	tx := &dbmodels.Transaction{
+		TransactionOutputs: []dbmodels.TransactionOutput{
+			{Value: 30, Address: otherAddress},
+			{Value: 60, Address: address},
+			{Value: 5},
+		},
+		TransactionInputs: []dbmodels.TransactionInput{
+			{PreviousTransactionOutput: &dbmodels.TransactionOutput{Value: 100, Address: address}},
+			{PreviousTransactionOutput: &dbmodels.TransactionOutput{Value: 10, Address: otherAddress}},
+			{},
+		},
+	}
+
+	tests := []struct {
+		address          string
+		expectedNetValue int64
+	}{
+		{address: "kaspa:a", expectedNetValue: -40},
+		{address: "kaspa:b", expectedNetValue: 20},
+		{address: "kaspa:c", expectedNetValue: 0},
+	}
+	for _, test := range tests {
+		netValue := addressNetValue(tx, test.address)
+		if netValue != test.expectedNetValue {
+			t.Errorf("%s: expected net value %d but got %d", test.address, test.expectedNetValue, netValue)
+		}
+	}
+}
+
+func TestValidateAddressTransactionsFilter(t *testing.T) {
+	lowBlueScore := uint64(10)
+	highBlueScore := uint64(20)
+	earlyTime := time.Unix(1000, 0)
+	lateTime := time.Unix(2000, 0)
+
+	tests := []struct {
+		name          string
+		filter        *dbaccess.AddressTransactionsFilter
+		expectedValid bool
+	}{
+		{"empty", &dbaccess.AddressTransactionsFilter{}, true},
+		{"incoming", &dbaccess.AddressTransactionsFilter{Direction: dbaccess.TransactionDirectionIncoming}, true},
+		{"outgoing", &dbaccess.AddressTransactionsFilter{Direction: dbaccess.TransactionDirectionOutgoing}, true},
+		{"short direction", &dbaccess.AddressTransactionsFilter{Direction: "in"}, false},
+		{"blue score range", &dbaccess.AddressTransactionsFilter{FromBlueScore: &lowBlueScore, ToBlueScore: &highBlueScore}, true},
+		{"single blue score", &dbaccess.AddressTransactionsFilter{FromBlueScore: &lowBlueScore, ToBlueScore: &lowBlueScore}, true},
+		{"reversed blue score range", &dbaccess.AddressTransactionsFilter{FromBlueScore: &highBlueScore, ToBlueScore: &lowBlueScore}, false},
+		{"time range", &dbaccess.AddressTransactionsFilter{FromTime: &earlyTime, ToTime: &lateTime}, true},
+		{"reversed time range", &dbaccess.AddressTransactionsFilter{FromTime: &lateTime, ToTime: &earlyTime}, false},
+	}
+	for _, test := range tests {
+		err := validateAddressTransactionsFilter(test.filter)
+		if (err == nil) != test.expectedValid {
+			t.Errorf("%s: expected valid to be %t but got error: %v", test.name, test.expectedValid, err)
+		}
+	}
+}
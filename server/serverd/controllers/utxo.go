@@ -1,27 +1,34 @@
 package controllers
 
 import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
 	"github.com/someone235/katnip/server/apimodels"
 	"github.com/someone235/katnip/server/database"
 	"github.com/someone235/katnip/server/dbaccess"
 	"github.com/someone235/katnip/server/dbmodels"
+	"github.com/someone235/katnip/server/httpserverutils"
 	"github.com/someone235/katnip/server/serverd/config"
 )
 
+const maxGetUTXOsLimit = 1000
+
 // GetUTXOsByAddressHandler searches for all UTXOs that belong to a certain address.
-func GetUTXOsByAddressHandler(address string) (interface{}, error) {
+func GetUTXOsByAddressHandler(ctx context.Context, address string) (interface{}, error) {
 	if err := validateAddress(address); err != nil {
 		return nil, err
 	}
 
-	transactionOutputs, err := dbaccess.UTXOsByAddress(database.NoTx(), address,
+	transactionOutputs, err := dbaccess.UTXOsByAddress(database.NoTxWithContext(ctx), address,
 		dbmodels.TransactionOutputFieldNames.TransactionAcceptingBlock,
 		dbmodels.TransactionOutputFieldNames.TransactionSubnetwork)
 	if err != nil {
 		return nil, err
 	}
 
-	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTxWithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -36,3 +43,85 @@ func GetUTXOsByAddressHandler(address string) (interface{}, error) {
 	}
 	return UTXOsResponses, nil
 }
+
+// PostUTXOsByAddressesHandler returns a page of the UTXOs that belong to any of
+// the addresses in the given request body, grouped by address, alongside cursors
+// to the pages before and after it. The cursors are only valid for the same
+// addresses. An empty cursor fetches the first page.
+func PostUTXOsByAddressesHandler(ctx context.Context, requestBody []byte, cursorString string, limit int64) (interface{}, error) {
+	if limit > maxGetUTXOsLimit || limit < 1 {
+		return nil, httpserverutils.NewHandlerError(http.StatusBadRequest,
+			errors.Errorf("limit higher than %d or lower than 1 was requested", maxGetUTXOsLimit))
+	}
+
+	addresses, err := parseAddressesRequest(requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := decodePageCursor(cursorString)
+	if err != nil {
+		return nil, err
+	}
+
+	queryOrder, position := pageQuery(dbaccess.OrderAscending, cursor)
+	transactionOutputs, err := dbaccess.UTXOsByAddressesAfterCursor(database.NoTxWithContext(ctx), addresses, queryOrder, position,
+		uint64(limit)+1,
+		dbmodels.TransactionOutputFieldNames.Address,
+		dbmodels.TransactionOutputFieldNames.TransactionAcceptingBlock,
+		dbmodels.TransactionOutputFieldNames.TransactionSubnetwork)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(transactionOutputs) > int(limit)
+	if hasMore {
+		transactionOutputs = transactionOutputs[:limit]
+	}
+	if pageIsReversed(cursor) {
+		for i, j := 0, len(transactionOutputs)-1; i < j; i, j = i+1, j-1 {
+			transactionOutputs[i], transactionOutputs[j] = transactionOutputs[j], transactionOutputs[i]
+		}
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTxWithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	activeNetParams := config.ActiveConfig().NetParams()
+
+	addressesToUTXOs := make(map[string][]*apimodels.TransactionOutputResponse, len(addresses))
+	positions := make([]*dbaccess.Cursor, len(transactionOutputs))
+	for i, transactionOutput := range transactionOutputs {
+		utxoResponse, err := apimodels.ConvertTransactionOutputModelToTransactionOutputResponse(transactionOutput,
+			selectedTipBlueScore, activeNetParams, false)
+		if err != nil {
+			return nil, err
+		}
+		address := transactionOutput.Address.Address
+		addressesToUTXOs[address] = append(addressesToUTXOs[address], utxoResponse)
+		positions[i] = &dbaccess.Cursor{ID: transactionOutput.ID}
+	}
+
+	addressUTXOsResponses := make([]*apimodels.AddressUTXOsResponse, len(addresses))
+	for i, address := range addresses {
+		utxos, ok := addressesToUTXOs[address]
+		if !ok {
+			utxos = []*apimodels.TransactionOutputResponse{}
+		}
+		addressUTXOsResponses[i] = &apimodels.AddressUTXOsResponse{
+			Address: address,
+			UTXOs:   utxos,
+		}
+	}
+
+	next, prev, err := pageCursors(dbaccess.OrderAscending, cursor, positions, hasMore)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apimodels.PaginatedResponse{
+		Items: addressUTXOsResponses,
+		Next:  next,
+		Prev:  prev,
+	}, nil
+}
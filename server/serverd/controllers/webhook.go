@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/dbmodels"
+	"github.com/someone235/katnip/server/httpserverutils"
+	"github.com/someone235/katnip/server/serverd/webhooks"
+)
+
+const webhookSecretSize = 32
+
+// PostWebhookSubscriptionHandler subscribes a webhook to the events of
+// an address or a transaction, and returns the subscription alongside the
+// secret that is used to sign its deliveries.
+func PostWebhookSubscriptionHandler(ctx context.Context, requestBody []byte) (interface{}, error) {
+	request := &apimodels.WebhookSubscriptionRequest{}
+	err := json.Unmarshal(requestBody, request)
+	if err != nil {
+		return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
+			errors.Wrap(err, "error unmarshalling request body"),
+			"The request body is not json-formatted")
+	}
+
+	err = validateWebhookSubscriptionRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	secretBytes := make([]byte, webhookSecretSize)
+	_, err = rand.Read(secretBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &dbmodels.WebhookSubscription{
+		URL:           request.URL,
+		Secret:        hex.EncodeToString(secretBytes),
+		Address:       request.Address,
+		TransactionID: request.TransactionID,
+		Confirmations: request.Confirmations,
+		CreatedAt:     time.Now(),
+	}
+	err = dbaccess.InsertWebhookSubscription(database.NoTxWithContext(ctx), subscription)
+	if err != nil {
+		return nil, err
+	}
+
+	response := apimodels.ConvertWebhookSubscriptionModelToWebhookSubscriptionResponse(subscription)
+	response.Secret = subscription.Secret
+	return response, nil
+}
+
+// GetWebhookSubscriptionHandler returns the webhook subscription with the given ID
+func GetWebhookSubscriptionHandler(ctx context.Context, webhookID uint64, secret string) (interface{}, error) {
+	subscription, err := authorizedWebhookSubscription(ctx, webhookID, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return apimodels.ConvertWebhookSubscriptionModelToWebhookSubscriptionResponse(subscription), nil
+}
+
+// DeleteWebhookSubscriptionHandler deletes the webhook subscription with the given ID
+// and cancels all its pending deliveries
+func DeleteWebhookSubscriptionHandler(ctx context.Context, webhookID uint64, secret string) (interface{}, error) {
+	subscription, err := authorizedWebhookSubscription(ctx, webhookID, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dbaccess.DeleteWebhookSubscription(database.NoTxWithContext(ctx), subscription.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return apimodels.ConvertWebhookSubscriptionModelToWebhookSubscriptionResponse(subscription), nil
+}
+
+func authorizedWebhookSubscription(ctx context.Context, webhookID uint64, secret string) (*dbmodels.WebhookSubscription, error) {
+	subscription, err := dbaccess.WebhookSubscriptionByID(database.NoTxWithContext(ctx), webhookID)
+	if err != nil {
+		return nil, err
+	}
+	// Unknown subscriptions and wrong secrets are reported the same way,
+	// so that subscription IDs can't be enumerated
+	if subscription == nil || !hmac.Equal([]byte(subscription.Secret), []byte(secret)) {
+		return nil, httpserverutils.NewHandlerError(http.StatusNotFound,
+			errors.New("no webhook subscription with the given ID and secret was found"))
+	}
+	return subscription, nil
+}
+
+func validateWebhookSubscriptionRequest(ctx context.Context, request *apimodels.WebhookSubscriptionRequest) error {
+	webhookURL, err := url.Parse(request.URL)
+	if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || webhookURL.Hostname() == "" {
+		return httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.New("the given url is not a valid http or https url"))
+	}
+	err = webhooks.ValidateDestination(ctx, webhookURL.Hostname())
+	if err != nil {
+		return httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity, err,
+			"The host of the given url can't be resolved, or resolves to a private address")
+	}
+
+	if request.Address == nil && request.TransactionID == nil {
+		return httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.New("either an address or a transaction ID must be given"))
+	}
+	if request.Address != nil {
+		if err := validateAddress(*request.Address); err != nil {
+			return err
+		}
+	}
+	if request.TransactionID != nil {
+		if bytes, err := hex.DecodeString(*request.TransactionID); err != nil || len(bytes) != externalapi.DomainHashSize {
+			return httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+				errors.Errorf("The given txid is not a hex-encoded %d-byte hash", externalapi.DomainHashSize))
+		}
+	}
+	if request.Confirmations != nil && *request.Confirmations == 0 {
+		return httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.New("confirmations must be at least 1"))
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package graphql
+
+import (
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+type addressResolver struct {
+	state   *requestState
+	address *dbmodels.Address
+}
+
+func (r *addressResolver) Address() string {
+	return r.address.Address
+}
+
+func (r *addressResolver) TransactionCount() (Uint64, error) {
+	count, err := dbaccess.TransactionsByAddressCount(database.NoTx(), r.address.Address)
+	if err != nil {
+		return 0, internalError(err)
+	}
+	return Uint64(count), nil
+}
+
+func (r *addressResolver) Transactions(args struct {
+	First int32
+	Skip  int32
+	Order string
+}) ([]*transactionResolver, error) {
+
+	err := validateListArgs(args.First, args.Skip)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := dbaccess.TransactionsByAddress(database.NoTx(), r.address.Address, nil,
+		dbaccess.Order(args.Order), uint64(args.Skip), uint64(args.First))
+	if err != nil {
+		return nil, internalError(err)
+	}
+	return newTransactionResolvers(r.state, transactions)
+}
+
+func (r *addressResolver) UTXOs(args struct{ First int32 }) ([]*outputResolver, error) {
+	err := validateListArgs(args.First, 0)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := dbaccess.UTXOsByAddressesAfterCursor(database.NoTx(), []string{r.address.Address},
+		dbaccess.OrderAscending, nil, uint64(args.First))
+	if err != nil {
+		return nil, internalError(err)
+	}
+	return newOutputResolvers(r.state, outputs)
+}
+
+type subnetworkResolver struct {
+	subnetwork *dbmodels.Subnetwork
+}
+
+func (r *subnetworkResolver) ID() string {
+	return r.subnetwork.SubnetworkID
+}
+
+func (r *subnetworkResolver) GasLimit() *Uint64 {
+	if r.subnetwork.GasLimit == nil {
+		return nil
+	}
+	gasLimit := Uint64(*r.subnetwork.GasLimit)
+	return &gasLimit
+}
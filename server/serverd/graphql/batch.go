@@ -0,0 +1,157 @@
+package graphql
+
+import (
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+func loadBlocks(ids []uint64) (map[uint64]interface{}, error) {
+	blocks, err := dbaccess.BlocksByIDs(database.NoTx(), ids)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[uint64]interface{}, len(blocks))
+	for _, block := range blocks {
+		values[block.ID] = block
+	}
+	return values, nil
+}
+
+func loadBlockParentIDs(blockIDs []uint64) (map[uint64]interface{}, error) {
+	parentBlocks, err := dbaccess.ParentBlocksByBlockIDs(database.NoTx(), blockIDs)
+	if err != nil {
+		return nil, err
+	}
+	parentIDs := make(map[uint64][]uint64, len(blockIDs))
+	for _, parentBlock := range parentBlocks {
+		parentIDs[parentBlock.BlockID] = append(parentIDs[parentBlock.BlockID], parentBlock.ParentBlockID)
+	}
+	return idsValues(parentIDs), nil
+}
+
+func loadBlockChildIDs(blockIDs []uint64) (map[uint64]interface{}, error) {
+	parentBlocks, err := dbaccess.ParentBlocksByParentBlockIDs(database.NoTx(), blockIDs)
+	if err != nil {
+		return nil, err
+	}
+	childIDs := make(map[uint64][]uint64, len(blockIDs))
+	for _, parentBlock := range parentBlocks {
+		childIDs[parentBlock.ParentBlockID] = append(childIDs[parentBlock.ParentBlockID], parentBlock.BlockID)
+	}
+	return idsValues(childIDs), nil
+}
+
+func loadBlockTransactionIDs(blockIDs []uint64) (map[uint64]interface{}, error) {
+	transactionBlocks, err := dbaccess.TransactionBlocksByBlockIDs(database.NoTx(), blockIDs)
+	if err != nil {
+		return nil, err
+	}
+	transactionIDs := make(map[uint64][]uint64, len(blockIDs))
+	for _, transactionBlock := range transactionBlocks {
+		transactionIDs[transactionBlock.BlockID] = append(transactionIDs[transactionBlock.BlockID],
+			transactionBlock.TransactionID)
+	}
+	return idsValues(transactionIDs), nil
+}
+
+func loadTransactions(ids []uint64) (map[uint64]interface{}, error) {
+	transactions, err := dbaccess.TransactionsByDatabaseIDs(database.NoTx(), ids)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[uint64]interface{}, len(transactions))
+	for _, transaction := range transactions {
+		values[transaction.ID] = transaction
+	}
+	return values, nil
+}
+
+func loadTransactionBlockIDs(transactionIDs []uint64) (map[uint64]interface{}, error) {
+	transactionBlocks, err := dbaccess.TransactionBlocksByTransactionIDs(database.NoTx(), transactionIDs)
+	if err != nil {
+		return nil, err
+	}
+	blockIDs := make(map[uint64][]uint64, len(transactionIDs))
+	for _, transactionBlock := range transactionBlocks {
+		blockIDs[transactionBlock.TransactionID] = append(blockIDs[transactionBlock.TransactionID],
+			transactionBlock.BlockID)
+	}
+	return idsValues(blockIDs), nil
+}
+
+func loadTransactionInputs(transactionIDs []uint64) (map[uint64]interface{}, error) {
+	transactionInputs, err := dbaccess.TransactionInputsByTransactionIDs(database.NoTx(), transactionIDs)
+	if err != nil {
+		return nil, err
+	}
+	inputs := make(map[uint64][]*dbmodels.TransactionInput, len(transactionIDs))
+	for _, transactionInput := range transactionInputs {
+		inputs[transactionInput.TransactionID] = append(inputs[transactionInput.TransactionID], transactionInput)
+	}
+	values := make(map[uint64]interface{}, len(inputs))
+	for transactionID, transactionInputs := range inputs {
+		values[transactionID] = transactionInputs
+	}
+	return values, nil
+}
+
+func loadTransactionOutputs(transactionIDs []uint64) (map[uint64]interface{}, error) {
+	transactionOutputs, err := dbaccess.TransactionOutputsByTransactionIDs(database.NoTx(), transactionIDs)
+	if err != nil {
+		return nil, err
+	}
+	outputs := make(map[uint64][]*dbmodels.TransactionOutput, len(transactionIDs))
+	for _, transactionOutput := range transactionOutputs {
+		outputs[transactionOutput.TransactionID] = append(outputs[transactionOutput.TransactionID], transactionOutput)
+	}
+	values := make(map[uint64]interface{}, len(outputs))
+	for transactionID, transactionOutputs := range outputs {
+		values[transactionID] = transactionOutputs
+	}
+	return values, nil
+}
+
+func loadOutputs(ids []uint64) (map[uint64]interface{}, error) {
+	transactionOutputs, err := dbaccess.TransactionOutputsByIDs(database.NoTx(), ids)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[uint64]interface{}, len(transactionOutputs))
+	for _, transactionOutput := range transactionOutputs {
+		values[transactionOutput.ID] = transactionOutput
+	}
+	return values, nil
+}
+
+func loadAddresses(ids []uint64) (map[uint64]interface{}, error) {
+	addresses, err := dbaccess.AddressesByIDs(database.NoTx(), ids)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[uint64]interface{}, len(addresses))
+	for _, address := range addresses {
+		values[address.ID] = address
+	}
+	return values, nil
+}
+
+func loadSubnetworks(ids []uint64) (map[uint64]interface{}, error) {
+	subnetworks, err := dbaccess.SubnetworksByDatabaseIDs(database.NoTx(), ids)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[uint64]interface{}, len(subnetworks))
+	for _, subnetwork := range subnetworks {
+		values[subnetwork.ID] = subnetwork
+	}
+	return values, nil
+}
+
+func idsValues(ids map[uint64][]uint64) map[uint64]interface{} {
+	values := make(map[uint64]interface{}, len(ids))
+	for key, keyIDs := range ids {
+		values[key] = keyIDs
+	}
+	return values
+}
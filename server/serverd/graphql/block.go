@@ -0,0 +1,143 @@
+package graphql
+
+import (
+	"github.com/someone235/katnip/server/dbmodels"
+	"github.com/someone235/katnip/server/serializer"
+)
+
+type blockResolver struct {
+	state *requestState
+	block *dbmodels.Block
+}
+
+func newBlockResolver(state *requestState, block *dbmodels.Block) (*blockResolver, error) {
+	if block == nil {
+		return nil, nil
+	}
+	err := state.charge(1)
+	if err != nil {
+		return nil, err
+	}
+	return &blockResolver{state: state, block: block}, nil
+}
+
+func newBlockResolvers(state *requestState, blocks []*dbmodels.Block) ([]*blockResolver, error) {
+	err := state.charge(len(blocks))
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*blockResolver, len(blocks))
+	for i, block := range blocks {
+		resolvers[i] = &blockResolver{state: state, block: block}
+	}
+	return resolvers, nil
+}
+
+func (r *blockResolver) Hash() string {
+	return r.block.BlockHash
+}
+
+func (r *blockResolver) Version() int32 {
+	return int32(r.block.Version)
+}
+
+func (r *blockResolver) HashMerkleRoot() string {
+	return r.block.HashMerkleRoot
+}
+
+func (r *blockResolver) AcceptedIDMerkleRoot() string {
+	return r.block.AcceptedIDMerkleRoot
+}
+
+func (r *blockResolver) UTXOCommitment() string {
+	return r.block.UTXOCommitment
+}
+
+func (r *blockResolver) Timestamp() Uint64 {
+	return Uint64(r.block.Timestamp.Unix())
+}
+
+func (r *blockResolver) Bits() Uint64 {
+	return Uint64(r.block.Bits)
+}
+
+func (r *blockResolver) Nonce() Uint64 {
+	return Uint64(serializer.BytesToUint64(r.block.Nonce))
+}
+
+func (r *blockResolver) BlueScore() Uint64 {
+	return Uint64(r.block.BlueScore)
+}
+
+func (r *blockResolver) IsChainBlock() bool {
+	return r.block.IsChainBlock
+}
+
+func (r *blockResolver) TransactionCount() int32 {
+	return int32(r.block.TransactionCount)
+}
+
+func (r *blockResolver) Difficulty() float64 {
+	return r.block.Difficulty
+}
+
+func (r *blockResolver) AcceptingBlock() (*blockResolver, error) {
+	if r.block.AcceptingBlockID == nil {
+		return nil, nil
+	}
+	acceptingBlock, err := r.state.loadBlock(*r.block.AcceptingBlockID)
+	if err != nil {
+		return nil, err
+	}
+	return newBlockResolver(r.state, acceptingBlock)
+}
+
+func (r *blockResolver) Parents() ([]*blockResolver, error) {
+	return r.relatedBlocks(r.state.blockParentIDs)
+}
+
+func (r *blockResolver) Children() ([]*blockResolver, error) {
+	return r.relatedBlocks(r.state.blockChildIDs)
+}
+
+func (r *blockResolver) relatedBlocks(idsLoader *loader) ([]*blockResolver, error) {
+	blockIDs, err := r.state.loadIDs(idsLoader, r.block.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Charge before loading, so that a query that's
+	// too large is rejected without hitting the database
+	err = r.state.charge(len(blockIDs))
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := r.state.loadBlocks(blockIDs)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*blockResolver, len(blocks))
+	for i, block := range blocks {
+		resolvers[i] = &blockResolver{state: r.state, block: block}
+	}
+	return resolvers, nil
+}
+
+func (r *blockResolver) Transactions() ([]*transactionResolver, error) {
+	transactionIDs, err := r.state.loadIDs(r.state.blockTransactionIDs, r.block.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = r.state.charge(len(transactionIDs))
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := r.state.loadTransactions(transactionIDs)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*transactionResolver, len(transactions))
+	for i, transaction := range transactions {
+		resolvers[i] = &transactionResolver{state: r.state, transaction: transaction}
+	}
+	return resolvers, nil
+}
@@ -0,0 +1,52 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/httpserverutils"
+)
+
+// queryRequest is a GraphQL request, as sent in the body of
+// a POST request or in the query parameters of a GET request
+type queryRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// HandleQuery executes the GraphQL query of the given request. POST requests
+// carry the query in a json body, and GET requests carry it in the `query`,
+// `operationName` and `variables` query parameters.
+func HandleQuery(ctx context.Context, method string, queryParams map[string]string,
+	requestBody []byte) (interface{}, error) {
+
+	request := &queryRequest{}
+	if method == http.MethodPost {
+		err := json.Unmarshal(requestBody, request)
+		if err != nil {
+			return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
+				errors.Wrap(err, "error unmarshalling request body"),
+				"The request body is not json-formatted")
+		}
+	} else {
+		request.Query = queryParams["query"]
+		request.OperationName = queryParams["operationName"]
+		if variables, ok := queryParams["variables"]; ok {
+			err := json.Unmarshal([]byte(variables), &request.Variables)
+			if err != nil {
+				return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(http.StatusUnprocessableEntity,
+					errors.Wrap(err, "error unmarshalling the variables query parameter"),
+					"The variables query parameter is not json-formatted")
+			}
+		}
+	}
+	if request.Query == "" {
+		return nil, httpserverutils.NewHandlerError(http.StatusUnprocessableEntity,
+			errors.New("query is required"))
+	}
+
+	return schema.Exec(withRequestState(ctx), request.Query, request.OperationName, request.Variables), nil
+}
@@ -0,0 +1,138 @@
+package graphql
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// loaderWait is how long a loader waits for more keys
+	// to be requested before it loads a batch
+	loaderWait = 2 * time.Millisecond
+
+	// loaderMaxBatchSize is the number of keys above which a batch
+	// is loaded right away, without waiting for more keys
+	loaderMaxBatchSize = 1000
+)
+
+// batchLoadFunc loads the values of all the given keys at once.
+// Keys that have no value may be omitted from the returned map.
+type batchLoadFunc func(keys []uint64) (map[uint64]interface{}, error)
+
+// loader batches the loads of values that are requested by resolvers
+// running concurrently into a single call to its batchLoadFunc, and
+// caches the loaded values for the rest of the request.
+type loader struct {
+	batchLoad batchLoadFunc
+
+	// wait is how long the loader waits for more
+	// keys to be requested before it loads a batch
+	wait time.Duration
+
+	lock    sync.Mutex
+	results map[uint64]*loaderResult
+	pending *loaderBatch
+}
+
+type loaderResult struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+type loaderBatch struct {
+	keys         []uint64
+	results      []*loaderResult
+	dispatchOnce sync.Once
+}
+
+func newLoader(batchLoad batchLoadFunc) *loader {
+	return &loader{
+		batchLoad: batchLoad,
+		wait:      loaderWait,
+		results:   make(map[uint64]*loaderResult),
+	}
+}
+
+// load returns the value of the given key, or nil if it has none
+func (l *loader) load(key uint64) (interface{}, error) {
+	l.lock.Lock()
+	result, ok := l.results[key]
+	if !ok {
+		result = &loaderResult{done: make(chan struct{})}
+		l.results[key] = result
+		l.enqueue(key, result)
+	}
+	l.lock.Unlock()
+
+	<-result.done
+	return result.value, result.err
+}
+
+// loadMany returns the values of the given keys, skipping keys that have no value
+func (l *loader) loadMany(keys []uint64) ([]interface{}, error) {
+	l.lock.Lock()
+	results := make([]*loaderResult, len(keys))
+	for i, key := range keys {
+		result, ok := l.results[key]
+		if !ok {
+			result = &loaderResult{done: make(chan struct{})}
+			l.results[key] = result
+			l.enqueue(key, result)
+		}
+		results[i] = result
+	}
+	l.lock.Unlock()
+
+	values := make([]interface{}, 0, len(keys))
+	for _, result := range results {
+		<-result.done
+		if result.err != nil {
+			return nil, result.err
+		}
+		if result.value != nil {
+			values = append(values, result.value)
+		}
+	}
+	return values, nil
+}
+
+// enqueue must be called while holding l.lock
+func (l *loader) enqueue(key uint64, result *loaderResult) {
+	if l.pending == nil {
+		batch := &loaderBatch{}
+		l.pending = batch
+		time.AfterFunc(l.wait, func() { l.dispatch(batch) })
+	}
+	batch := l.pending
+	batch.keys = append(batch.keys, key)
+	batch.results = append(batch.results, result)
+	if len(batch.keys) >= loaderMaxBatchSize {
+		l.pending = nil
+		spawn("graphql-loader-dispatch", func() { l.dispatch(batch) })
+	}
+}
+
+func (l *loader) dispatch(batch *loaderBatch) {
+	batch.dispatchOnce.Do(func() {
+		l.lock.Lock()
+		if l.pending == batch {
+			l.pending = nil
+		}
+		l.lock.Unlock()
+
+		values, err := l.batchLoad(batch.keys)
+		if err != nil {
+			log.Errorf("Error loading a batch of %d keys: %s", len(batch.keys), err)
+			err = errInternal
+		}
+		for i, key := range batch.keys {
+			result := batch.results[i]
+			result.err = err
+			if err == nil {
+				result.value = values[key]
+			}
+			close(result.done)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package graphql
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoader(t *testing.T) {
+	var batchesLock sync.Mutex
+	var batches [][]uint64
+	l := newLoader(func(keys []uint64) (map[uint64]interface{}, error) {
+		batchesLock.Lock()
+		batches = append(batches, keys)
+		batchesLock.Unlock()
+
+		values := make(map[uint64]interface{}, len(keys))
+		for _, key := range keys {
+			if key%2 == 0 {
+				values[key] = key * 10
+			}
+		}
+		return values, nil
+	})
+	// The first batch is dispatched by the test once all its keys are
+	// enqueued, so that it doesn't depend on how the goroutines are scheduled
+	l.wait = time.Hour
+
+	var wg sync.WaitGroup
+	for i := uint64(0); i < 10; i++ {
+		key := i % 5
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := l.load(key)
+			if err != nil {
+				t.Errorf("load: %s", err)
+				return
+			}
+			if key%2 == 0 && value != key*10 {
+				t.Errorf("key %d: expected value %d but got %v", key, key*10, value)
+			}
+			if key%2 == 1 && value != nil {
+				t.Errorf("key %d: expected no value but got %v", key, value)
+			}
+		}()
+	}
+	firstBatch := waitForPendingKeys(t, l, 5)
+	l.dispatch(firstBatch)
+	wg.Wait()
+
+	if len(batches) != 1 || len(batches[0]) != 5 {
+		t.Fatalf("expected a single batch of 5 keys but got %v", batches)
+	}
+
+	l.wait = loaderWait
+	values, err := l.loadMany([]uint64{0, 1, 2, 6})
+	if err != nil {
+		t.Fatalf("loadMany: %s", err)
+	}
+	if len(values) != 3 || values[0] != uint64(0) || values[1] != uint64(20) || values[2] != uint64(60) {
+		t.Errorf("unexpected values %v", values)
+	}
+	if len(batches) != 2 || len(batches[1]) != 1 || batches[1][0] != 6 {
+		t.Errorf("expected only the uncached key to be loaded, but got batches %v", batches)
+	}
+}
+
+// waitForPendingKeys waits until the pending batch of the given
+// loader has the given number of keys, and returns it
+func waitForPendingKeys(t *testing.T, l *loader, keyCount int) *loaderBatch {
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		l.lock.Lock()
+		batch := l.pending
+		isFull := batch != nil && len(batch.keys) == keyCount
+		l.lock.Unlock()
+		if isFull {
+			return batch
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d keys to be enqueued", keyCount)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,11 @@
+package graphql
+
+import (
+	"github.com/kaspanet/kaspad/util/panics"
+	"github.com/someone235/katnip/server/logger"
+)
+
+var (
+	log   = logger.Logger("GRQL")
+	spawn = panics.GoroutineWrapperFunc(log)
+)
@@ -0,0 +1,156 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/kaspanet/kaspad/util"
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/dbmodels"
+	"github.com/someone235/katnip/server/serverd/config"
+	"github.com/someone235/katnip/server/serverd/stream"
+)
+
+type rootResolver struct{}
+
+func (*rootResolver) Block(ctx context.Context, args struct{ Hash string }) (*blockResolver, error) {
+	block, err := dbaccess.BlockByHash(database.NoTx(), args.Hash)
+	if err != nil {
+		return nil, internalError(err)
+	}
+	return newBlockResolver(requestStateFromContext(ctx), block)
+}
+
+func (*rootResolver) Blocks(ctx context.Context, args struct {
+	First int32
+	Skip  int32
+	Order string
+}) ([]*blockResolver, error) {
+
+	err := validateListArgs(args.First, args.Skip)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := dbaccess.Blocks(database.NoTx(), dbaccess.Order(args.Order), uint64(args.Skip), uint64(args.First))
+	if err != nil {
+		return nil, internalError(err)
+	}
+	return newBlockResolvers(requestStateFromContext(ctx), blocks)
+}
+
+func (*rootResolver) SelectedTip(ctx context.Context) (*blockResolver, error) {
+	block, err := dbaccess.SelectedTip(database.NoTx())
+	if err != nil {
+		return nil, internalError(err)
+	}
+	return newBlockResolver(requestStateFromContext(ctx), block)
+}
+
+func (*rootResolver) Transaction(ctx context.Context, args struct {
+	ID   *string
+	Hash *string
+}) (*transactionResolver, error) {
+
+	var transaction *dbmodels.Transaction
+	var err error
+	switch {
+	case args.ID != nil && args.Hash == nil:
+		transaction, err = dbaccess.TransactionByID(database.NoTx(), *args.ID)
+	case args.Hash != nil && args.ID == nil:
+		transaction, err = dbaccess.TransactionByHash(database.NoTx(), *args.Hash)
+	default:
+		return nil, errors.New("exactly one of id and hash is required")
+	}
+	if err != nil {
+		return nil, internalError(err)
+	}
+	return newTransactionResolver(requestStateFromContext(ctx), transaction)
+}
+
+func (*rootResolver) Address(ctx context.Context, args struct{ Address string }) (*addressResolver, error) {
+	_, err := util.DecodeAddress(args.Address, config.ActiveConfig().ActiveNetParams.Prefix)
+	if err != nil {
+		return nil, errors.New("the given address is not a well-formatted P2PKH or P2SH address")
+	}
+
+	state := requestStateFromContext(ctx)
+	err = state.charge(1)
+	if err != nil {
+		return nil, err
+	}
+	// An address that never appeared in a transaction is still a valid
+	// address, so it's resolved even if it's not in the database
+	return &addressResolver{state: state, address: &dbmodels.Address{Address: args.Address}}, nil
+}
+
+// BlockAdded streams every block that's added to the DAG. The nested
+// fields of every event are resolved with a fresh request state, so that
+// they're up to date and the complexity limit applies to each event alone.
+func (*rootResolver) BlockAdded(ctx context.Context) (<-chan *blockResolver, error) {
+	subscription, err := stream.Subscribe(&stream.Filter{
+		Topics: map[string]struct{}{stream.BlocksTopic: {}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make(chan *blockResolver)
+	spawn("graphql-BlockAdded", func() {
+		defer close(resolvers)
+		defer subscription.Unsubscribe()
+		for {
+			select {
+			case event := <-subscription.Events():
+				resolver, err := blockAddedResolver(event)
+				if err != nil {
+					log.Errorf("Error resolving a blockAdded event: %s", err)
+					continue
+				}
+				if resolver == nil {
+					continue
+				}
+				select {
+				case resolvers <- resolver:
+				case <-ctx.Done():
+					return
+				}
+			case <-subscription.Done():
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+	return resolvers, nil
+}
+
+func blockAddedResolver(event *stream.Event) (*blockResolver, error) {
+	blockResponse, ok := event.Data.(*apimodels.BlockResponse)
+	if !ok {
+		return nil, errors.Errorf("unexpected data of type %T in a %s event", event.Data, event.Topic)
+	}
+	block, err := dbaccess.BlockByHash(database.NoTx(), blockResponse.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	return newBlockResolver(newRequestState(), block)
+}
+
+func validateListArgs(first int32, skip int32) error {
+	if first < 1 || first > maxListSize {
+		return errors.Errorf("first higher than %d or lower than 1 was requested", maxListSize)
+	}
+	if skip < 0 {
+		return errors.New("skip lower than 0 was requested")
+	}
+	return nil
+}
+
+// internalError logs the given error and hides
+// its details from the client
+func internalError(err error) error {
+	log.Errorf("Error resolving a GraphQL query: %s", err)
+	return errInternal
+}
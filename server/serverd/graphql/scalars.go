@@ -0,0 +1,44 @@
+package graphql
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Uint64 is the GraphQL Uint64 scalar. GraphQL integers are only 32-bit,
+// and JavaScript numbers lose precision above 2^53, so it's serialized
+// as a decimal string.
+type Uint64 uint64
+
+// ImplementsGraphQLType maps this type to the Uint64 scalar of the schema
+func (Uint64) ImplementsGraphQLType(name string) bool {
+	return name == "Uint64"
+}
+
+// UnmarshalGraphQL parses a Uint64 from a string or an integer argument
+func (u *Uint64) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case string:
+		value, err := strconv.ParseUint(input, 10, 64)
+		if err != nil {
+			return errors.Errorf("'%s' is not a valid Uint64", input)
+		}
+		*u = Uint64(value)
+		return nil
+	case int32:
+		if input < 0 {
+			return errors.Errorf("'%d' is not a valid Uint64", input)
+		}
+		*u = Uint64(input)
+		return nil
+	default:
+		return errors.Errorf("wrong type for Uint64: %T", input)
+	}
+}
+
+// MarshalJSON serializes the Uint64 as a decimal string
+func (u Uint64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatUint(uint64(u), 10))
+}
@@ -0,0 +1,117 @@
+package graphql
+
+import (
+	graphqlgo "github.com/graph-gophers/graphql-go"
+)
+
+const (
+	// maxQueryDepth is the maximum nesting depth of the fields of a query
+	maxQueryDepth = 10
+
+	// maxParallelism is the maximum number of resolvers
+	// that run concurrently for a single query
+	maxParallelism = 20
+
+	// maxListSize is the maximum number of items that
+	// may be requested from a single list field
+	maxListSize = 100
+)
+
+const schemaString = `
+schema {
+	query: Query
+	subscription: Subscription
+}
+
+# Uint64 is an unsigned 64-bit integer, serialized as a decimal string
+scalar Uint64
+
+enum Order {
+	ASC
+	DESC
+}
+
+type Query {
+	block(hash: String!): Block
+	blocks(first: Int = 25, skip: Int = 0, order: Order = DESC): [Block!]!
+	selectedTip: Block
+	transaction(id: String, hash: String): Transaction
+	address(address: String!): Address
+}
+
+type Subscription {
+	blockAdded: Block!
+}
+
+type Block {
+	hash: String!
+	version: Int!
+	hashMerkleRoot: String!
+	acceptedIdMerkleRoot: String!
+	utxoCommitment: String!
+	timestamp: Uint64!
+	bits: Uint64!
+	nonce: Uint64!
+	blueScore: Uint64!
+	isChainBlock: Boolean!
+	transactionCount: Int!
+	difficulty: Float!
+	acceptingBlock: Block
+	parents: [Block!]!
+	children: [Block!]!
+	transactions: [Transaction!]!
+}
+
+type Transaction {
+	id: String!
+	hash: String!
+	subnetwork: Subnetwork!
+	lockTime: Uint64!
+	gas: Uint64!
+	payload: String!
+	mass: Uint64!
+	version: Int!
+	fee: Uint64
+	acceptingBlock: Block
+	confirmations: Uint64!
+	blocks: [Block!]!
+	inputs: [Input!]!
+	outputs: [Output!]!
+}
+
+type Input {
+	transaction: Transaction!
+	index: Int!
+	previousTransactionId: String!
+	previousTransactionOutputIndex: Int!
+	previousOutput: Output
+	signatureScript: String!
+	sequence: Uint64!
+}
+
+type Output {
+	transaction: Transaction!
+	index: Int!
+	value: Uint64!
+	scriptPubKey: String!
+	isSpent: Boolean!
+	address: Address
+}
+
+type Address {
+	address: String!
+	transactionCount: Uint64!
+	transactions(first: Int = 25, skip: Int = 0, order: Order = DESC): [Transaction!]!
+	utxos(first: Int = 100): [Output!]!
+}
+
+type Subnetwork {
+	id: String!
+	gasLimit: Uint64
+}
+`
+
+var schema = graphqlgo.MustParseSchema(schemaString, &rootResolver{},
+	graphqlgo.MaxDepth(maxQueryDepth),
+	graphqlgo.MaxParallelism(maxParallelism),
+)
@@ -0,0 +1,185 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+// maxQueryComplexity is the maximum number of objects that a single
+// query, or a single event of a subscription, may resolve
+const maxQueryComplexity = 5000
+
+var (
+	errInternal      = errors.New("internal error")
+	errQueryTooLarge = errors.Errorf("the query resolves more than %d objects", maxQueryComplexity)
+)
+
+// requestState holds the loaders and the complexity budget
+// of a single query or a single event of a subscription
+type requestState struct {
+	complexity int64
+
+	blocks              *loader
+	blockParentIDs      *loader
+	blockChildIDs       *loader
+	blockTransactionIDs *loader
+	transactions        *loader
+	transactionBlockIDs *loader
+	transactionInputs   *loader
+	transactionOutputs  *loader
+	outputs             *loader
+	addresses           *loader
+	subnetworks         *loader
+
+	selectedTipBlueScoreOnce sync.Once
+	selectedTipBlueScore     uint64
+	selectedTipBlueScoreErr  error
+}
+
+func newRequestState() *requestState {
+	return &requestState{
+		blocks:              newLoader(loadBlocks),
+		blockParentIDs:      newLoader(loadBlockParentIDs),
+		blockChildIDs:       newLoader(loadBlockChildIDs),
+		blockTransactionIDs: newLoader(loadBlockTransactionIDs),
+		transactions:        newLoader(loadTransactions),
+		transactionBlockIDs: newLoader(loadTransactionBlockIDs),
+		transactionInputs:   newLoader(loadTransactionInputs),
+		transactionOutputs:  newLoader(loadTransactionOutputs),
+		outputs:             newLoader(loadOutputs),
+		addresses:           newLoader(loadAddresses),
+		subnetworks:         newLoader(loadSubnetworks),
+	}
+}
+
+type contextKey string
+
+const contextKeyRequestState contextKey = "GRAPHQL_REQUEST_STATE"
+
+func withRequestState(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKeyRequestState, newRequestState())
+}
+
+func requestStateFromContext(ctx context.Context) *requestState {
+	state, ok := ctx.Value(contextKeyRequestState).(*requestState)
+	if !ok {
+		panic("the context has no GraphQL request state")
+	}
+	return state
+}
+
+// charge adds the given number of resolved objects to the
+// complexity of the request, and fails once it's too large
+func (state *requestState) charge(objects int) error {
+	if atomic.AddInt64(&state.complexity, int64(objects)) > maxQueryComplexity {
+		return errQueryTooLarge
+	}
+	return nil
+}
+
+// getSelectedTipBlueScore returns the blue score of the selected
+// tip, which is fetched once for the whole request
+func (state *requestState) getSelectedTipBlueScore() (uint64, error) {
+	state.selectedTipBlueScoreOnce.Do(func() {
+		state.selectedTipBlueScore, state.selectedTipBlueScoreErr = dbaccess.SelectedTipBlueScore(database.NoTx())
+		if state.selectedTipBlueScoreErr != nil {
+			log.Errorf("Error getting the selected tip blue score: %s", state.selectedTipBlueScoreErr)
+			state.selectedTipBlueScoreErr = errInternal
+		}
+	})
+	return state.selectedTipBlueScore, state.selectedTipBlueScoreErr
+}
+
+func (state *requestState) loadBlock(id uint64) (*dbmodels.Block, error) {
+	value, err := state.blocks.load(id)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return value.(*dbmodels.Block), nil
+}
+
+func (state *requestState) loadBlocks(ids []uint64) ([]*dbmodels.Block, error) {
+	values, err := state.blocks.loadMany(ids)
+	if err != nil {
+		return nil, err
+	}
+	blocks := make([]*dbmodels.Block, len(values))
+	for i, value := range values {
+		blocks[i] = value.(*dbmodels.Block)
+	}
+	return blocks, nil
+}
+
+func (state *requestState) loadIDs(idsLoader *loader, id uint64) ([]uint64, error) {
+	value, err := idsLoader.load(id)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return value.([]uint64), nil
+}
+
+func (state *requestState) loadTransaction(id uint64) (*dbmodels.Transaction, error) {
+	value, err := state.transactions.load(id)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return value.(*dbmodels.Transaction), nil
+}
+
+func (state *requestState) loadTransactions(ids []uint64) ([]*dbmodels.Transaction, error) {
+	values, err := state.transactions.loadMany(ids)
+	if err != nil {
+		return nil, err
+	}
+	transactions := make([]*dbmodels.Transaction, len(values))
+	for i, value := range values {
+		transactions[i] = value.(*dbmodels.Transaction)
+	}
+	return transactions, nil
+}
+
+func (state *requestState) loadTransactionInputs(transactionID uint64) ([]*dbmodels.TransactionInput, error) {
+	value, err := state.transactionInputs.load(transactionID)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return value.([]*dbmodels.TransactionInput), nil
+}
+
+func (state *requestState) loadTransactionOutputs(transactionID uint64) ([]*dbmodels.TransactionOutput, error) {
+	value, err := state.transactionOutputs.load(transactionID)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return value.([]*dbmodels.TransactionOutput), nil
+}
+
+func (state *requestState) loadOutput(id uint64) (*dbmodels.TransactionOutput, error) {
+	value, err := state.outputs.load(id)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return value.(*dbmodels.TransactionOutput), nil
+}
+
+func (state *requestState) loadAddress(id uint64) (*dbmodels.Address, error) {
+	value, err := state.addresses.load(id)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return value.(*dbmodels.Address), nil
+}
+
+func (state *requestState) loadSubnetwork(id uint64) (*dbmodels.Subnetwork, error) {
+	value, err := state.subnetworks.load(id)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return value.(*dbmodels.Subnetwork), nil
+}
@@ -0,0 +1,273 @@
+package graphql
+
+import (
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/dbmodels"
+	"github.com/someone235/katnip/server/serializer"
+)
+
+type transactionResolver struct {
+	state       *requestState
+	transaction *dbmodels.Transaction
+}
+
+func newTransactionResolver(state *requestState, transaction *dbmodels.Transaction) (*transactionResolver, error) {
+	if transaction == nil {
+		return nil, nil
+	}
+	err := state.charge(1)
+	if err != nil {
+		return nil, err
+	}
+	return &transactionResolver{state: state, transaction: transaction}, nil
+}
+
+func newTransactionResolvers(state *requestState, transactions []*dbmodels.Transaction) ([]*transactionResolver, error) {
+	err := state.charge(len(transactions))
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*transactionResolver, len(transactions))
+	for i, transaction := range transactions {
+		resolvers[i] = &transactionResolver{state: state, transaction: transaction}
+	}
+	return resolvers, nil
+}
+
+func (r *transactionResolver) ID() string {
+	return r.transaction.TransactionID
+}
+
+func (r *transactionResolver) Hash() string {
+	return r.transaction.TransactionHash
+}
+
+func (r *transactionResolver) Subnetwork() (*subnetworkResolver, error) {
+	subnetwork, err := r.state.loadSubnetwork(r.transaction.SubnetworkID)
+	if err != nil {
+		return nil, err
+	}
+	if subnetwork == nil {
+		return nil, internalError(errors.Errorf("subnetwork %d of transaction %s does not exist",
+			r.transaction.SubnetworkID, r.transaction.TransactionID))
+	}
+	err = r.state.charge(1)
+	if err != nil {
+		return nil, err
+	}
+	return &subnetworkResolver{subnetwork: subnetwork}, nil
+}
+
+func (r *transactionResolver) LockTime() Uint64 {
+	return Uint64(serializer.BytesToUint64(r.transaction.LockTime))
+}
+
+func (r *transactionResolver) Gas() Uint64 {
+	return Uint64(r.transaction.Gas)
+}
+
+func (r *transactionResolver) Payload() string {
+	return hex.EncodeToString(r.transaction.Payload)
+}
+
+func (r *transactionResolver) Mass() Uint64 {
+	return Uint64(r.transaction.Mass)
+}
+
+func (r *transactionResolver) Version() int32 {
+	return int32(r.transaction.Version)
+}
+
+func (r *transactionResolver) Fee() *Uint64 {
+	if r.transaction.Fee == nil {
+		return nil
+	}
+	fee := Uint64(*r.transaction.Fee)
+	return &fee
+}
+
+func (r *transactionResolver) AcceptingBlock() (*blockResolver, error) {
+	if r.transaction.AcceptingBlockID == nil {
+		return nil, nil
+	}
+	acceptingBlock, err := r.state.loadBlock(*r.transaction.AcceptingBlockID)
+	if err != nil {
+		return nil, err
+	}
+	return newBlockResolver(r.state, acceptingBlock)
+}
+
+func (r *transactionResolver) Confirmations() (Uint64, error) {
+	if r.transaction.AcceptingBlockID == nil {
+		return 0, nil
+	}
+	acceptingBlock, err := r.state.loadBlock(*r.transaction.AcceptingBlockID)
+	if err != nil || acceptingBlock == nil {
+		return 0, err
+	}
+	selectedTipBlueScore, err := r.state.getSelectedTipBlueScore()
+	if err != nil {
+		return 0, err
+	}
+	if selectedTipBlueScore < acceptingBlock.BlueScore {
+		return 0, nil
+	}
+	return Uint64(selectedTipBlueScore - acceptingBlock.BlueScore + 1), nil
+}
+
+func (r *transactionResolver) Blocks() ([]*blockResolver, error) {
+	blockIDs, err := r.state.loadIDs(r.state.transactionBlockIDs, r.transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = r.state.charge(len(blockIDs))
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := r.state.loadBlocks(blockIDs)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*blockResolver, len(blocks))
+	for i, block := range blocks {
+		resolvers[i] = &blockResolver{state: r.state, block: block}
+	}
+	return resolvers, nil
+}
+
+func (r *transactionResolver) Inputs() ([]*inputResolver, error) {
+	inputs, err := r.state.loadTransactionInputs(r.transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = r.state.charge(len(inputs))
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*inputResolver, len(inputs))
+	for i, input := range inputs {
+		resolvers[i] = &inputResolver{state: r.state, input: input}
+	}
+	return resolvers, nil
+}
+
+func (r *transactionResolver) Outputs() ([]*outputResolver, error) {
+	outputs, err := r.state.loadTransactionOutputs(r.transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+	return newOutputResolvers(r.state, outputs)
+}
+
+type inputResolver struct {
+	state *requestState
+	input *dbmodels.TransactionInput
+}
+
+func (r *inputResolver) Transaction() (*transactionResolver, error) {
+	return loadTransactionResolver(r.state, r.input.TransactionID)
+}
+
+func (r *inputResolver) Index() int32 {
+	return int32(r.input.Index)
+}
+
+func (r *inputResolver) PreviousTransactionID() string {
+	return r.input.PreviousTransactionID
+}
+
+func (r *inputResolver) PreviousTransactionOutputIndex() int32 {
+	return int32(r.input.PreviousTransactionOutputIndex)
+}
+
+func (r *inputResolver) PreviousOutput() (*outputResolver, error) {
+	// The previous output of an input is unknown
+	// when it's not in the database
+	if r.input.PreviousTransactionOutputID == 0 {
+		return nil, nil
+	}
+	output, err := r.state.loadOutput(r.input.PreviousTransactionOutputID)
+	if err != nil || output == nil {
+		return nil, err
+	}
+	err = r.state.charge(1)
+	if err != nil {
+		return nil, err
+	}
+	return &outputResolver{state: r.state, output: output}, nil
+}
+
+func (r *inputResolver) SignatureScript() string {
+	return hex.EncodeToString(r.input.SignatureScript)
+}
+
+func (r *inputResolver) Sequence() Uint64 {
+	return Uint64(serializer.BytesToUint64(r.input.Sequence))
+}
+
+type outputResolver struct {
+	state  *requestState
+	output *dbmodels.TransactionOutput
+}
+
+func newOutputResolvers(state *requestState, outputs []*dbmodels.TransactionOutput) ([]*outputResolver, error) {
+	err := state.charge(len(outputs))
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*outputResolver, len(outputs))
+	for i, output := range outputs {
+		resolvers[i] = &outputResolver{state: state, output: output}
+	}
+	return resolvers, nil
+}
+
+func (r *outputResolver) Transaction() (*transactionResolver, error) {
+	return loadTransactionResolver(r.state, r.output.TransactionID)
+}
+
+func (r *outputResolver) Index() int32 {
+	return int32(r.output.Index)
+}
+
+func (r *outputResolver) Value() Uint64 {
+	return Uint64(r.output.Value)
+}
+
+func (r *outputResolver) ScriptPubKey() string {
+	return hex.EncodeToString(r.output.ScriptPubKey)
+}
+
+func (r *outputResolver) IsSpent() bool {
+	return r.output.IsSpent
+}
+
+func (r *outputResolver) Address() (*addressResolver, error) {
+	if r.output.AddressID == nil {
+		return nil, nil
+	}
+	address, err := r.state.loadAddress(*r.output.AddressID)
+	if err != nil || address == nil {
+		return nil, err
+	}
+	err = r.state.charge(1)
+	if err != nil {
+		return nil, err
+	}
+	return &addressResolver{state: r.state, address: address}, nil
+}
+
+// loadTransactionResolver resolves the transaction with the given database ID,
+// which is expected to exist since it's referenced by one of its inputs or outputs
+func loadTransactionResolver(state *requestState, id uint64) (*transactionResolver, error) {
+	transaction, err := state.loadTransaction(id)
+	if err != nil {
+		return nil, err
+	}
+	if transaction == nil {
+		return nil, internalError(errors.Errorf("transaction %d does not exist", id))
+	}
+	return newTransactionResolver(state, transaction)
+}
@@ -0,0 +1,230 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/httpserverutils"
+)
+
+const (
+	webSocketWriteTimeout = 10 * time.Second
+	webSocketPingInterval = 30 * time.Second
+	webSocketPongTimeout  = webSocketPingInterval + webSocketWriteTimeout
+	webSocketReadLimit    = 64 * 1024
+
+	// maxOperationsPerConnection is the maximum number of
+	// operations that may run at once on a single connection
+	maxOperationsPerConnection = 10
+)
+
+// webSocketSubprotocol is the subprotocol of subscriptions-transport-ws,
+// which is supported by most GraphQL clients
+const webSocketSubprotocol = "graphql-ws"
+
+// graphql-ws message types
+const (
+	messageTypeConnectionInit      = "connection_init"
+	messageTypeConnectionAck       = "connection_ack"
+	messageTypeConnectionError     = "connection_error"
+	messageTypeConnectionTerminate = "connection_terminate"
+	messageTypeStart               = "start"
+	messageTypeStop                = "stop"
+	messageTypeData                = "data"
+	messageTypeError               = "error"
+	messageTypeComplete            = "complete"
+)
+
+type operationMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+var upgrader = websocket.Upgrader{
+	// The REST API is open to all origins, and so are subscriptions
+	CheckOrigin:  func(_ *http.Request) bool { return true },
+	Subprotocols: []string{webSocketSubprotocol},
+}
+
+// webSocketConnection runs the GraphQL operations
+// that a client started over a single WebSocket
+type webSocketConnection struct {
+	connection *websocket.Conn
+	writeLock  sync.Mutex
+
+	operationsLock sync.Mutex
+	operations     map[string]context.CancelFunc
+}
+
+// ServeWebSocket runs GraphQL operations, and subscriptions in particular,
+// over a WebSocket using the graphql-ws protocol.
+func ServeWebSocket(w http.ResponseWriter, r *http.Request) {
+	ctx := httpserverutils.ToServerContext(r.Context())
+
+	// Upgrade replies to the client by itself on failure
+	connection, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		ctx.Warnf("Error upgrading to a WebSocket: %s", err)
+		return
+	}
+	defer connection.Close()
+
+	connectionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c := &webSocketConnection{
+		connection: connection,
+		operations: make(map[string]context.CancelFunc),
+	}
+	c.serve(connectionCtx)
+}
+
+func (c *webSocketConnection) serve(ctx context.Context) {
+	pingTickerDone := make(chan struct{})
+	defer close(pingTickerDone)
+	spawn("graphql-ServeWebSocket-ping", func() {
+		pingTicker := time.NewTicker(webSocketPingInterval)
+		defer pingTicker.Stop()
+		for {
+			select {
+			case <-pingTicker.C:
+				c.writeLock.Lock()
+				err := c.connection.WriteControl(websocket.PingMessage, nil, time.Now().Add(webSocketWriteTimeout))
+				c.writeLock.Unlock()
+				if err != nil {
+					return
+				}
+			case <-pingTickerDone:
+				return
+			}
+		}
+	})
+
+	c.connection.SetReadLimit(webSocketReadLimit)
+	_ = c.connection.SetReadDeadline(time.Now().Add(webSocketPongTimeout))
+	c.connection.SetPongHandler(func(string) error {
+		return c.connection.SetReadDeadline(time.Now().Add(webSocketPongTimeout))
+	})
+
+	isInitialized := false
+	for {
+		message := &operationMessage{}
+		err := c.connection.ReadJSON(message)
+		if err != nil {
+			return
+		}
+
+		switch message.Type {
+		case messageTypeConnectionInit:
+			isInitialized = true
+			c.write(&operationMessage{Type: messageTypeConnectionAck})
+		case messageTypeStart:
+			if !isInitialized {
+				c.write(&operationMessage{Type: messageTypeConnectionError,
+					Payload: errorPayload(errors.New("the connection was not initialized"))})
+				return
+			}
+			c.start(ctx, message)
+		case messageTypeStop:
+			c.stop(message.ID)
+		case messageTypeConnectionTerminate:
+			return
+		default:
+			c.write(&operationMessage{Type: messageTypeConnectionError,
+				Payload: errorPayload(errors.Errorf("unknown message type '%s'", message.Type))})
+		}
+	}
+}
+
+func (c *webSocketConnection) start(ctx context.Context, message *operationMessage) {
+	request := &queryRequest{}
+	err := json.Unmarshal(message.Payload, request)
+	if err != nil {
+		c.write(&operationMessage{ID: message.ID, Type: messageTypeError,
+			Payload: errorPayload(errors.New("the payload is not a valid GraphQL request"))})
+		return
+	}
+
+	c.operationsLock.Lock()
+	if _, ok := c.operations[message.ID]; ok {
+		c.operationsLock.Unlock()
+		c.write(&operationMessage{ID: message.ID, Type: messageTypeError,
+			Payload: errorPayload(errors.Errorf("an operation with ID '%s' is already running", message.ID))})
+		return
+	}
+	if len(c.operations) >= maxOperationsPerConnection {
+		c.operationsLock.Unlock()
+		c.write(&operationMessage{ID: message.ID, Type: messageTypeError,
+			Payload: errorPayload(errors.Errorf("no more than %d operations may run at once", maxOperationsPerConnection))})
+		return
+	}
+	operationCtx, cancel := context.WithCancel(withRequestState(ctx))
+	c.operations[message.ID] = cancel
+	c.operationsLock.Unlock()
+
+	responses, err := schema.Subscribe(operationCtx, request.Query, request.OperationName, request.Variables)
+	if err != nil {
+		c.stop(message.ID)
+		c.write(&operationMessage{ID: message.ID, Type: messageTypeError, Payload: errorPayload(err)})
+		return
+	}
+
+	spawn("graphql-webSocketConnection-start", func() {
+		defer c.stop(message.ID)
+		for {
+			select {
+			case response, ok := <-responses:
+				if !ok {
+					c.write(&operationMessage{ID: message.ID, Type: messageTypeComplete})
+					return
+				}
+				payload, err := json.Marshal(response)
+				if err != nil {
+					log.Errorf("Error marshalling a GraphQL response: %s", err)
+					return
+				}
+				c.write(&operationMessage{ID: message.ID, Type: messageTypeData, Payload: payload})
+			case <-operationCtx.Done():
+				return
+			}
+		}
+	})
+}
+
+func (c *webSocketConnection) stop(id string) {
+	c.operationsLock.Lock()
+	defer c.operationsLock.Unlock()
+
+	if cancel, ok := c.operations[id]; ok {
+		cancel()
+		delete(c.operations, id)
+	}
+}
+
+func (c *webSocketConnection) write(message *operationMessage) {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	_ = c.connection.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout))
+	err := c.connection.WriteJSON(message)
+	if err != nil {
+		// A failed write leaves the connection broken, so closing it
+		// makes the read loop return and stop all the operations
+		_ = c.connection.Close()
+	}
+}
+
+func errorPayload(err error) json.RawMessage {
+	payload, _ := json.Marshal(struct {
+		Message string `json:"message"`
+	}{
+		Message: err.Error(),
+	})
+	return payload
+}
@@ -0,0 +1,95 @@
+package grpcserver
+
+import (
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/serverd/grpcserver/protowire"
+)
+
+func convertBlockResponse(block *apimodels.BlockResponse) *protowire.Block {
+	return &protowire.Block{
+		BlockHash:            block.BlockHash,
+		Version:              uint32(block.Version),
+		HashMerkleRoot:       block.HashMerkleRoot,
+		AcceptedIDMerkleRoot: block.AcceptedIDMerkleRoot,
+		UtxoCommitment:       block.UTXOCommitment,
+		Timestamp:            block.Timestamp,
+		Bits:                 block.Bits,
+		Nonce:                block.Nonce,
+		ParentBlockHashes:    block.ParentBlockHashes,
+		ChildBlockHashes:     block.ChildBlockHashes,
+		BlueScore:            block.BlueScore,
+		TransactionCount:     uint32(block.TransactionCount),
+		Difficulty:           block.Difficulty,
+		TransactionIds:       block.TransactionIDs,
+	}
+}
+
+func convertBlockResponses(blocks []*apimodels.BlockResponse) []*protowire.Block {
+	protoBlocks := make([]*protowire.Block, len(blocks))
+	for i, block := range blocks {
+		protoBlocks[i] = convertBlockResponse(block)
+	}
+	return protoBlocks
+}
+
+func convertTransactionResponse(transaction *apimodels.TransactionResponse) *protowire.Transaction {
+	inputs := make([]*protowire.TransactionInput, len(transaction.Inputs))
+	for i, input := range transaction.Inputs {
+		inputs[i] = &protowire.TransactionInput{
+			TransactionId:                  input.TransactionID,
+			PreviousTransactionId:          input.PreviousTransactionID,
+			PreviousTransactionOutputIndex: input.PreviousTransactionOutputIndex,
+			SignatureScript:                input.SignatureScript,
+			Sequence:                       input.Sequence,
+			Address:                        input.Address,
+			Value:                          input.Value,
+			Index:                          input.Index,
+			HasKnownPreviousOutput:         input.HasKnownPreviousOutput,
+		}
+	}
+
+	return &protowire.Transaction{
+		TransactionHash:         transaction.TransactionHash,
+		TransactionId:           transaction.TransactionID,
+		AcceptingBlockHash:      transaction.AcceptingBlockHash,
+		AcceptingBlockBlueScore: transaction.AcceptingBlockBlueScore,
+		SubnetworkId:            transaction.SubnetworkID,
+		LockTime:                transaction.LockTime,
+		Gas:                     transaction.Gas,
+		Payload:                 transaction.Payload,
+		Inputs:                  inputs,
+		Outputs:                 convertTransactionOutputResponses(transaction.Outputs),
+		Mass:                    transaction.Mass,
+		Version:                 uint32(transaction.Version),
+		Blocks:                  convertBlockResponses(transaction.Blocks),
+		NetValue:                transaction.NetValue,
+	}
+}
+
+func convertTransactionResponses(transactions []*apimodels.TransactionResponse) []*protowire.Transaction {
+	protoTransactions := make([]*protowire.Transaction, len(transactions))
+	for i, transaction := range transactions {
+		protoTransactions[i] = convertTransactionResponse(transaction)
+	}
+	return protoTransactions
+}
+
+func convertTransactionOutputResponses(outputs []*apimodels.TransactionOutputResponse) []*protowire.TransactionOutput {
+	protoOutputs := make([]*protowire.TransactionOutput, len(outputs))
+	for i, output := range outputs {
+		protoOutputs[i] = &protowire.TransactionOutput{
+			TransactionId:           output.TransactionID,
+			Value:                   output.Value,
+			ScriptPubKey:            output.ScriptPubKey,
+			Address:                 output.Address,
+			AcceptingBlockHash:      output.AcceptingBlockHash,
+			AcceptingBlockBlueScore: output.AcceptingBlockBlueScore,
+			Index:                   output.Index,
+			IsSpent:                 output.IsSpent,
+			IsCoinbase:              output.IsCoinbase,
+			IsSpendable:             output.IsSpendable,
+			Confirmations:           output.Confirmations,
+		}
+	}
+	return protoOutputs
+}
@@ -0,0 +1,50 @@
+package grpcserver
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/httpserverutils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// httpStatusCodes maps the HTTP status codes of the
+// controllers' errors to gRPC status codes
+var httpStatusCodes = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnprocessableEntity:   codes.InvalidArgument,
+	http.StatusRequestEntityTooLarge: codes.InvalidArgument,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusBadGateway:            codes.Unavailable,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+}
+
+// convertHandlerErrorToStatus converts an error returned by
+// a controller to a gRPC status error
+func convertHandlerErrorToStatus(err error) error {
+	code, message := handlerErrorCodeAndMessage(err)
+	if code == codes.Internal {
+		log.Errorf("Internal error: %+v", err)
+	}
+	return status.Error(code, message)
+}
+
+// handlerErrorCodeAndMessage returns the gRPC status code and the client
+// message of the given controller error. Errors that aren't HandlerErrors
+// are internal, and their details are hidden from the client.
+func handlerErrorCodeAndMessage(err error) (codes.Code, string) {
+	var handlerErr *httpserverutils.HandlerError
+	if !errors.As(err, &handlerErr) {
+		return codes.Internal, http.StatusText(http.StatusInternalServerError)
+	}
+	code, ok := httpStatusCodes[handlerErr.Code]
+	if !ok {
+		code = codes.Internal
+	}
+	return code, handlerErr.ClientMessage
+}
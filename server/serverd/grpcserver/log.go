@@ -0,0 +1,11 @@
+package grpcserver
+
+import (
+	"github.com/kaspanet/kaspad/util/panics"
+	"github.com/someone235/katnip/server/logger"
+)
+
+var (
+	log   = logger.Logger("GRPC")
+	spawn = panics.GoroutineWrapperFunc(log)
+)
@@ -0,0 +1,420 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.17.3
+// source: katnip.proto
+
+package protowire
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// KatnipClient is the client API for Katnip service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type KatnipClient interface {
+	GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*Block, error)
+	GetBlocks(ctx context.Context, in *GetBlocksRequest, opts ...grpc.CallOption) (*GetBlocksResponse, error)
+	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*Transaction, error)
+	GetUTXOsByAddress(ctx context.Context, in *GetUTXOsByAddressRequest, opts ...grpc.CallOption) (*GetUTXOsByAddressResponse, error)
+	GetAddressTransactions(ctx context.Context, in *GetAddressTransactionsRequest, opts ...grpc.CallOption) (*GetAddressTransactionsResponse, error)
+	GetAddressTransactionCount(ctx context.Context, in *GetAddressTransactionCountRequest, opts ...grpc.CallOption) (*GetAddressTransactionCountResponse, error)
+	// StreamBlocks streams every block that's added to the DAG
+	StreamBlocks(ctx context.Context, in *StreamBlocksRequest, opts ...grpc.CallOption) (Katnip_StreamBlocksClient, error)
+	// StreamAddressActivity streams the transactions that send to or spend
+	// from an address, both when they're included in a block and when
+	// they're accepted
+	StreamAddressActivity(ctx context.Context, in *StreamAddressActivityRequest, opts ...grpc.CallOption) (Katnip_StreamAddressActivityClient, error)
+}
+
+type katnipClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewKatnipClient(cc grpc.ClientConnInterface) KatnipClient {
+	return &katnipClient{cc}
+}
+
+func (c *katnipClient) GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*Block, error) {
+	out := new(Block)
+	err := c.cc.Invoke(ctx, "/katnip.Katnip/GetBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *katnipClient) GetBlocks(ctx context.Context, in *GetBlocksRequest, opts ...grpc.CallOption) (*GetBlocksResponse, error) {
+	out := new(GetBlocksResponse)
+	err := c.cc.Invoke(ctx, "/katnip.Katnip/GetBlocks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *katnipClient) GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*Transaction, error) {
+	out := new(Transaction)
+	err := c.cc.Invoke(ctx, "/katnip.Katnip/GetTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *katnipClient) GetUTXOsByAddress(ctx context.Context, in *GetUTXOsByAddressRequest, opts ...grpc.CallOption) (*GetUTXOsByAddressResponse, error) {
+	out := new(GetUTXOsByAddressResponse)
+	err := c.cc.Invoke(ctx, "/katnip.Katnip/GetUTXOsByAddress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *katnipClient) GetAddressTransactions(ctx context.Context, in *GetAddressTransactionsRequest, opts ...grpc.CallOption) (*GetAddressTransactionsResponse, error) {
+	out := new(GetAddressTransactionsResponse)
+	err := c.cc.Invoke(ctx, "/katnip.Katnip/GetAddressTransactions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *katnipClient) GetAddressTransactionCount(ctx context.Context, in *GetAddressTransactionCountRequest, opts ...grpc.CallOption) (*GetAddressTransactionCountResponse, error) {
+	out := new(GetAddressTransactionCountResponse)
+	err := c.cc.Invoke(ctx, "/katnip.Katnip/GetAddressTransactionCount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *katnipClient) StreamBlocks(ctx context.Context, in *StreamBlocksRequest, opts ...grpc.CallOption) (Katnip_StreamBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Katnip_ServiceDesc.Streams[0], "/katnip.Katnip/StreamBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &katnipStreamBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Katnip_StreamBlocksClient interface {
+	Recv() (*Block, error)
+	grpc.ClientStream
+}
+
+type katnipStreamBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *katnipStreamBlocksClient) Recv() (*Block, error) {
+	m := new(Block)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *katnipClient) StreamAddressActivity(ctx context.Context, in *StreamAddressActivityRequest, opts ...grpc.CallOption) (Katnip_StreamAddressActivityClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Katnip_ServiceDesc.Streams[1], "/katnip.Katnip/StreamAddressActivity", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &katnipStreamAddressActivityClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Katnip_StreamAddressActivityClient interface {
+	Recv() (*AddressActivity, error)
+	grpc.ClientStream
+}
+
+type katnipStreamAddressActivityClient struct {
+	grpc.ClientStream
+}
+
+func (x *katnipStreamAddressActivityClient) Recv() (*AddressActivity, error) {
+	m := new(AddressActivity)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// KatnipServer is the server API for Katnip service.
+// All implementations must embed UnimplementedKatnipServer
+// for forward compatibility
+type KatnipServer interface {
+	GetBlock(context.Context, *GetBlockRequest) (*Block, error)
+	GetBlocks(context.Context, *GetBlocksRequest) (*GetBlocksResponse, error)
+	GetTransaction(context.Context, *GetTransactionRequest) (*Transaction, error)
+	GetUTXOsByAddress(context.Context, *GetUTXOsByAddressRequest) (*GetUTXOsByAddressResponse, error)
+	GetAddressTransactions(context.Context, *GetAddressTransactionsRequest) (*GetAddressTransactionsResponse, error)
+	GetAddressTransactionCount(context.Context, *GetAddressTransactionCountRequest) (*GetAddressTransactionCountResponse, error)
+	// StreamBlocks streams every block that's added to the DAG
+	StreamBlocks(*StreamBlocksRequest, Katnip_StreamBlocksServer) error
+	// StreamAddressActivity streams the transactions that send to or spend
+	// from an address, both when they're included in a block and when
+	// they're accepted
+	StreamAddressActivity(*StreamAddressActivityRequest, Katnip_StreamAddressActivityServer) error
+	mustEmbedUnimplementedKatnipServer()
+}
+
+// UnimplementedKatnipServer must be embedded to have forward compatible implementations.
+type UnimplementedKatnipServer struct {
+}
+
+func (UnimplementedKatnipServer) GetBlock(context.Context, *GetBlockRequest) (*Block, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlock not implemented")
+}
+func (UnimplementedKatnipServer) GetBlocks(context.Context, *GetBlocksRequest) (*GetBlocksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlocks not implemented")
+}
+func (UnimplementedKatnipServer) GetTransaction(context.Context, *GetTransactionRequest) (*Transaction, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransaction not implemented")
+}
+func (UnimplementedKatnipServer) GetUTXOsByAddress(context.Context, *GetUTXOsByAddressRequest) (*GetUTXOsByAddressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUTXOsByAddress not implemented")
+}
+func (UnimplementedKatnipServer) GetAddressTransactions(context.Context, *GetAddressTransactionsRequest) (*GetAddressTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAddressTransactions not implemented")
+}
+func (UnimplementedKatnipServer) GetAddressTransactionCount(context.Context, *GetAddressTransactionCountRequest) (*GetAddressTransactionCountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAddressTransactionCount not implemented")
+}
+func (UnimplementedKatnipServer) StreamBlocks(*StreamBlocksRequest, Katnip_StreamBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamBlocks not implemented")
+}
+func (UnimplementedKatnipServer) StreamAddressActivity(*StreamAddressActivityRequest, Katnip_StreamAddressActivityServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamAddressActivity not implemented")
+}
+func (UnimplementedKatnipServer) mustEmbedUnimplementedKatnipServer() {}
+
+// UnsafeKatnipServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to KatnipServer will
+// result in compilation errors.
+type UnsafeKatnipServer interface {
+	mustEmbedUnimplementedKatnipServer()
+}
+
+func RegisterKatnipServer(s grpc.ServiceRegistrar, srv KatnipServer) {
+	s.RegisterService(&Katnip_ServiceDesc, srv)
+}
+
+func _Katnip_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KatnipServer).GetBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/katnip.Katnip/GetBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KatnipServer).GetBlock(ctx, req.(*GetBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Katnip_GetBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KatnipServer).GetBlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/katnip.Katnip/GetBlocks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KatnipServer).GetBlocks(ctx, req.(*GetBlocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Katnip_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KatnipServer).GetTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/katnip.Katnip/GetTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KatnipServer).GetTransaction(ctx, req.(*GetTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Katnip_GetUTXOsByAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUTXOsByAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KatnipServer).GetUTXOsByAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/katnip.Katnip/GetUTXOsByAddress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KatnipServer).GetUTXOsByAddress(ctx, req.(*GetUTXOsByAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Katnip_GetAddressTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAddressTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KatnipServer).GetAddressTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/katnip.Katnip/GetAddressTransactions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KatnipServer).GetAddressTransactions(ctx, req.(*GetAddressTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Katnip_GetAddressTransactionCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAddressTransactionCountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KatnipServer).GetAddressTransactionCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/katnip.Katnip/GetAddressTransactionCount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KatnipServer).GetAddressTransactionCount(ctx, req.(*GetAddressTransactionCountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Katnip_StreamBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamBlocksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KatnipServer).StreamBlocks(m, &katnipStreamBlocksServer{stream})
+}
+
+type Katnip_StreamBlocksServer interface {
+	Send(*Block) error
+	grpc.ServerStream
+}
+
+type katnipStreamBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *katnipStreamBlocksServer) Send(m *Block) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Katnip_StreamAddressActivity_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamAddressActivityRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KatnipServer).StreamAddressActivity(m, &katnipStreamAddressActivityServer{stream})
+}
+
+type Katnip_StreamAddressActivityServer interface {
+	Send(*AddressActivity) error
+	grpc.ServerStream
+}
+
+type katnipStreamAddressActivityServer struct {
+	grpc.ServerStream
+}
+
+func (x *katnipStreamAddressActivityServer) Send(m *AddressActivity) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Katnip_ServiceDesc is the grpc.ServiceDesc for Katnip service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Katnip_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "katnip.Katnip",
+	HandlerType: (*KatnipServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetBlock",
+			Handler:    _Katnip_GetBlock_Handler,
+		},
+		{
+			MethodName: "GetBlocks",
+			Handler:    _Katnip_GetBlocks_Handler,
+		},
+		{
+			MethodName: "GetTransaction",
+			Handler:    _Katnip_GetTransaction_Handler,
+		},
+		{
+			MethodName: "GetUTXOsByAddress",
+			Handler:    _Katnip_GetUTXOsByAddress_Handler,
+		},
+		{
+			MethodName: "GetAddressTransactions",
+			Handler:    _Katnip_GetAddressTransactions_Handler,
+		},
+		{
+			MethodName: "GetAddressTransactionCount",
+			Handler:    _Katnip_GetAddressTransactionCount_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamBlocks",
+			Handler:       _Katnip_StreamBlocks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamAddressActivity",
+			Handler:       _Katnip_StreamAddressActivity_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "katnip.proto",
+}
@@ -0,0 +1,72 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/someone235/katnip/server/httpserverutils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// retryAfterMetadataKey is the response header that holds the number of
+// seconds to wait before retrying a call that exceeded its rate limit
+const retryAfterMetadataKey = "retry-after"
+
+// rateLimitInterceptors returns the interceptors that limit the rate of
+// calls with the given limiter, the same way that the HTTP server does.
+// API keys are taken from the X-API-Key metadata.
+func rateLimitInterceptors(rateLimiter *httpserverutils.RateLimiter) (grpc.UnaryServerInterceptor,
+	grpc.StreamServerInterceptor) {
+
+	unaryInterceptor := func(ctx context.Context, request interface{}, _ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		err := limit(ctx, rateLimiter)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, request)
+	}
+
+	streamInterceptor := func(server interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+
+		err := limit(stream.Context(), rateLimiter)
+		if err != nil {
+			return err
+		}
+		return handler(server, stream)
+	}
+
+	return unaryInterceptor, streamInterceptor
+}
+
+// limit counts the call of the given context with the given limiter, and
+// returns a status error if it exceeds its rate limit or quota
+func limit(ctx context.Context, rateLimiter *httpserverutils.RateLimiter) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	remoteAddr := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr.String()
+	}
+	clientIP := rateLimiter.ClientIP(strings.Join(md.Get("x-forwarded-for"), ","), remoteAddr)
+	apiKey := ""
+	if apiKeys := md.Get(strings.ToLower(httpserverutils.APIKeyHeader)); len(apiKeys) > 0 {
+		apiKey = apiKeys[0]
+	}
+
+	retryAfter, err := rateLimiter.Limit(clientIP, apiKey, time.Now())
+	if err != nil {
+		if retryAfter > 0 {
+			retryAfterSeconds := fmt.Sprintf("%d", int64(math.Ceil(retryAfter.Seconds())))
+			_ = grpc.SetHeader(ctx, metadata.Pairs(retryAfterMetadataKey, retryAfterSeconds))
+		}
+		return convertHandlerErrorToStatus(err)
+	}
+	return nil
+}
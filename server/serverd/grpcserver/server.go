@@ -0,0 +1,74 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/httpserverutils"
+	"github.com/someone235/katnip/server/serverd/grpcserver/protowire"
+	"google.golang.org/grpc"
+)
+
+const gracefulStopTimeout = 30 * time.Second
+
+// Start starts the gRPC server and returns a function to gracefully
+// stop it. Calls are limited by the given rate limiter.
+func Start(listenAddr string, rateLimiter *httpserverutils.RateLimiter) (func(), error) {
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listening on %s", listenAddr)
+	}
+
+	unaryRateLimitInterceptor, streamRateLimitInterceptor := rateLimitInterceptors(rateLimiter)
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryLoggingInterceptor, unaryRateLimitInterceptor),
+		grpc.ChainStreamInterceptor(streamLoggingInterceptor, streamRateLimitInterceptor),
+	)
+	protowire.RegisterKatnipServer(grpcServer, &katnipServer{})
+
+	spawn("grpcserver-Start", func() {
+		log.Infof("Katnip gRPC is listening on %s", listenAddr)
+		err := grpcServer.Serve(listener)
+		if err != nil {
+			log.Errorf("%s", err)
+		}
+	})
+
+	return func() {
+		stopped := make(chan struct{})
+		spawn("grpcserver-GracefulStop", func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		})
+		select {
+		case <-stopped:
+		case <-time.After(gracefulStopTimeout):
+			log.Warnf("Timed out stopping the gRPC server gracefully")
+			grpcServer.Stop()
+		}
+	}, nil
+}
+
+func unaryLoggingInterceptor(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+
+	log.Infof("Method: %s", info.FullMethod)
+	response, err := handler(ctx, request)
+	if err != nil {
+		log.Warnf("%s: got error: %s", info.FullMethod, err)
+	}
+	return response, err
+}
+
+func streamLoggingInterceptor(server interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+
+	log.Infof("Method: %s", info.FullMethod)
+	err := handler(server, stream)
+	if err != nil {
+		log.Warnf("%s: got error: %s", info.FullMethod, err)
+	}
+	return err
+}
@@ -0,0 +1,224 @@
+package grpcserver
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/httpserverutils"
+	"github.com/someone235/katnip/server/serverd/controllers"
+	"github.com/someone235/katnip/server/serverd/grpcserver/protowire"
+	"github.com/someone235/katnip/server/serverd/stream"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The defaults of the REST API, which apply when
+// the respective request fields are left empty
+const (
+	defaultGetBlocksLimit       = 25
+	defaultGetBlocksOrder       = string(dbaccess.OrderDescending)
+	defaultGetTransactionsLimit = 100
+	defaultGetTransactionsOrder = string(dbaccess.OrderAscending)
+)
+
+// katnipServer implements the Katnip gRPC service on top of the REST controllers
+type katnipServer struct {
+	protowire.UnimplementedKatnipServer
+}
+
+func (*katnipServer) GetBlock(ctx context.Context, request *protowire.GetBlockRequest) (*protowire.Block, error) {
+	response, err := controllers.GetBlockByHashHandler(ctx, request.BlockHash)
+	if err != nil {
+		return nil, convertHandlerErrorToStatus(err)
+	}
+	return convertBlockResponse(httpserverutils.UnwrapResponse(response).(*apimodels.BlockResponse)), nil
+}
+
+func (*katnipServer) GetBlocks(ctx context.Context, request *protowire.GetBlocksRequest) (*protowire.GetBlocksResponse, error) {
+	order := stringOrDefault(request.Order, defaultGetBlocksOrder)
+	limit := uint64OrDefault(request.Limit, defaultGetBlocksLimit)
+
+	if request.Cursor != nil {
+		response, err := controllers.GetBlocksPageHandler(ctx, order, *request.Cursor, limit)
+		if err != nil {
+			return nil, convertHandlerErrorToStatus(err)
+		}
+		page := httpserverutils.UnwrapResponse(response).(*apimodels.PaginatedResponse)
+		return &protowire.GetBlocksResponse{
+			Blocks:     convertBlockResponses(page.Items.([]*apimodels.BlockResponse)),
+			NextCursor: page.Next,
+			PrevCursor: page.Prev,
+		}, nil
+	}
+
+	response, err := controllers.GetBlocksHandler(ctx, order, int64(request.Skip), limit)
+	if err != nil {
+		return nil, convertHandlerErrorToStatus(err)
+	}
+	return &protowire.GetBlocksResponse{
+		Blocks: convertBlockResponses(httpserverutils.UnwrapResponse(response).([]*apimodels.BlockResponse)),
+	}, nil
+}
+
+func (*katnipServer) GetTransaction(ctx context.Context, request *protowire.GetTransactionRequest) (*protowire.Transaction, error) {
+	var response interface{}
+	var err error
+	switch selector := request.Selector.(type) {
+	case *protowire.GetTransactionRequest_TransactionId:
+		response, err = controllers.GetTransactionByIDHandler(ctx, selector.TransactionId)
+	case *protowire.GetTransactionRequest_TransactionHash:
+		response, err = controllers.GetTransactionByHashHandler(ctx, selector.TransactionHash)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "either transactionId or transactionHash is required")
+	}
+	if err != nil {
+		return nil, convertHandlerErrorToStatus(err)
+	}
+	return convertTransactionResponse(httpserverutils.UnwrapResponse(response).(*apimodels.TransactionResponse)), nil
+}
+
+func (*katnipServer) GetUTXOsByAddress(ctx context.Context, request *protowire.GetUTXOsByAddressRequest) (
+	*protowire.GetUTXOsByAddressResponse, error) {
+
+	response, err := controllers.GetUTXOsByAddressHandler(ctx, request.Address)
+	if err != nil {
+		return nil, convertHandlerErrorToStatus(err)
+	}
+	return &protowire.GetUTXOsByAddressResponse{
+		Utxos: convertTransactionOutputResponses(response.([]*apimodels.TransactionOutputResponse)),
+	}, nil
+}
+
+func (*katnipServer) GetAddressTransactions(ctx context.Context, request *protowire.GetAddressTransactionsRequest) (
+	*protowire.GetAddressTransactionsResponse, error) {
+
+	order := stringOrDefault(request.Order, defaultGetTransactionsOrder)
+	limit := uint64OrDefault(request.Limit, defaultGetTransactionsLimit)
+	filter := &dbaccess.AddressTransactionsFilter{
+		FromBlueScore: request.FromBlueScore,
+		ToBlueScore:   request.ToBlueScore,
+		FromTime:      unixTimeToTime(request.FromTime),
+		ToTime:        unixTimeToTime(request.ToTime),
+		Direction:     dbaccess.TransactionDirection(request.Direction),
+		AcceptedOnly:  request.AcceptedOnly,
+		MinValue:      request.MinValue,
+	}
+
+	if request.Cursor != nil {
+		response, err := controllers.GetTransactionsByAddressPageHandler(ctx, request.Address, filter, order, *request.Cursor, limit)
+		if err != nil {
+			return nil, convertHandlerErrorToStatus(err)
+		}
+		page := response.(*apimodels.PaginatedResponse)
+		return &protowire.GetAddressTransactionsResponse{
+			Transactions: convertTransactionResponses(page.Items.([]*apimodels.TransactionResponse)),
+			NextCursor:   page.Next,
+			PrevCursor:   page.Prev,
+		}, nil
+	}
+
+	response, err := controllers.GetTransactionsByAddressHandler(ctx, request.Address, filter, order, int64(request.Skip), limit)
+	if err != nil {
+		return nil, convertHandlerErrorToStatus(err)
+	}
+	return &protowire.GetAddressTransactionsResponse{
+		Transactions: convertTransactionResponses(response.([]*apimodels.TransactionResponse)),
+	}, nil
+}
+
+func (*katnipServer) GetAddressTransactionCount(ctx context.Context, request *protowire.GetAddressTransactionCountRequest) (
+	*protowire.GetAddressTransactionCountResponse, error) {
+
+	response, err := controllers.GetTransactionCountByAddressHandler(ctx, request.Address)
+	if err != nil {
+		return nil, convertHandlerErrorToStatus(err)
+	}
+	return &protowire.GetAddressTransactionCountResponse{
+		Count: response.(uint64),
+	}, nil
+}
+
+func (*katnipServer) StreamBlocks(_ *protowire.StreamBlocksRequest, server protowire.Katnip_StreamBlocksServer) error {
+	filter, err := controllers.NewStreamFilter(stream.BlocksTopic, "", 0)
+	if err != nil {
+		return convertHandlerErrorToStatus(err)
+	}
+	return serveStream(server.Context(), filter, func(event *stream.Event) error {
+		return server.Send(convertBlockResponse(event.Data.(*apimodels.BlockResponse)))
+	})
+}
+
+func (*katnipServer) StreamAddressActivity(request *protowire.StreamAddressActivityRequest,
+	server protowire.Katnip_StreamAddressActivityServer) error {
+
+	if request.Address == "" {
+		return status.Error(codes.InvalidArgument, "address is required")
+	}
+	topics := stream.TransactionsTopic + "," + stream.AcceptedTransactionsTopic
+	if request.AcceptedOnly {
+		topics = stream.AcceptedTransactionsTopic
+	}
+	filter, err := controllers.NewStreamFilter(topics, request.Address, request.MinValue)
+	if err != nil {
+		return convertHandlerErrorToStatus(err)
+	}
+	return serveStream(server.Context(), filter, func(event *stream.Event) error {
+		return server.Send(&protowire.AddressActivity{
+			Accepted:    event.Topic == stream.AcceptedTransactionsTopic,
+			Transaction: convertTransactionResponse(event.Data.(*apimodels.TransactionResponse)),
+		})
+	})
+}
+
+// serveStream sends the events selected by the given filter
+// until the client cancels the call or the stream is dropped
+func serveStream(ctx context.Context, filter *stream.Filter, send func(event *stream.Event) error) error {
+	subscription, err := stream.Subscribe(filter)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	defer subscription.Unsubscribe()
+
+	for {
+		select {
+		case event := <-subscription.Events():
+			err := send(event)
+			if err != nil {
+				return err
+			}
+		case <-subscription.Done():
+			return status.Error(codes.Unavailable, "the stream was dropped")
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func stringOrDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+func uint64OrDefault(value uint64, defaultValue int64) int64 {
+	if value == 0 {
+		return defaultValue
+	}
+	// Values that overflow int64 are rejected by the controllers' limits anyway
+	if value > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(value)
+}
+
+func unixTimeToTime(unixTime *uint64) *time.Time {
+	if unixTime == nil {
+		return nil
+	}
+	timeValue := time.Unix(int64(*unixTime), 0).UTC()
+	return &timeValue
+}
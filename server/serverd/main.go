@@ -13,9 +13,16 @@ import (
 	"github.com/kaspanet/kaspad/infrastructure/os/signal"
 	"github.com/kaspanet/kaspad/util/panics"
 	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/httpserverutils"
 	"github.com/someone235/katnip/server/kaspadrpc"
+	"github.com/someone235/katnip/server/metrics"
+	"github.com/someone235/katnip/server/serverd/apikeys"
 	"github.com/someone235/katnip/server/serverd/config"
+	"github.com/someone235/katnip/server/serverd/grpcserver"
 	"github.com/someone235/katnip/server/serverd/server"
+	"github.com/someone235/katnip/server/serverd/stream"
+	"github.com/someone235/katnip/server/serverd/webhooks"
+	"github.com/someone235/katnip/server/tracing"
 	"github.com/someone235/katnip/server/version"
 )
 
@@ -41,6 +48,18 @@ func main() {
 		profiling.Start(config.ActiveConfig().Profile, log)
 	}
 
+	// Start the metrics server if required
+	if config.ActiveConfig().MetricsListen != "" {
+		shutdownMetrics := metrics.Start(config.ActiveConfig().MetricsListen)
+		defer shutdownMetrics()
+	}
+
+	shutdownTracing, err := tracing.Start("katnip-serverd", &config.ActiveConfig().CommonConfigFlags)
+	if err != nil {
+		panic(errors.Errorf("Error starting tracing: %s", err))
+	}
+	defer shutdownTracing()
+
 	err = database.Connect(&config.ActiveConfig().CommonConfigFlags)
 	if err != nil {
 		panic(errors.Errorf("Error connecting to database: %s", err))
@@ -58,8 +77,48 @@ func main() {
 	}
 	defer client.Close()
 
-	shutdownServer := server.Start(config.ActiveConfig().HTTPListen)
+	// The API key store is stopped after the server is shut down,
+	// so that the usage of the last requests is written as well
+	apiKeyStore := apikeys.NewStore()
+	stopAPIKeyStore := apiKeyStore.Start()
+	defer stopAPIKeyStore()
+
+	rateLimiter := httpserverutils.NewRateLimiter(httpserverutils.RateLimit{
+		RequestsPerSecond: config.ActiveConfig().RateLimit,
+		Burst:             config.ActiveConfig().RateLimitBurst,
+	}, apiKeyStore, config.ActiveConfig().ForwardedFor)
+
+	var responseCache *httpserverutils.ResponseCache
+	if config.ActiveConfig().ResponseCacheSize > 0 {
+		responseCache = httpserverutils.NewResponseCache(config.ActiveConfig().ResponseCacheSize)
+	}
+	shutdownServer := server.Start(config.ActiveConfig().HTTPListen, rateLimiter, responseCache)
 	defer shutdownServer()
 
+	shutdownGRPCServer, err := grpcserver.Start(config.ActiveConfig().GRPCListen, rateLimiter)
+	if err != nil {
+		panic(errors.Errorf("Error starting the gRPC server: %s", err))
+	}
+	defer shutdownGRPCServer()
+
+	stopWebhooks := webhooks.Start()
+	defer stopWebhooks()
+
+	// The stream is stopped before the server is shut down,
+	// so that open streams don't hold the shutdown back
+	stopStream, err := stream.Start()
+	if err != nil {
+		panic(errors.Errorf("Error starting the stream: %s", err))
+	}
+	defer stopStream()
+
+	if responseCache != nil {
+		stopResponseCacheInvalidation, err := server.StartResponseCacheInvalidation(responseCache)
+		if err != nil {
+			panic(errors.Errorf("Error starting the response cache invalidation: %s", err))
+		}
+		defer stopResponseCacheInvalidation()
+	}
+
 	<-interrupt
 }
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"github.com/someone235/katnip/server/httpserverutils"
+	"github.com/someone235/katnip/server/serverd/stream"
+)
+
+// StartResponseCacheInvalidation invalidates the mutable responses of the
+// given cache whenever a block is added to the DAG or the selected tip
+// changes, and returns a function to stop it. The stream must be running.
+func StartResponseCacheInvalidation(cache *httpserverutils.ResponseCache) (func(), error) {
+	filter := &stream.Filter{
+		Topics: map[string]struct{}{
+			stream.BlocksTopic:      {},
+			stream.SelectedTipTopic: {},
+		},
+	}
+	subscription, err := stream.Subscribe(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	stopChan := make(chan struct{})
+	stoppedChan := make(chan struct{})
+	spawn("server-StartResponseCacheInvalidation", func() {
+		defer close(stoppedChan)
+		for {
+			select {
+			case <-subscription.Events():
+				cache.InvalidateMutable()
+			case <-subscription.Done():
+				// Events may have been missed, so the cache is
+				// invalidated before subscribing again
+				cache.InvalidateMutable()
+				subscription, err = stream.Subscribe(filter)
+				if err != nil {
+					// The stream stopped
+					return
+				}
+			case <-stopChan:
+				subscription.Unsubscribe()
+				return
+			}
+		}
+	})
+
+	return func() {
+		close(stopChan)
+		<-stoppedChan
+	}, nil
+}
@@ -0,0 +1,402 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/httpserverutils"
+	"github.com/someone235/katnip/server/version"
+)
+
+const openAPIVersion = "3.0.3"
+
+type openAPISpec struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       *openAPIInfo                            `json:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components *openAPIComponents                      `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*openAPISchema `json:"schemas"`
+}
+
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                         `json:"required"`
+	Content  map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+	AllOf                []*openAPISchema          `json:"allOf,omitempty"`
+	OneOf                []*openAPISchema          `json:"oneOf,omitempty"`
+}
+
+// paginatedResponse documents an apimodels.PaginatedResponse whose
+// items are of the same type as the given value
+type paginatedResponse struct {
+	items interface{}
+}
+
+func paginated(items interface{}) paginatedResponse {
+	return paginatedResponse{items: items}
+}
+
+var pathParamRegexp = regexp.MustCompile(`{([^}:]+)(:[^}]*)?}`)
+
+var (
+	openAPISpecOnce   sync.Once
+	cachedOpenAPISpec *openAPISpec
+	openAPISpecErr    error
+)
+
+// makeOpenAPIHandler returns a handler that serves the OpenAPI
+// specification of the routes of the given router. The specification
+// is generated on the first request, once all the routes are registered.
+func makeOpenAPIHandler(router *mux.Router) httpserverutils.HandlerFunc {
+	return func(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+		_ []byte) (interface{}, error) {
+
+		openAPISpecOnce.Do(func() {
+			cachedOpenAPISpec, openAPISpecErr = buildOpenAPISpec(router)
+		})
+		if openAPISpecErr != nil {
+			return nil, httpserverutils.NewInternalServerHandlerError(openAPISpecErr)
+		}
+		return cachedOpenAPISpec, nil
+	}
+}
+
+// buildOpenAPISpec generates an OpenAPI specification from the routes
+// registered on the given router and their entries in routeDocs. It
+// returns an error if a route isn't documented or if its documented
+// types can't be described by a schema.
+func buildOpenAPISpec(router *mux.Router) (*openAPISpec, error) {
+	spec := &openAPISpec{
+		OpenAPI: openAPIVersion,
+		Info: &openAPIInfo{
+			Title:   "Katnip API",
+			Version: version.Version(),
+		},
+		Paths: make(map[string]map[string]*openAPIOperation),
+		Components: &openAPIComponents{
+			Schemas: make(map[string]*openAPISchema),
+		},
+	}
+	generator := &schemaGenerator{schemas: spec.Components.Schemas}
+
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		pathTemplate, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			// Routes that don't restrict their methods are documented as GET
+			methods = []string{http.MethodGet}
+		}
+
+		for _, method := range methods {
+			path := pathParamRegexp.ReplaceAllString(pathTemplate, "{$1}")
+			if _, ok := spec.Paths[path][strings.ToLower(method)]; ok {
+				// Routes that share a method and a path, such as the GraphQL
+				// WebSocket and queries, share a single operation
+				continue
+			}
+			operation, err := buildOpenAPIOperation(generator, method, pathTemplate)
+			if err != nil {
+				return err
+			}
+			if _, ok := spec.Paths[path]; !ok {
+				spec.Paths[path] = make(map[string]*openAPIOperation)
+			}
+			spec.Paths[path][strings.ToLower(method)] = operation
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+func routeDocKey(method string, pathTemplate string) string {
+	return fmt.Sprintf("%s %s", method, pathTemplate)
+}
+
+func buildOpenAPIOperation(generator *schemaGenerator, method string, pathTemplate string) (*openAPIOperation, error) {
+	key := routeDocKey(method, pathTemplate)
+	doc, ok := routeDocs[key]
+	if !ok {
+		return nil, errors.Errorf("route %s is not documented", key)
+	}
+	if len(doc.responses) == 0 && doc.successCode == 0 {
+		return nil, errors.Errorf("route %s has no documented response", key)
+	}
+
+	operation := &openAPIOperation{
+		OperationID: doc.operationID,
+		Summary:     doc.summary,
+		Responses:   make(map[string]*openAPIResponse),
+	}
+
+	for _, match := range pathParamRegexp.FindAllStringSubmatch(pathTemplate, -1) {
+		operation.Parameters = append(operation.Parameters, &openAPIParameter{
+			Name:        match[1],
+			In:          "path",
+			Description: routeParamDescriptions[match[1]],
+			Required:    true,
+			Schema:      &openAPISchema{Type: "string"},
+		})
+	}
+	for _, param := range doc.queryParams {
+		paramDoc, ok := queryParamDocs[param]
+		if !ok {
+			return nil, errors.Errorf("query parameter %s of route %s is not documented", param, key)
+		}
+		operation.Parameters = append(operation.Parameters, &openAPIParameter{
+			Name:        param,
+			In:          "query",
+			Description: paramDoc.description,
+			Schema:      paramDoc.schema,
+		})
+	}
+	for _, header := range doc.headers {
+		operation.Parameters = append(operation.Parameters, &openAPIParameter{
+			Name:     header,
+			In:       "header",
+			Required: true,
+			Schema:   &openAPISchema{Type: "string"},
+		})
+	}
+
+	if doc.requestBody != nil {
+		schema, err := generator.schema(reflect.TypeOf(doc.requestBody))
+		if err != nil {
+			return nil, errors.Wrapf(err, "error describing the request body of route %s", key)
+		}
+		operation.RequestBody = &openAPIRequestBody{
+			Required: true,
+			Content:  map[string]*openAPIMediaType{"application/json": {Schema: schema}},
+		}
+	}
+
+	successCode := doc.successCode
+	if successCode == 0 {
+		successCode = http.StatusOK
+	}
+	response := &openAPIResponse{Description: http.StatusText(successCode)}
+	if len(doc.responses) > 0 {
+		schemas := make([]*openAPISchema, len(doc.responses))
+		for i, responseValue := range doc.responses {
+			schema, err := generator.responseSchema(responseValue)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error describing the response of route %s", key)
+			}
+			schemas[i] = schema
+		}
+		schema := schemas[0]
+		if len(schemas) > 1 {
+			schema = &openAPISchema{OneOf: schemas}
+		}
+		contentType := doc.contentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		response.Content = map[string]*openAPIMediaType{contentType: {Schema: schema}}
+	}
+	operation.Responses[fmt.Sprintf("%d", successCode)] = response
+
+	errorSchema, err := generator.schema(reflect.TypeOf(httpserverutils.ClientError{}))
+	if err != nil {
+		return nil, err
+	}
+	operation.Responses["default"] = &openAPIResponse{
+		Description: "Error",
+		Content:     map[string]*openAPIMediaType{"application/json": {Schema: errorSchema}},
+	}
+
+	return operation, nil
+}
+
+// schemaGenerator describes go types as OpenAPI schemas, the way
+// encoding/json marshals them. Named structs are added to schemas
+// and referenced by name.
+type schemaGenerator struct {
+	schemas map[string]*openAPISchema
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (g *schemaGenerator) responseSchema(response interface{}) (*openAPISchema, error) {
+	page, ok := response.(paginatedResponse)
+	if !ok {
+		return g.schema(reflect.TypeOf(response))
+	}
+
+	schema, err := g.structSchema(reflect.TypeOf(apimodels.PaginatedResponse{}))
+	if err != nil {
+		return nil, err
+	}
+	itemsSchema, err := g.schema(reflect.TypeOf(page.items))
+	if err != nil {
+		return nil, err
+	}
+	schema.Properties["items"] = itemsSchema
+	return schema, nil
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) (*openAPISchema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &openAPISchema{Type: "string", Format: "date-time"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &openAPISchema{Type: "integer", Format: "int32"}, nil
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}, nil
+	case reflect.Float32:
+		return &openAPISchema{Type: "number", Format: "float"}, nil
+	case reflect.Float64:
+		return &openAPISchema{Type: "number", Format: "double"}, nil
+	case reflect.String:
+		return &openAPISchema{Type: "string"}, nil
+	case reflect.Interface:
+		// Any json value
+		return &openAPISchema{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &openAPISchema{Type: "string", Format: "byte"}, nil
+		}
+		items, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &openAPISchema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, errors.Errorf("map %s doesn't have string keys", t)
+		}
+		values, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &openAPISchema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		if _, ok := g.schemas[t.Name()]; !ok {
+			// The entry is reserved before the fields are described,
+			// so that recursive types refer to themselves
+			g.schemas[t.Name()] = nil
+			schema, err := g.structSchema(t)
+			if err != nil {
+				delete(g.schemas, t.Name())
+				return nil, err
+			}
+			g.schemas[t.Name()] = schema
+		}
+		return &openAPISchema{Ref: "#/components/schemas/" + t.Name()}, nil
+	default:
+		return nil, errors.Errorf("type %s can't be described by a schema", t)
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) (*openAPISchema, error) {
+	schema := &openAPISchema{
+		Type:       "object",
+		Properties: make(map[string]*openAPISchema),
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, options := field.Name, ""
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			tagParts := strings.SplitN(tag, ",", 2)
+			if tagParts[0] != "" {
+				name = tagParts[0]
+			}
+			if len(tagParts) > 1 {
+				options = tagParts[1]
+			}
+		}
+
+		fieldSchema, err := g.schema(field.Type)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error describing field %s of %s", field.Name, t)
+		}
+		isOmitted := strings.Contains(options, "omitempty")
+		isNullable := field.Type.Kind() == reflect.Ptr || field.Type.Kind() == reflect.Interface
+		if isNullable && !isOmitted {
+			if fieldSchema.Ref != "" {
+				fieldSchema = &openAPISchema{AllOf: []*openAPISchema{fieldSchema}}
+			}
+			fieldSchema.Nullable = true
+		}
+		schema.Properties[name] = fieldSchema
+		if !isOmitted {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+	return schema, nil
+}
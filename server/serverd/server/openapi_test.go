@@ -0,0 +1,59 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestOpenAPISpec(t *testing.T) {
+	router := mux.NewRouter()
+	addRoutes(router)
+
+	spec, err := buildOpenAPISpec(router)
+	if err != nil {
+		t.Fatalf("buildOpenAPISpec: %s", err)
+	}
+
+	// Every documented route must still be registered
+	for key := range routeDocs {
+		keyParts := strings.SplitN(key, " ", 2)
+		method, path := strings.ToLower(keyParts[0]), keyParts[1]
+		if _, ok := spec.Paths[path][method]; !ok {
+			t.Errorf("route %s is documented but not registered", key)
+		}
+	}
+
+	// Every schema reference must resolve to a component
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("json.Marshal: %s", err)
+	}
+	var checkRefs func(value interface{})
+	checkRefs = func(value interface{}) {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			if ref, ok := value["$ref"].(string); ok {
+				name := strings.TrimPrefix(ref, "#/components/schemas/")
+				if spec.Components.Schemas[name] == nil {
+					t.Errorf("schema %s is referenced but not defined", ref)
+				}
+			}
+			for _, child := range value {
+				checkRefs(child)
+			}
+		case []interface{}:
+			for _, child := range value {
+				checkRefs(child)
+			}
+		}
+	}
+	var specValue interface{}
+	err = json.Unmarshal(specJSON, &specValue)
+	if err != nil {
+		t.Fatalf("json.Unmarshal: %s", err)
+	}
+	checkRefs(specValue)
+}
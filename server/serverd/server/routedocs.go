@@ -0,0 +1,310 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/serverd/controllers"
+	"github.com/someone235/katnip/server/serverd/stream"
+)
+
+// routeDoc documents a route for the OpenAPI specification.
+// responses holds values of the types that the route may respond
+// with, and paginated() values for apimodels.PaginatedResponse.
+// TestRouteDocsResponses checks them against what handlers return.
+type routeDoc struct {
+	operationID string
+	summary     string
+	queryParams []string
+	headers     []string
+	requestBody interface{}
+	responses   []interface{}
+
+	// successCode and contentType default to
+	// 200 and application/json respectively
+	successCode int
+	contentType string
+}
+
+type queryParamDoc struct {
+	description string
+	schema      *openAPISchema
+}
+
+var (
+	stringSchema  = &openAPISchema{Type: "string"}
+	integerSchema = &openAPISchema{Type: "integer", Format: "int64"}
+	booleanSchema = &openAPISchema{Type: "boolean"}
+)
+
+var (
+	directionSchema = stringEnumSchema(string(dbaccess.TransactionDirectionIncoming),
+		string(dbaccess.TransactionDirectionOutgoing))
+	addressHistoryFormatSchema = stringEnumSchema(controllers.AddressHistoryFormatCSV,
+		controllers.AddressHistoryFormatJSON)
+	balanceHistoryIntervalSchema = stringEnumSchema(controllers.BalanceHistoryIntervalHour,
+		controllers.BalanceHistoryIntervalDay, controllers.BalanceHistoryIntervalWeek,
+		controllers.BalanceHistoryIntervalMonth)
+)
+
+// stringEnumSchema returns the schema of a string that must be one of the given values
+func stringEnumSchema(values ...string) *openAPISchema {
+	return &openAPISchema{Type: "string", Enum: values}
+}
+
+var routeParamDescriptions = map[string]string{
+	routeParamTxID:      "The transaction ID",
+	routeParamTxHash:    "The transaction hash",
+	routeParamAddress:   "The address",
+	routeParamBlockHash: "The block hash",
+	routeParamWebhookID: "The webhook subscription ID",
+}
+
+var queryParamDocs = map[string]*queryParamDoc{
+	queryParamSkip:  {"The number of items to skip", integerSchema},
+	queryParamLimit: {"The maximum number of items to return", integerSchema},
+	queryParamOrder: {"The order of the items: asc or desc", stringSchema},
+	queryParamDepth: {"The number of parent or child edges to walk", integerSchema},
+	queryParamCursor: {"Switches to cursor pagination and fetches the page of the given cursor. " +
+		"An empty cursor fetches the first page", stringSchema},
+	queryParamFromBlueScore: {"The lowest blue score to include", integerSchema},
+	queryParamToBlueScore:   {"The highest blue score to include", integerSchema},
+	queryParamFromTime:      {"The earliest unix time in seconds to include", integerSchema},
+	queryParamToTime:        {"The latest unix time in seconds to include", integerSchema},
+	queryParamDirection:     {"Selects only incoming or outgoing transactions", directionSchema},
+	queryParamAcceptedOnly:  {"Selects only accepted transactions", booleanSchema},
+	queryParamSearchQuery:   {"A block hash, transaction ID or hash, address, or a prefix of them", stringSchema},
+	queryParamTopics:        {"A comma-separated list of the topics to subscribe to", stringSchema},
+	queryParamAddress:       {"Selects only the transactions of the given address", stringSchema},
+	queryParamMinValue:      {"Selects only the transactions worth at least the given value", integerSchema},
+	queryParamFormat:        {"The format of the export", addressHistoryFormatSchema},
+	queryParamFrom:          {"The unix time in seconds to start from", integerSchema},
+	queryParamTo:            {"The unix time in seconds to end at", integerSchema},
+	queryParamAtBlueScore:   {"The blue score to return the balance as of. Defaults to the selected tip", integerSchema},
+	queryParamInterval:      {"The interval between balances", balanceHistoryIntervalSchema},
+
+	"query":         {"The GraphQL query", stringSchema},
+	"operationName": {"The name of the GraphQL operation to execute", stringSchema},
+	"variables":     {"The json-formatted GraphQL variables", stringSchema},
+}
+
+var (
+	transactionsByAddressQueryParams = []string{queryParamSkip, queryParamLimit, queryParamOrder, queryParamCursor,
+		queryParamFromBlueScore, queryParamToBlueScore, queryParamFromTime, queryParamToTime, queryParamDirection,
+		queryParamAcceptedOnly, queryParamMinValue}
+	streamQueryParams = []string{queryParamTopics, queryParamAddress, queryParamMinValue}
+)
+
+// graphqlResponse is a json representation of a GraphQL response
+type graphqlResponse struct {
+	Data   map[string]interface{} `json:"data,omitempty"`
+	Errors []*struct {
+		Message string   `json:"message"`
+		Path    []string `json:"path,omitempty"`
+	} `json:"errors,omitempty"`
+}
+
+// routeDocs documents the routes that are registered by addRoutes,
+// keyed by their method and path template
+var routeDocs = map[string]*routeDoc{
+	"GET /": {
+		operationID: "getStatus",
+		summary:     "Reports that the server is running",
+		responses:   []interface{}{&statusResponse{}},
+	},
+	"GET /health/live": {
+		operationID: "getLiveness",
+		summary:     "Reports that the server is running, for liveness probes",
+		responses:   []interface{}{&statusResponse{}},
+	},
+	"GET /health/ready": {
+		operationID: "getReadiness",
+		summary: "Reports whether the database is available and synced with the node, " +
+			"for readiness probes. Responds with 503 if it's not",
+		responses: []interface{}{&statusResponse{}},
+	},
+	"GET /sync-status": {
+		operationID: "getSyncStatus",
+		summary:     "Returns how far the database is synced with the node",
+		responses:   []interface{}{&apimodels.SyncStatusResponse{}},
+	},
+	"GET /openapi.json": {
+		operationID: "getOpenAPISpec",
+		summary:     "Returns the OpenAPI specification of this API",
+		responses:   []interface{}{map[string]interface{}{}},
+	},
+	"POST /transaction": {
+		operationID: "postTransaction",
+		summary:     "Relays a serialized transaction to the node",
+		requestBody: &apimodels.RawTransaction{},
+		responses:   []interface{}{&apimodels.SubmitTransactionResponse{}},
+	},
+	"GET /transaction/id/{txID}": {
+		operationID: "getTransactionByID",
+		summary:     "Returns the transaction with the given ID",
+		responses:   []interface{}{&apimodels.TransactionResponse{}},
+	},
+	"GET /transaction/hash/{txHash}": {
+		operationID: "getTransactionByHash",
+		summary:     "Returns the transaction with the given hash",
+		responses:   []interface{}{&apimodels.TransactionResponse{}},
+	},
+	"GET /transactions/address/{address}": {
+		operationID: "getTransactionsByAddress",
+		summary:     "Returns the transactions of the given address, or a page of them if cursor is set",
+		queryParams: transactionsByAddressQueryParams,
+		responses: []interface{}{
+			[]*apimodels.TransactionResponse{},
+			paginated([]*apimodels.TransactionResponse{}),
+		},
+	},
+	"POST /transactions/addresses": {
+		operationID: "postTransactionsByAddresses",
+		summary:     "Returns a page of the transactions of the given addresses, grouped by address",
+		queryParams: []string{queryParamLimit, queryParamCursor},
+		requestBody: &apimodels.AddressesRequest{},
+		responses:   []interface{}{paginated([]*apimodels.AddressTransactionsResponse{})},
+	},
+	"GET /address/{address}/export": {
+		operationID: "exportAddressHistory",
+		summary: "Exports the accepted transactions of the given address with their net amounts, fees and " +
+			"running balances, as CSV or as a JSON array",
+		queryParams: []string{queryParamFormat, queryParamFrom, queryParamTo},
+		responses:   []interface{}{[]*apimodels.AddressHistoryEntryResponse{}},
+	},
+	"GET /address/{address}/balance": {
+		operationID: "getAddressBalance",
+		summary:     "Returns the balance of the given address as of the given blue score",
+		queryParams: []string{queryParamAtBlueScore},
+		responses:   []interface{}{&apimodels.AddressBalanceResponse{}},
+	},
+	"GET /address/{address}/balance-history": {
+		operationID: "getAddressBalanceHistory",
+		summary:     "Returns the balances of the given address at the boundaries of the given interval",
+		queryParams: []string{queryParamInterval, queryParamFrom, queryParamTo},
+		responses:   []interface{}{&apimodels.AddressBalanceHistoryResponse{}},
+	},
+	"GET /transactions/address/{address}/count": {
+		operationID: "getTransactionCountByAddress",
+		summary:     "Returns the number of transactions of the given address",
+		responses:   []interface{}{uint64(0)},
+	},
+	"GET /transactions/block/{blockHash}": {
+		operationID: "getTransactionsByBlockHash",
+		summary:     "Returns the transactions included in the given block",
+		responses:   []interface{}{&apimodels.TransactionsResponse{}},
+	},
+	"GET /transaction/id/{txID}/doublespends": {
+		operationID: "getTransactionDoubleSpends",
+		summary:     "Returns the transactions that spend any of the inputs of the given transaction",
+		responses:   []interface{}{&apimodels.TransactionDoubleSpendsResponse{}},
+	},
+	"GET /utxos/address/{address}": {
+		operationID: "getUTXOsByAddress",
+		summary:     "Returns the UTXOs of the given address",
+		responses:   []interface{}{[]*apimodels.TransactionOutputResponse{}},
+	},
+	"POST /utxos/addresses": {
+		operationID: "postUTXOsByAddresses",
+		summary:     "Returns a page of the UTXOs of the given addresses, grouped by address",
+		queryParams: []string{queryParamLimit, queryParamCursor},
+		requestBody: &apimodels.AddressesRequest{},
+		responses:   []interface{}{paginated([]*apimodels.AddressUTXOsResponse{})},
+	},
+	"GET /block/{blockHash}": {
+		operationID: "getBlockByHash",
+		summary:     "Returns the block with the given hash",
+		responses:   []interface{}{&apimodels.BlockResponse{}},
+	},
+	"GET /block/{blockHash}/children": {
+		operationID: "getBlockChildren",
+		summary:     "Returns the children of the given block",
+		responses:   []interface{}{[]*apimodels.BlockResponse{}},
+	},
+	"GET /block/{blockHash}/neighbourhood": {
+		operationID: "getBlockNeighbourhood",
+		summary:     "Returns the blocks up to depth parent or child edges away from the given block",
+		queryParams: []string{queryParamDepth},
+		responses:   []interface{}{&apimodels.BlockNeighbourhoodResponse{}},
+	},
+	"GET /blocks": {
+		operationID: "getBlocks",
+		summary:     "Returns the blocks, or a page of them if cursor is set",
+		queryParams: []string{queryParamSkip, queryParamLimit, queryParamOrder, queryParamCursor},
+		responses: []interface{}{
+			[]*apimodels.BlockResponse{},
+			paginated([]*apimodels.BlockResponse{}),
+		},
+	},
+	"GET /blocks/count": {
+		operationID: "getBlockCount",
+		summary:     "Returns the number of blocks",
+		responses:   []interface{}{uint64(0)},
+	},
+	"GET /dag": {
+		operationID: "getDAG",
+		summary:     "Returns the blocks and edges of the DAG between the given blue scores",
+		queryParams: []string{queryParamFromBlueScore, queryParamToBlueScore},
+		responses:   []interface{}{&apimodels.DAGResponse{}},
+	},
+	"POST /webhooks": {
+		operationID: "postWebhookSubscription",
+		summary:     "Subscribes a webhook to the events of an address or a transaction",
+		requestBody: &apimodels.WebhookSubscriptionRequest{},
+		responses:   []interface{}{&apimodels.WebhookSubscriptionResponse{}},
+	},
+	"GET /webhooks/{webhookID}": {
+		operationID: "getWebhookSubscription",
+		summary:     "Returns the given webhook subscription",
+		headers:     []string{webhookSecretHeader},
+		responses:   []interface{}{&apimodels.WebhookSubscriptionResponse{}},
+	},
+	"DELETE /webhooks/{webhookID}": {
+		operationID: "deleteWebhookSubscription",
+		summary:     "Deletes the given webhook subscription",
+		headers:     []string{webhookSecretHeader},
+		responses:   []interface{}{&apimodels.WebhookSubscriptionResponse{}},
+	},
+	"GET /stream": {
+		operationID: "getStream",
+		summary:     "Streams the events of the given topics as server-sent events",
+		queryParams: streamQueryParams,
+		responses:   []interface{}{&stream.Event{}},
+		contentType: "text/event-stream",
+	},
+	"GET /ws": {
+		operationID: "getWebSocketStream",
+		summary:     "Streams the events of the given topics over a WebSocket",
+		queryParams: streamQueryParams,
+		successCode: http.StatusSwitchingProtocols,
+	},
+	"GET /search": {
+		operationID: "getSearch",
+		summary:     "Returns the blocks, transactions and addresses that match the given query",
+		queryParams: []string{queryParamSearchQuery},
+		responses:   []interface{}{&apimodels.SearchResponse{}},
+	},
+	"GET /fee-estimates": {
+		operationID: "getFeeEstimates",
+		summary:     "Returns the fee estimates for different priorities",
+		responses:   []interface{}{&apimodels.FeeEstimateResponse{}},
+	},
+	"GET /graphql": {
+		operationID: "getGraphQL",
+		summary: "Executes the given GraphQL query. Requests that upgrade to a WebSocket " +
+			"run GraphQL subscriptions using the graphql-ws protocol",
+		queryParams: []string{"query", "operationName", "variables"},
+		responses:   []interface{}{&graphqlResponse{}},
+	},
+	"POST /graphql": {
+		operationID: "postGraphQL",
+		summary:     "Executes the GraphQL query of the request body",
+		requestBody: &struct {
+			Query         string                 `json:"query"`
+			OperationName string                 `json:"operationName,omitempty"`
+			Variables     map[string]interface{} `json:"variables,omitempty"`
+		}{},
+		responses: []interface{}{&graphqlResponse{}},
+	},
+}
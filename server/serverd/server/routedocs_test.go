@@ -0,0 +1,469 @@
+package server
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// routesWithForeignResponses are the routes whose handlers respond with
+// types from outside of this module, which their documented types mirror
+var routesWithForeignResponses = map[string]bool{
+	"GET /graphql":  true,
+	"POST /graphql": true,
+}
+
+// TestRouteDocsResponses checks that the documented responses of every
+// route that's made by MakeHandler cover the types its handler returns,
+// as read from the sources of the handler and the controllers it calls
+func TestRouteDocsResponses(t *testing.T) {
+	packagePath := reflect.TypeOf(statusResponse{}).PkgPath()
+	checker := newHandlerResponseChecker(strings.TrimSuffix(packagePath, "/serverd/server"), filepath.Join("..", ".."))
+	_, files, err := checker.check(packagePath, ".")
+	if err != nil {
+		t.Fatalf("check: %s", err)
+	}
+
+	var addRoutesDecl *ast.FuncDecl
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Name.Name == "addRoutes" {
+				addRoutesDecl = funcDecl
+			}
+		}
+	}
+	if addRoutesDecl == nil {
+		t.Fatalf("addRoutes was not found")
+	}
+	handlers, err := checker.registeredHandlers(addRoutesDecl)
+	if err != nil {
+		t.Fatalf("registeredHandlers: %s", err)
+	}
+	if len(handlers) == 0 {
+		t.Fatalf("no handlers were found in addRoutes")
+	}
+
+	for key, handler := range handlers {
+		if routesWithForeignResponses[key] {
+			continue
+		}
+		doc, ok := routeDocs[key]
+		if !ok {
+			t.Errorf("route %s is not documented", key)
+			continue
+		}
+		documentedTypes := make(map[string]bool, len(doc.responses))
+		for _, response := range doc.responses {
+			documentedTypes[documentedTypeString(response)] = true
+		}
+
+		responseTypes, err := checker.responseTypes(handler)
+		if err != nil {
+			t.Errorf("route %s: %s", key, err)
+			continue
+		}
+		for _, responseType := range responseTypes {
+			if !documentedTypes[responseType] {
+				t.Errorf("route %s responds with %s, which is not in its documented responses", key, responseType)
+			}
+		}
+	}
+}
+
+// documentedTypeString returns the name of the type of the given documented
+// response, in the format of typeString
+func documentedTypeString(response interface{}) string {
+	if page, ok := response.(paginatedResponse); ok {
+		return fmt.Sprintf("paginated(%s)", documentedTypeString(page.items))
+	}
+	responseType := reflect.TypeOf(response)
+	for responseType.Kind() == reflect.Ptr {
+		responseType = responseType.Elem()
+	}
+	return responseType.String()
+}
+
+// handlerResponseChecker type-checks the sources of this module, so that the
+// types that handlers respond with can be read from the handlers themselves.
+// Packages from outside of the module are replaced with empty ones, and the
+// type errors that follow are ignored, since only the types of the module's
+// own response models matter.
+type handlerResponseChecker struct {
+	modulePath string
+	moduleDir  string
+	fset       *token.FileSet
+	info       *types.Info
+	std        types.Importer
+	packages   map[string]*types.Package
+	funcDecls  map[*types.Func]*ast.FuncDecl
+}
+
+func newHandlerResponseChecker(modulePath string, moduleDir string) *handlerResponseChecker {
+	fset := token.NewFileSet()
+	return &handlerResponseChecker{
+		modulePath: modulePath,
+		moduleDir:  moduleDir,
+		fset:       fset,
+		info: &types.Info{
+			Types: make(map[ast.Expr]types.TypeAndValue),
+			Defs:  make(map[*ast.Ident]types.Object),
+			Uses:  make(map[*ast.Ident]types.Object),
+		},
+		std:       importer.ForCompiler(fset, "source", nil),
+		packages:  make(map[string]*types.Package),
+		funcDecls: make(map[*types.Func]*ast.FuncDecl),
+	}
+}
+
+// Import implements types.Importer
+func (c *handlerResponseChecker) Import(path string) (*types.Package, error) {
+	if pkg, ok := c.packages[path]; ok {
+		return pkg, nil
+	}
+
+	var pkg *types.Package
+	switch {
+	case path == c.modulePath || strings.HasPrefix(path, c.modulePath+"/"):
+		dir := filepath.Join(c.moduleDir, strings.TrimPrefix(path, c.modulePath))
+		checkedPkg, _, err := c.check(path, dir)
+		if err != nil {
+			return nil, err
+		}
+		pkg = checkedPkg
+	case !strings.Contains(strings.Split(path, "/")[0], "."):
+		stdPkg, err := c.std.Import(path)
+		if err != nil {
+			return nil, err
+		}
+		pkg = stdPkg
+	default:
+		pkg = types.NewPackage(path, filepath.Base(path))
+		pkg.MarkComplete()
+	}
+	c.packages[path] = pkg
+	return pkg, nil
+}
+
+// check type-checks the non-test sources of the package in dir
+func (c *handlerResponseChecker) check(path string, dir string) (*types.Package, []*ast.File, error) {
+	parsedPackages, err := parser.ParseDir(c.fset, dir, func(fileInfo os.FileInfo) bool {
+		return !strings.HasSuffix(fileInfo.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var files []*ast.File
+	for _, parsedPackage := range parsedPackages {
+		for _, file := range parsedPackage.Files {
+			files = append(files, file)
+		}
+	}
+
+	config := &types.Config{Importer: c, Error: func(error) {}}
+	pkg, _ := config.Check(path, c.fset, files, c.info)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Body != nil {
+				if function, ok := c.info.Defs[funcDecl.Name].(*types.Func); ok {
+					c.funcDecls[function] = funcDecl
+				}
+			}
+		}
+	}
+	return pkg, files, nil
+}
+
+// registeredHandlers returns the handlers that addRoutes registers with
+// MakeHandler, keyed by their routeDocs keys. Handlers that aren't made
+// by MakeHandler write their responses themselves and are not returned.
+func (c *handlerResponseChecker) registeredHandlers(addRoutes *ast.FuncDecl) (map[string]*types.Func, error) {
+	handlers := make(map[string]*types.Func)
+	for _, stmt := range addRoutes.Body.List {
+		exprStmt, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+
+		var methods []string
+		var path string
+		var handler *types.Func
+		call, _ := exprStmt.X.(*ast.CallExpr)
+		for call != nil {
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				break
+			}
+			switch selector.Sel.Name {
+			case "Methods":
+				for _, arg := range call.Args {
+					method, err := c.stringValue(arg)
+					if err != nil {
+						return nil, err
+					}
+					methods = append(methods, method)
+				}
+			case "HandleFunc":
+				var err error
+				path, err = c.stringValue(call.Args[0])
+				if err != nil {
+					return nil, err
+				}
+				handler = c.madeHandler(call.Args[1])
+			}
+			call, _ = selector.X.(*ast.CallExpr)
+		}
+
+		if handler == nil {
+			continue
+		}
+		if len(methods) == 0 {
+			methods = []string{"GET"}
+		}
+		for _, method := range methods {
+			handlers[routeDocKey(method, path)] = handler
+		}
+	}
+	return handlers, nil
+}
+
+// stringValue returns the value of a constant string expression,
+// or of a call to fmt.Sprintf with constant arguments
+func (c *handlerResponseChecker) stringValue(expr ast.Expr) (string, error) {
+	if value := c.info.Types[expr].Value; value != nil && value.Kind() == constant.String {
+		return constant.StringVal(value), nil
+	}
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || c.calledFunc(call) == nil || c.calledFunc(call).FullName() != "fmt.Sprintf" {
+		return "", errors.Errorf("%s is not a constant string", c.fset.Position(expr.Pos()))
+	}
+	format, err := c.stringValue(call.Args[0])
+	if err != nil {
+		return "", err
+	}
+	args := make([]interface{}, len(call.Args)-1)
+	for i, arg := range call.Args[1:] {
+		args[i], err = c.stringValue(arg)
+		if err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf(format, args...), nil
+}
+
+// madeHandler returns the function that the given
+// handler is made of, if it's made by MakeHandler
+func (c *handlerResponseChecker) madeHandler(handler ast.Expr) *types.Func {
+	call, ok := handler.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	if maker := c.calledFunc(call); maker == nil || maker.Name() != "MakeHandler" {
+		return nil
+	}
+	ident, ok := call.Args[0].(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	function, _ := c.info.Uses[ident].(*types.Func)
+	return function
+}
+
+func (c *handlerResponseChecker) calledFunc(call *ast.CallExpr) *types.Func {
+	var ident *ast.Ident
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return nil
+	}
+	function, _ := c.info.Uses[ident].(*types.Func)
+	return function
+}
+
+// responseTypes returns the types of the responses that the given function
+// returns, following the functions whose responses it returns as they are
+func (c *handlerResponseChecker) responseTypes(function *types.Func) ([]string, error) {
+	funcDecl, ok := c.funcDecls[function]
+	if !ok {
+		return nil, errors.Errorf("%s is not declared in this module", function.FullName())
+	}
+
+	var responseTypes []string
+	var err error
+	ast.Inspect(funcDecl.Body, func(node ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if len(node.Results) == 0 {
+				return false
+			}
+			var types []string
+			types, err = c.expressionResponseTypes(funcDecl, node.Results[0])
+			responseTypes = append(responseTypes, types...)
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return responseTypes, nil
+}
+
+// expressionResponseTypes returns the types of the responses
+// that the given expression of the given function may evaluate to
+func (c *handlerResponseChecker) expressionResponseTypes(funcDecl *ast.FuncDecl, expr ast.Expr) ([]string, error) {
+	for {
+		paren, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		expr = paren.X
+	}
+
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if expr.Name == "nil" {
+			return nil, nil
+		}
+		if isResponseWrapper(c.info.TypeOf(expr)) {
+			return c.assignedResponseTypes(funcDecl, c.info.Uses[expr])
+		}
+	case *ast.UnaryExpr:
+		if literal, ok := expr.X.(*ast.CompositeLit); ok && expr.Op == token.AND {
+			return c.literalResponseTypes(funcDecl, literal)
+		}
+	case *ast.CompositeLit:
+		return c.literalResponseTypes(funcDecl, expr)
+	case *ast.CallExpr:
+		callee := c.calledFunc(expr)
+		if _, ok := c.funcDecls[callee]; !ok || callee.Type().(*types.Signature).Results().Len() == 0 {
+			break
+		}
+		resultType := callee.Type().(*types.Signature).Results().At(0).Type()
+		if isEmptyInterface(resultType) {
+			return c.responseTypes(callee)
+		}
+		if isResponseWrapper(resultType) {
+			// Functions that return a CacheableResponse wrap their first argument
+			return c.expressionResponseTypes(funcDecl, expr.Args[0])
+		}
+	}
+
+	responseType := c.info.TypeOf(expr)
+	if tuple, ok := responseType.(*types.Tuple); ok {
+		// The response and the error of a call that's returned as is
+		responseType = tuple.At(0).Type()
+	}
+	if responseType == nil || responseType == types.Typ[types.Invalid] || isEmptyInterface(responseType) {
+		return nil, errors.Errorf("%s: the type of the response can't be told", c.fset.Position(expr.Pos()))
+	}
+	return []string{typeString(responseType)}, nil
+}
+
+// literalResponseTypes returns the types of the responses of the given
+// literal, looking into the CacheableResponses and PaginatedResponses
+func (c *handlerResponseChecker) literalResponseTypes(funcDecl *ast.FuncDecl, literal *ast.CompositeLit) ([]string, error) {
+	literalType := typeString(c.info.TypeOf(literal))
+	var wrappedField string
+	switch literalType {
+	case "httpserverutils.CacheableResponse":
+		wrappedField = "Response"
+	case "apimodels.PaginatedResponse":
+		wrappedField = "Items"
+	default:
+		return []string{literalType}, nil
+	}
+
+	for _, element := range literal.Elts {
+		keyValue, ok := element.(*ast.KeyValueExpr)
+		if !ok || keyValue.Key.(*ast.Ident).Name != wrappedField {
+			continue
+		}
+		wrappedTypes, err := c.expressionResponseTypes(funcDecl, keyValue.Value)
+		if err != nil {
+			return nil, err
+		}
+		if wrappedField == "Response" {
+			return wrappedTypes, nil
+		}
+		responseTypes := make([]string, len(wrappedTypes))
+		for i, wrappedType := range wrappedTypes {
+			responseTypes[i] = fmt.Sprintf("paginated(%s)", wrappedType)
+		}
+		return responseTypes, nil
+	}
+	return nil, errors.Errorf("%s: %s has no %s", c.fset.Position(literal.Pos()), literalType, wrappedField)
+}
+
+// assignedResponseTypes returns the types of the
+// responses that are assigned to the given variable
+func (c *handlerResponseChecker) assignedResponseTypes(funcDecl *ast.FuncDecl, variable types.Object) ([]string, error) {
+	var responseTypes []string
+	var err error
+	ast.Inspect(funcDecl.Body, func(node ast.Node) bool {
+		assign, ok := node.(*ast.AssignStmt)
+		if !ok || err != nil || len(assign.Lhs) != len(assign.Rhs) {
+			return err == nil
+		}
+		for i, lhs := range assign.Lhs {
+			ident, ok := lhs.(*ast.Ident)
+			if !ok || (c.info.Defs[ident] != variable && c.info.Uses[ident] != variable) {
+				continue
+			}
+			var types []string
+			types, err = c.expressionResponseTypes(funcDecl, assign.Rhs[i])
+			responseTypes = append(responseTypes, types...)
+		}
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(responseTypes) == 0 {
+		return nil, errors.Errorf("%s: no response is assigned to %s", c.fset.Position(variable.Pos()), variable.Name())
+	}
+	return responseTypes, nil
+}
+
+// isResponseWrapper returns whether values of the given type
+// wrap a response of a type that has to be looked into
+func isResponseWrapper(t types.Type) bool {
+	return isEmptyInterface(t) || typeString(t) == "httpserverutils.CacheableResponse"
+}
+
+func isEmptyInterface(t types.Type) bool {
+	iface, ok := t.Underlying().(*types.Interface)
+	return ok && iface.Empty()
+}
+
+// typeString returns the name of the given type, qualified by package
+// names, as reflect names it. Pointers are dropped, since they
+// have the schema of the types they point to.
+func typeString(t types.Type) string {
+	for {
+		pointer, ok := t.(*types.Pointer)
+		if !ok {
+			break
+		}
+		t = pointer.Elem()
+	}
+	return types.TypeString(t, func(pkg *types.Package) string { return pkg.Name() })
+}
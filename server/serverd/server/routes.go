@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/someone235/katnip/server/dbaccess"
 	"github.com/someone235/katnip/server/httpserverutils"
 	"github.com/someone235/katnip/server/serverd/controllers"
+	"github.com/someone235/katnip/server/serverd/graphql"
+	"github.com/someone235/katnip/server/serverd/stream"
 
 	"github.com/gorilla/mux"
 )
@@ -18,31 +21,110 @@ const (
 	routeParamTxHash    = "txHash"
 	routeParamAddress   = "address"
 	routeParamBlockHash = "blockHash"
+	routeParamWebhookID = "webhookID"
 )
 
+// webhookSecretHeader is the request header that holds the
+// secret of the webhook subscription that is being accessed
+const webhookSecretHeader = "X-Webhook-Secret"
+
 const (
 	queryParamSkip  = "skip"
 	queryParamLimit = "limit"
 	queryParamOrder = "order"
+	queryParamDepth = "depth"
+
+	// queryParamCursor switches list endpoints from skip/limit pagination
+	// to cursor pagination. An empty cursor fetches the first page.
+	queryParamCursor = "cursor"
+
+	queryParamFromBlueScore = "fromBlueScore"
+	queryParamToBlueScore   = "toBlueScore"
+
+	queryParamFromTime     = "fromTime"
+	queryParamToTime       = "toTime"
+	queryParamDirection    = "direction"
+	queryParamAcceptedOnly = "acceptedOnly"
+
+	queryParamSearchQuery = "q"
+
+	queryParamTopics   = "topics"
+	queryParamAddress  = "address"
+	queryParamMinValue = "minValue"
+
+	queryParamFormat = "format"
+	queryParamFrom   = "from"
+	queryParamTo     = "to"
+
+	queryParamAtBlueScore = "atBlueScore"
+	queryParamInterval    = "interval"
 )
 
 const (
-	defaultGetTransactionsLimit = 100
-	defaultGetBlocksLimit       = 25
-	defaultGetBlocksOrder       = string(dbaccess.OrderDescending)
+	defaultGetTransactionsLimit    = 100
+	defaultGetBlocksLimit          = 25
+	defaultGetUTXOsLimit           = 1000
+	defaultGetBlocksOrder          = string(dbaccess.OrderDescending)
+	defaultGetTransactionsOrder    = string(dbaccess.OrderAscending)
+	defaultBlockNeighbourhoodDepth = 1
+	defaultBalanceHistoryInterval  = controllers.BalanceHistoryIntervalDay
 )
 
+// statusResponse is a json representation of the status of the server
+type statusResponse struct {
+	Message string `json:"message"`
+}
+
 func mainHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string, _ []byte) (interface{}, error) {
-	return struct {
-		Message string `json:"message"`
-	}{
+	return &statusResponse{
 		Message: "Katnip server is running",
 	}, nil
 }
 
+func liveHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string, _ []byte) (interface{}, error) {
+	return &statusResponse{
+		Message: "Katnip server is live",
+	}, nil
+}
+
+func readyHandler(ctx *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string, _ []byte) (interface{}, error) {
+	err := controllers.GetReadinessHandler(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &statusResponse{
+		Message: "Katnip server is ready",
+	}, nil
+}
+
 func addRoutes(router *mux.Router) {
 	router.HandleFunc("/", httpserverutils.MakeHandler(mainHandler))
 
+	router.HandleFunc(
+		"/health/live",
+		httpserverutils.MakeHandler(liveHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		"/health/ready",
+		httpserverutils.MakeHandler(readyHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		"/sync-status",
+		httpserverutils.MakeHandler(getSyncStatusHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		"/openapi.json",
+		httpserverutils.MakeHandler(makeOpenAPIHandler(router))).
+		Methods("GET")
+
+	router.HandleFunc(
+		"/transaction",
+		httpserverutils.MakeHandler(postTransactionHandler)).
+		Methods("POST")
+
 	router.HandleFunc(
 		fmt.Sprintf("/transaction/id/{%s}", routeParamTxID),
 		httpserverutils.MakeHandler(getTransactionByIDHandler)).
@@ -58,6 +140,11 @@ func addRoutes(router *mux.Router) {
 		httpserverutils.MakeHandler(getTransactionsByAddressHandler)).
 		Methods("GET")
 
+	router.HandleFunc(
+		"/transactions/addresses",
+		httpserverutils.MakeHandler(postTransactionsByAddressesHandler)).
+		Methods("POST")
+
 	router.HandleFunc(
 		fmt.Sprintf("/transactions/address/{%s}/count", routeParamAddress),
 		httpserverutils.MakeHandler(getTransactionCountByAddressHandler)).
@@ -73,16 +160,46 @@ func addRoutes(router *mux.Router) {
 		httpserverutils.MakeHandler(getTransactionDoubleSpendsHandler)).
 		Methods("GET")
 
+	router.HandleFunc(
+		fmt.Sprintf("/address/{%s}/export", routeParamAddress),
+		exportAddressHistoryHandler).
+		Methods("GET")
+
+	router.HandleFunc(
+		fmt.Sprintf("/address/{%s}/balance", routeParamAddress),
+		httpserverutils.MakeHandler(getAddressBalanceHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		fmt.Sprintf("/address/{%s}/balance-history", routeParamAddress),
+		httpserverutils.MakeHandler(getAddressBalanceHistoryHandler)).
+		Methods("GET")
+
 	router.HandleFunc(
 		fmt.Sprintf("/utxos/address/{%s}", routeParamAddress),
 		httpserverutils.MakeHandler(getUTXOsByAddressHandler)).
 		Methods("GET")
 
+	router.HandleFunc(
+		"/utxos/addresses",
+		httpserverutils.MakeHandler(postUTXOsByAddressesHandler)).
+		Methods("POST")
+
 	router.HandleFunc(
 		fmt.Sprintf("/block/{%s}", routeParamBlockHash),
 		httpserverutils.MakeHandler(getBlockByHashHandler)).
 		Methods("GET")
 
+	router.HandleFunc(
+		fmt.Sprintf("/block/{%s}/children", routeParamBlockHash),
+		httpserverutils.MakeHandler(getBlockChildrenHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		fmt.Sprintf("/block/{%s}/neighbourhood", routeParamBlockHash),
+		httpserverutils.MakeHandler(getBlockNeighbourhoodHandler)).
+		Methods("GET")
+
 	router.HandleFunc(
 		"/blocks",
 		httpserverutils.MakeHandler(getBlocksHandler)).
@@ -93,10 +210,58 @@ func addRoutes(router *mux.Router) {
 		httpserverutils.MakeHandler(getBlockCountHandler)).
 		Methods("GET")
 
+	router.HandleFunc(
+		"/dag",
+		httpserverutils.MakeHandler(getDAGHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		"/webhooks",
+		httpserverutils.MakeHandler(postWebhookSubscriptionHandler)).
+		Methods("POST")
+
+	router.HandleFunc(
+		fmt.Sprintf("/webhooks/{%s}", routeParamWebhookID),
+		httpserverutils.MakeHandler(getWebhookSubscriptionHandler)).
+		Methods("GET")
+
+	router.HandleFunc(
+		fmt.Sprintf("/webhooks/{%s}", routeParamWebhookID),
+		httpserverutils.MakeHandler(deleteWebhookSubscriptionHandler)).
+		Methods("DELETE")
+
+	router.HandleFunc(
+		"/stream",
+		streamHandler).
+		Methods("GET")
+
+	router.HandleFunc(
+		"/ws",
+		webSocketHandler).
+		Methods("GET")
+
+	router.HandleFunc(
+		"/search",
+		httpserverutils.MakeHandler(getSearchHandler)).
+		Methods("GET")
+
 	router.HandleFunc(
 		"/fee-estimates",
 		httpserverutils.MakeHandler(getFeeEstimatesHandler)).
 		Methods("GET")
+
+	// GraphQL subscriptions are served over a WebSocket
+	// on the same path as queries
+	router.HandleFunc(
+		"/graphql",
+		graphql.ServeWebSocket).
+		Methods("GET").
+		Headers("Upgrade", "websocket")
+
+	router.HandleFunc(
+		"/graphql",
+		httpserverutils.MakeHandler(graphqlHandler)).
+		Methods("GET", "POST")
 }
 
 func convertQueryParamToInt64(queryParams map[string]string, param string, defaultValue int64) (int64, error) {
@@ -114,74 +279,254 @@ func convertQueryParamToInt64(queryParams map[string]string, param string, defau
 	return defaultValue, nil
 }
 
-func getTransactionByIDHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
+func convertOptionalQueryParamToUint64(queryParams map[string]string, param string) (*uint64, error) {
+	if _, ok := queryParams[param]; !ok {
+		return nil, nil
+	}
+	uint64Value, err := strconv.ParseUint(queryParams[param], 10, 64)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Couldn't parse the '%s' query parameter", param)
+		return nil, httpserverutils.NewHandlerErrorWithCustomClientMessage(
+			http.StatusUnprocessableEntity,
+			errors.Wrap(err, errorMessage),
+			errorMessage)
+	}
+	return &uint64Value, nil
+}
+
+func convertQueryParamToBool(queryParams map[string]string, param string, defaultValue bool) (bool, error) {
+	if _, ok := queryParams[param]; !ok {
+		return defaultValue, nil
+	}
+	boolValue, err := strconv.ParseBool(queryParams[param])
+	if err != nil {
+		errorMessage := fmt.Sprintf("Couldn't parse the '%s' query parameter", param)
+		return false, httpserverutils.NewHandlerErrorWithCustomClientMessage(
+			http.StatusUnprocessableEntity,
+			errors.Wrap(err, errorMessage),
+			errorMessage)
+	}
+	return boolValue, nil
+}
+
+// convertOptionalQueryParamToTime parses a query parameter of unix time in seconds
+func convertOptionalQueryParamToTime(queryParams map[string]string, param string) (*time.Time, error) {
+	unixTime, err := convertOptionalQueryParamToUint64(queryParams, param)
+	if err != nil || unixTime == nil {
+		return nil, err
+	}
+	timeValue := time.Unix(int64(*unixTime), 0).UTC()
+	return &timeValue, nil
+}
+
+func convertRouteParamToUint64(routeParams map[string]string, param string) (uint64, error) {
+	uint64Value, err := strconv.ParseUint(routeParams[param], 10, 64)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Couldn't parse the '%s' route parameter", param)
+		return 0, httpserverutils.NewHandlerErrorWithCustomClientMessage(
+			http.StatusUnprocessableEntity,
+			errors.Wrap(err, errorMessage),
+			errorMessage)
+	}
+	return uint64Value, nil
+}
+
+func getSyncStatusHandler(ctx *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
 	_ []byte) (interface{}, error) {
 
-	return controllers.GetTransactionByIDHandler(routeParams[routeParamTxID])
+	return controllers.GetSyncStatusHandler(ctx)
 }
 
-func getTransactionByHashHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
+func getTransactionByIDHandler(ctx *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
 	_ []byte) (interface{}, error) {
 
-	return controllers.GetTransactionByHashHandler(routeParams[routeParamTxHash])
+	return controllers.GetTransactionByIDHandler(ctx, routeParams[routeParamTxID])
 }
 
-func getTransactionsByAddressHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, queryParams map[string]string,
+func getTransactionByHashHandler(ctx *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
 	_ []byte) (interface{}, error) {
 
+	return controllers.GetTransactionByHashHandler(ctx, routeParams[routeParamTxHash])
+}
+
+func postTransactionHandler(ctx *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+	requestBody []byte) (interface{}, error) {
+
+	return controllers.PostTransactionHandler(ctx, requestBody)
+}
+
+func getTransactionsByAddressHandler(ctx *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, queryParams map[string]string,
+	_ []byte) (interface{}, error) {
+
+	limit, err := convertQueryParamToInt64(queryParams, queryParamLimit, defaultGetTransactionsLimit)
+	if err != nil {
+		return nil, err
+	}
+	order := defaultGetTransactionsOrder
+	if orderParamValue, ok := queryParams[queryParamOrder]; ok {
+		order = orderParamValue
+	}
+	filter, err := addressTransactionsFilter(queryParams)
+	if err != nil {
+		return nil, err
+	}
+	if cursor, ok := queryParams[queryParamCursor]; ok {
+		return controllers.GetTransactionsByAddressPageHandler(ctx, routeParams[routeParamAddress], filter, order, cursor, limit)
+	}
 	skip, err := convertQueryParamToInt64(queryParams, queryParamSkip, 0)
 	if err != nil {
 		return nil, err
 	}
+	return controllers.GetTransactionsByAddressHandler(ctx, routeParams[routeParamAddress], filter, order, skip, limit)
+}
+
+func postTransactionsByAddressesHandler(ctx *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, queryParams map[string]string,
+	requestBody []byte) (interface{}, error) {
+
 	limit, err := convertQueryParamToInt64(queryParams, queryParamLimit, defaultGetTransactionsLimit)
 	if err != nil {
 		return nil, err
 	}
-	return controllers.GetTransactionsByAddressHandler(routeParams[routeParamAddress], skip, limit)
+	return controllers.PostTransactionsByAddressesHandler(ctx, requestBody, queryParams[queryParamCursor], limit)
+}
+
+func addressTransactionsFilter(queryParams map[string]string) (*dbaccess.AddressTransactionsFilter, error) {
+	fromBlueScore, err := convertOptionalQueryParamToUint64(queryParams, queryParamFromBlueScore)
+	if err != nil {
+		return nil, err
+	}
+	toBlueScore, err := convertOptionalQueryParamToUint64(queryParams, queryParamToBlueScore)
+	if err != nil {
+		return nil, err
+	}
+	fromTime, err := convertOptionalQueryParamToTime(queryParams, queryParamFromTime)
+	if err != nil {
+		return nil, err
+	}
+	toTime, err := convertOptionalQueryParamToTime(queryParams, queryParamToTime)
+	if err != nil {
+		return nil, err
+	}
+	acceptedOnly, err := convertQueryParamToBool(queryParams, queryParamAcceptedOnly, false)
+	if err != nil {
+		return nil, err
+	}
+	minValue, err := convertOptionalQueryParamToUint64(queryParams, queryParamMinValue)
+	if err != nil {
+		return nil, err
+	}
+	if minValue == nil {
+		minValue = new(uint64)
+	}
+	return &dbaccess.AddressTransactionsFilter{
+		FromBlueScore: fromBlueScore,
+		ToBlueScore:   toBlueScore,
+		FromTime:      fromTime,
+		ToTime:        toTime,
+		Direction:     dbaccess.TransactionDirection(queryParams[queryParamDirection]),
+		AcceptedOnly:  acceptedOnly,
+		MinValue:      *minValue,
+	}, nil
 }
 
-func getTransactionCountByAddressHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
+func getTransactionCountByAddressHandler(ctx *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
 	_ []byte) (interface{}, error) {
-	return controllers.GetTransactionCountByAddressHandler(routeParams[routeParamAddress])
+	return controllers.GetTransactionCountByAddressHandler(ctx, routeParams[routeParamAddress])
 }
 
-func getTransactionsByBlockHashHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
+func getTransactionsByBlockHashHandler(ctx *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
 	_ []byte) (interface{}, error) {
 
-	return controllers.GetTransactionsByBlockHashHandler(routeParams[routeParamBlockHash])
+	return controllers.GetTransactionsByBlockHashHandler(ctx, routeParams[routeParamBlockHash])
 }
 
-func getTransactionDoubleSpendsHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
+func getTransactionDoubleSpendsHandler(ctx *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
 	_ []byte) (interface{}, error) {
 
-	return controllers.GetTransactionDoubleSpends(routeParams[routeParamTxID])
+	return controllers.GetTransactionDoubleSpends(ctx, routeParams[routeParamTxID])
 }
 
-func getUTXOsByAddressHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
+func getUTXOsByAddressHandler(ctx *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
 	_ []byte) (interface{}, error) {
 
-	return controllers.GetUTXOsByAddressHandler(routeParams[routeParamAddress])
+	return controllers.GetUTXOsByAddressHandler(ctx, routeParams[routeParamAddress])
 }
 
-func getBlockByHashHandler(_ *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
+func postUTXOsByAddressesHandler(ctx *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, queryParams map[string]string,
+	requestBody []byte) (interface{}, error) {
+
+	limit, err := convertQueryParamToInt64(queryParams, queryParamLimit, defaultGetUTXOsLimit)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.PostUTXOsByAddressesHandler(ctx, requestBody, queryParams[queryParamCursor], limit)
+}
+
+func getAddressBalanceHandler(ctx *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, queryParams map[string]string,
 	_ []byte) (interface{}, error) {
 
-	return controllers.GetBlockByHashHandler(routeParams[routeParamBlockHash])
+	atBlueScore, err := convertOptionalQueryParamToUint64(queryParams, queryParamAtBlueScore)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.GetAddressBalanceHandler(ctx, routeParams[routeParamAddress], atBlueScore)
 }
 
-func getFeeEstimatesHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+func getAddressBalanceHistoryHandler(ctx *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, queryParams map[string]string,
 	_ []byte) (interface{}, error) {
 
-	return controllers.GetFeeEstimatesHandler()
+	interval := defaultBalanceHistoryInterval
+	if intervalParamValue, ok := queryParams[queryParamInterval]; ok {
+		interval = intervalParamValue
+	}
+	fromTime, err := convertOptionalQueryParamToTime(queryParams, queryParamFrom)
+	if err != nil {
+		return nil, err
+	}
+	toTime, err := convertOptionalQueryParamToTime(queryParams, queryParamTo)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.GetAddressBalanceHistoryHandler(ctx, routeParams[routeParamAddress], interval, fromTime, toTime)
 }
 
-func getBlocksHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, queryParams map[string]string,
+func getBlockByHashHandler(ctx *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
 	_ []byte) (interface{}, error) {
 
-	skip, err := convertQueryParamToInt64(queryParams, queryParamSkip, 0)
+	return controllers.GetBlockByHashHandler(ctx, routeParams[routeParamBlockHash])
+}
+
+func getBlockChildrenHandler(ctx *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+
+	return controllers.GetBlockChildrenHandler(ctx, routeParams[routeParamBlockHash])
+}
+
+func getBlockNeighbourhoodHandler(ctx *httpserverutils.ServerContext, _ *http.Request, routeParams map[string]string, queryParams map[string]string,
+	_ []byte) (interface{}, error) {
+
+	depth, err := convertQueryParamToInt64(queryParams, queryParamDepth, defaultBlockNeighbourhoodDepth)
 	if err != nil {
 		return nil, err
 	}
+	return controllers.GetBlockNeighbourhoodHandler(ctx, routeParams[routeParamBlockHash], depth)
+}
+
+func getFeeEstimatesHandler(ctx *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+
+	return controllers.GetFeeEstimatesHandler(ctx)
+}
+
+func getSearchHandler(ctx *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, queryParams map[string]string,
+	_ []byte) (interface{}, error) {
+
+	return controllers.GetSearchHandler(ctx, queryParams[queryParamSearchQuery])
+}
+
+func getBlocksHandler(ctx *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, queryParams map[string]string,
+	_ []byte) (interface{}, error) {
+
 	limit, err := convertQueryParamToInt64(queryParams, queryParamLimit, defaultGetBlocksLimit)
 	if err != nil {
 		return nil, err
@@ -190,10 +535,126 @@ func getBlocksHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[s
 	if orderParamValue, ok := queryParams[queryParamOrder]; ok {
 		order = orderParamValue
 	}
-	return controllers.GetBlocksHandler(order, skip, limit)
+	if cursor, ok := queryParams[queryParamCursor]; ok {
+		return controllers.GetBlocksPageHandler(ctx, order, cursor, limit)
+	}
+	skip, err := convertQueryParamToInt64(queryParams, queryParamSkip, 0)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.GetBlocksHandler(ctx, order, skip, limit)
 }
 
-func getBlockCountHandler(_ *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+func getDAGHandler(ctx *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, queryParams map[string]string,
 	_ []byte) (interface{}, error) {
-	return controllers.GetBlockCountHandler()
+
+	fromBlueScore, err := convertOptionalQueryParamToUint64(queryParams, queryParamFromBlueScore)
+	if err != nil {
+		return nil, err
+	}
+	toBlueScore, err := convertOptionalQueryParamToUint64(queryParams, queryParamToBlueScore)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.GetDAGHandler(ctx, fromBlueScore, toBlueScore)
+}
+
+func getBlockCountHandler(ctx *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+	return controllers.GetBlockCountHandler(ctx)
+}
+
+func postWebhookSubscriptionHandler(ctx *httpserverutils.ServerContext, _ *http.Request, _ map[string]string, _ map[string]string,
+	requestBody []byte) (interface{}, error) {
+
+	return controllers.PostWebhookSubscriptionHandler(ctx, requestBody)
+}
+
+func getWebhookSubscriptionHandler(ctx *httpserverutils.ServerContext, r *http.Request, routeParams map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+
+	webhookID, err := convertRouteParamToUint64(routeParams, routeParamWebhookID)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.GetWebhookSubscriptionHandler(ctx, webhookID, r.Header.Get(webhookSecretHeader))
+}
+
+func deleteWebhookSubscriptionHandler(ctx *httpserverutils.ServerContext, r *http.Request, routeParams map[string]string, _ map[string]string,
+	_ []byte) (interface{}, error) {
+
+	webhookID, err := convertRouteParamToUint64(routeParams, routeParamWebhookID)
+	if err != nil {
+		return nil, err
+	}
+	return controllers.DeleteWebhookSubscriptionHandler(ctx, webhookID, r.Header.Get(webhookSecretHeader))
+}
+
+func graphqlHandler(ctx *httpserverutils.ServerContext, r *http.Request, _ map[string]string, queryParams map[string]string,
+	requestBody []byte) (interface{}, error) {
+
+	return graphql.HandleQuery(ctx, r.Method, queryParams, requestBody)
+}
+
+func streamHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := streamFilter(r)
+	if err != nil {
+		httpserverutils.SendErr(httpserverutils.ToServerContext(r.Context()), w, err)
+		return
+	}
+	stream.ServeSSE(w, r, filter)
+}
+
+func webSocketHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := streamFilter(r)
+	if err != nil {
+		httpserverutils.SendErr(httpserverutils.ToServerContext(r.Context()), w, err)
+		return
+	}
+	stream.ServeWebSocket(w, r, filter)
+}
+
+func exportAddressHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := httpserverutils.ToServerContext(r.Context())
+	queryParams := firstQueryParamValues(r)
+	fromTime, err := convertOptionalQueryParamToTime(queryParams, queryParamFrom)
+	if err != nil {
+		httpserverutils.SendErr(ctx, w, err)
+		return
+	}
+	toTime, err := convertOptionalQueryParamToTime(queryParams, queryParamTo)
+	if err != nil {
+		httpserverutils.SendErr(ctx, w, err)
+		return
+	}
+	format := controllers.AddressHistoryFormatCSV
+	if formatParamValue, ok := queryParams[queryParamFormat]; ok {
+		format = formatParamValue
+	}
+	err = controllers.ExportAddressHistoryHandler(ctx, w, mux.Vars(r)[routeParamAddress], format, fromTime, toTime)
+	if err != nil {
+		httpserverutils.SendErr(ctx, w, err)
+	}
+}
+
+func streamFilter(r *http.Request) (*stream.Filter, error) {
+	queryParams := firstQueryParamValues(r)
+	minValue, err := convertOptionalQueryParamToUint64(queryParams, queryParamMinValue)
+	if err != nil {
+		return nil, err
+	}
+	if minValue == nil {
+		minValue = new(uint64)
+	}
+	return controllers.NewStreamFilter(queryParams[queryParamTopics], queryParams[queryParamAddress], *minValue)
+}
+
+// firstQueryParamValues returns the first value of every query parameter
+// of the given request, for handlers that aren't made by MakeHandler
+func firstQueryParamValues(r *http.Request) map[string]string {
+	queryParams := map[string]string{}
+	for param, values := range r.URL.Query() {
+		queryParams[param] = values[0]
+	}
+	return queryParams
 }
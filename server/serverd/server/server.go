@@ -12,18 +12,34 @@ import (
 
 const gracefulShutdownTimeout = 30 * time.Second
 
-// Start starts the HTTP REST server and returns a
+// Start starts the HTTP REST server, which limits the rate of
+// requests with the given rate limiter and caches responses in
+// the given response cache if it's not nil, and returns a
 // function to gracefully shutdown it.
-func Start(listenAddr string) func() {
+func Start(listenAddr string, rateLimiter *httpserverutils.RateLimiter,
+	responseCache *httpserverutils.ResponseCache) func() {
+
 	router := mux.NewRouter()
 	router.Use(httpserverutils.AddRequestMetadataMiddleware)
+	router.Use(httpserverutils.TracingMiddleware)
+	router.Use(httpserverutils.MetricsMiddleware)
 	router.Use(httpserverutils.RecoveryMiddleware)
 	router.Use(httpserverutils.LoggingMiddleware)
 	router.Use(httpserverutils.SetJSONMiddleware)
+	router.Use(rateLimiter.Middleware)
+	if responseCache != nil {
+		router.Use(responseCache.Middleware)
+	}
 	addRoutes(router)
+	cors := handlers.CORS(
+		handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "DELETE"}),
+		handlers.AllowedHeaders([]string{"Content-Type", "If-None-Match", "traceparent", "tracestate",
+			webhookSecretHeader, httpserverutils.APIKeyHeader}),
+		handlers.ExposedHeaders([]string{"Retry-After", "ETag"}),
+	)
 	httpServer := &http.Server{
 		Addr:    listenAddr,
-		Handler: handlers.CORS()(router),
+		Handler: cors(router),
 	}
 	spawn("server-Start", func() {
 		log.Infof("Katnip is listening on %s", listenAddr)
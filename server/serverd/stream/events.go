@@ -0,0 +1,107 @@
+package stream
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+// eventsFromNotification loads from the database the events
+// announced by the given notification
+func eventsFromNotification(notification *dbaccess.EventNotification) ([]*Event, error) {
+	switch notification.Event {
+	case dbaccess.EventBlocksAdded:
+		return blocksAddedEvents(notification.BlockHashes)
+	case dbaccess.EventChainBlocksAdded:
+		return chainBlocksAddedEvents(notification.BlockHashes, notification.IsLast)
+	default:
+		return nil, errors.Errorf("unknown event '%s'", notification.Event)
+	}
+}
+
+func blocksAddedEvents(blockHashes []string) ([]*Event, error) {
+	if !hub.wantsAnyOf(BlocksTopic, TransactionsTopic) {
+		return nil, nil
+	}
+
+	preloadedFields := dbmodels.PrefixFieldNames(dbmodels.BlockFieldNames.Transactions, dbmodels.TransactionRecommendedPreloadedFields)
+	preloadedFields = append(preloadedFields, dbmodels.BlockFieldNames.ParentBlocks)
+	dbBlocks, err := dbaccess.BlocksByHashes(database.NoTx(), blockHashes, preloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(dbBlocks, func(i, j int) bool {
+		return dbBlocks[i].BlueScore < dbBlocks[j].BlueScore
+	})
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*Event, 0, len(dbBlocks))
+	for _, dbBlock := range dbBlocks {
+		events = append(events, &Event{
+			Topic: BlocksTopic,
+			Data:  apimodels.ConvertBlockModelToBlockResponse(dbBlock, selectedTipBlueScore),
+		})
+		events = append(events, transactionEvents(TransactionsTopic, dbBlock.Transactions, selectedTipBlueScore)...)
+	}
+	return events, nil
+}
+
+// chainBlocksAddedEvents returns the events of the given blocks that were
+// added to the selected parent chain. The last of them is announced as the
+// selected tip only if isLast is set, since otherwise it's followed by more
+// chain blocks in the notifications that the chain change was split between.
+func chainBlocksAddedEvents(blockHashes []string, isLast bool) ([]*Event, error) {
+	if len(blockHashes) == 0 {
+		return nil, nil
+	}
+	wantsSelectedTip := isLast && hub.wantsAnyOf(SelectedTipTopic)
+	if !wantsSelectedTip && !hub.wantsAnyOf(AcceptedTransactionsTopic) {
+		return nil, nil
+	}
+
+	lastBlockHash := blockHashes[len(blockHashes)-1]
+	dbLastBlock, err := dbaccess.BlockByHash(database.NoTx(), lastBlockHash, dbmodels.BlockRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+	if dbLastBlock == nil {
+		return nil, errors.Errorf("chain block %s does not exist in the database", lastBlockHash)
+	}
+
+	acceptedTransactions, err := dbaccess.AcceptedTransactionsByBlockHashes(database.NoTx(), blockHashes,
+		dbmodels.TransactionRecommendedPreloadedFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	events := transactionEvents(AcceptedTransactionsTopic, acceptedTransactions, dbLastBlock.BlueScore)
+	if isLast {
+		events = append(events, &Event{
+			Topic: SelectedTipTopic,
+			Data:  apimodels.ConvertBlockModelToBlockResponse(dbLastBlock, dbLastBlock.BlueScore),
+		})
+	}
+	return events, nil
+}
+
+func transactionEvents(topic string, dbTransactions []*dbmodels.Transaction, selectedTipBlueScore uint64) []*Event {
+	events := make([]*Event, len(dbTransactions))
+	for i, dbTransaction := range dbTransactions {
+		transaction := apimodels.ConvertTxModelToTxResponse(dbTransaction, selectedTipBlueScore)
+		events[i] = &Event{
+			Topic:       topic,
+			Data:        transaction,
+			transaction: transaction,
+		}
+	}
+	return events
+}
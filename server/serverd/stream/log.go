@@ -0,0 +1,11 @@
+package stream
+
+import (
+	"github.com/kaspanet/kaspad/util/panics"
+	"github.com/someone235/katnip/server/logger"
+)
+
+var (
+	log   = logger.Logger("STRM")
+	spawn = panics.GoroutineWrapperFunc(log)
+)
@@ -0,0 +1,66 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/httpserverutils"
+)
+
+const sseKeepAliveInterval = 15 * time.Second
+
+// ServeSSE streams the events selected by the given filter
+// to the client as Server-Sent Events
+func ServeSSE(w http.ResponseWriter, r *http.Request, filter *Filter) {
+	ctx := httpserverutils.ToServerContext(r.Context())
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httpserverutils.SendErr(ctx, w, errors.New("the response writer does not support streaming"))
+		return
+	}
+
+	subscription, err := Subscribe(filter)
+	if err != nil {
+		httpserverutils.SendErr(ctx, w, httpserverutils.NewHandlerError(http.StatusServiceUnavailable, err))
+		return
+	}
+	defer subscription.Unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAliveTicker := time.NewTicker(sseKeepAliveInterval)
+	defer keepAliveTicker.Stop()
+
+	for {
+		select {
+		case event := <-subscription.Events():
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				ctx.Errorf("Error serializing stream event: %s", err)
+				return
+			}
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Topic, data)
+			if err != nil {
+				return
+			}
+		case <-keepAliveTicker.C:
+			_, err := fmt.Fprint(w, ": keep-alive\n\n")
+			if err != nil {
+				return
+			}
+		case <-subscription.Done():
+			return
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,238 @@
+package stream
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+)
+
+// Stream topics. They mirror the MQTT topics published by syncd,
+// and their events carry the same JSON shapes.
+const (
+	// BlocksTopic is a topic for new blocks
+	BlocksTopic = "dag/blocks"
+
+	// SelectedTipTopic is a topic for DAG selected tips
+	SelectedTipTopic = "dag/selected-tip"
+
+	// TransactionsTopic is a topic for the transactions of new blocks
+	TransactionsTopic = "transactions"
+
+	// AcceptedTransactionsTopic is a topic for accepted transactions
+	AcceptedTransactionsTopic = "transactions/accepted"
+)
+
+// Topics are all the topics a client can subscribe to
+var Topics = []string{BlocksTopic, SelectedTipTopic, TransactionsTopic, AcceptedTransactionsTopic}
+
+// subscriptionBufferSize is the amount of events that may wait for
+// a slow client before its subscription is dropped
+const subscriptionBufferSize = 256
+
+// Event is a single streamed event
+type Event struct {
+	Topic string      `json:"topic"`
+	Data  interface{} `json:"data"`
+
+	// transaction is set for events of the transaction topics,
+	// so that they can be filtered
+	transaction *apimodels.TransactionResponse
+}
+
+// Filter selects the events a subscription receives
+type Filter struct {
+	Topics map[string]struct{}
+
+	// Address, if set, selects only the transactions that
+	// send to or spend from this address
+	Address string
+
+	// MinValue selects only the transactions whose
+	// outputs are worth at least this value in total
+	MinValue uint64
+}
+
+func (filter *Filter) matches(event *Event) bool {
+	if _, ok := filter.Topics[event.Topic]; !ok {
+		return false
+	}
+	transaction := event.transaction
+	if transaction == nil {
+		return true
+	}
+
+	var value uint64
+	for _, output := range transaction.Outputs {
+		value += output.Value
+	}
+	if value < filter.MinValue {
+		return false
+	}
+
+	if filter.Address == "" {
+		return true
+	}
+	for _, output := range transaction.Outputs {
+		if output.Address == filter.Address {
+			return true
+		}
+	}
+	for _, input := range transaction.Inputs {
+		if input.Address == filter.Address {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription is a client's subscription to the stream
+type Subscription struct {
+	filter    *Filter
+	events    chan *Event
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Events returns the channel on which the subscription's events are sent
+func (subscription *Subscription) Events() <-chan *Event {
+	return subscription.events
+}
+
+// Done returns a channel that's closed when the subscription is dropped,
+// either because the client couldn't keep up or because the stream stopped
+func (subscription *Subscription) Done() <-chan struct{} {
+	return subscription.done
+}
+
+// Unsubscribe stops sending events to the subscription
+func (subscription *Subscription) Unsubscribe() {
+	hub.lock.Lock()
+	defer hub.lock.Unlock()
+	hub.remove(subscription)
+}
+
+type streamHub struct {
+	lock          sync.Mutex
+	isRunning     bool
+	subscriptions map[*Subscription]struct{}
+}
+
+var hub = &streamHub{
+	subscriptions: make(map[*Subscription]struct{}),
+}
+
+// Subscribe subscribes to the events selected by the given filter
+func Subscribe(filter *Filter) (*Subscription, error) {
+	hub.lock.Lock()
+	defer hub.lock.Unlock()
+
+	if !hub.isRunning {
+		return nil, errors.New("the stream is not running")
+	}
+
+	subscription := &Subscription{
+		filter: filter,
+		events: make(chan *Event, subscriptionBufferSize),
+		done:   make(chan struct{}),
+	}
+	hub.subscriptions[subscription] = struct{}{}
+	return subscription, nil
+}
+
+// remove must be called while holding hub.lock
+func (hub *streamHub) remove(subscription *Subscription) {
+	delete(hub.subscriptions, subscription)
+	subscription.closeOnce.Do(func() {
+		close(subscription.done)
+	})
+}
+
+// wantsAnyOf returns whether any subscription wants events of one of the given topics
+func (hub *streamHub) wantsAnyOf(topics ...string) bool {
+	hub.lock.Lock()
+	defer hub.lock.Unlock()
+
+	for subscription := range hub.subscriptions {
+		for _, topic := range topics {
+			if _, ok := subscription.filter.Topics[topic]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// broadcast sends the given events to all the subscriptions that want them.
+// Subscriptions that can't keep up are dropped.
+func (hub *streamHub) broadcast(events []*Event) {
+	hub.lock.Lock()
+	defer hub.lock.Unlock()
+
+	for subscription := range hub.subscriptions {
+		for _, event := range events {
+			if !subscription.filter.matches(event) {
+				continue
+			}
+			select {
+			case subscription.events <- event:
+			default:
+				log.Warnf("Dropping a stream subscription that couldn't keep up")
+				hub.remove(subscription)
+			}
+			if _, ok := hub.subscriptions[subscription]; !ok {
+				break
+			}
+		}
+	}
+}
+
+// Start starts listening to the events announced by syncd
+// and returns a function to stop it.
+func Start() (func(), error) {
+	listener, err := database.Listen(dbaccess.EventsChannel)
+	if err != nil {
+		return nil, err
+	}
+
+	hub.lock.Lock()
+	hub.isRunning = true
+	hub.lock.Unlock()
+
+	stoppedChan := make(chan struct{})
+	spawn("stream-Start", func() {
+		defer close(stoppedChan)
+		for notification := range listener.Channel() {
+			eventNotification := &dbaccess.EventNotification{}
+			err := json.Unmarshal([]byte(notification.Payload), eventNotification)
+			if err != nil {
+				log.Errorf("Error parsing event notification: %s", err)
+				continue
+			}
+			events, err := eventsFromNotification(eventNotification)
+			if err != nil {
+				log.Errorf("Error loading the events of '%s': %s", eventNotification.Event, err)
+				continue
+			}
+			hub.broadcast(events)
+		}
+	})
+
+	return func() {
+		hub.lock.Lock()
+		hub.isRunning = false
+		for subscription := range hub.subscriptions {
+			hub.remove(subscription)
+		}
+		hub.lock.Unlock()
+
+		err := listener.Close()
+		if err != nil {
+			log.Errorf("Error closing the stream listener: %s", err)
+		}
+		<-stoppedChan
+	}, nil
+}
@@ -0,0 +1,85 @@
+package stream
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/someone235/katnip/server/httpserverutils"
+)
+
+const (
+	webSocketWriteTimeout = 10 * time.Second
+	webSocketPingInterval = 30 * time.Second
+	webSocketPongTimeout  = webSocketPingInterval + webSocketWriteTimeout
+)
+
+var upgrader = websocket.Upgrader{
+	// The REST API is open to all origins, and so is the stream
+	CheckOrigin: func(_ *http.Request) bool { return true },
+}
+
+// ServeWebSocket streams the events selected by the given filter
+// to the client over a WebSocket. Every message is a JSON object
+// with the topic of the event and its data.
+func ServeWebSocket(w http.ResponseWriter, r *http.Request, filter *Filter) {
+	ctx := httpserverutils.ToServerContext(r.Context())
+
+	subscription, err := Subscribe(filter)
+	if err != nil {
+		httpserverutils.SendErr(ctx, w, httpserverutils.NewHandlerError(http.StatusServiceUnavailable, err))
+		return
+	}
+	defer subscription.Unsubscribe()
+
+	// Upgrade replies to the client by itself on failure
+	connection, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		ctx.Warnf("Error upgrading to a WebSocket: %s", err)
+		return
+	}
+	defer connection.Close()
+
+	// The client isn't expected to send anything, but the connection
+	// must be read from in order to process pongs and close messages
+	closedChan := make(chan struct{})
+	spawn("stream-ServeWebSocket-read", func() {
+		defer close(closedChan)
+		connection.SetReadLimit(512)
+		_ = connection.SetReadDeadline(time.Now().Add(webSocketPongTimeout))
+		connection.SetPongHandler(func(string) error {
+			return connection.SetReadDeadline(time.Now().Add(webSocketPongTimeout))
+		})
+		for {
+			_, _, err := connection.ReadMessage()
+			if err != nil {
+				return
+			}
+		}
+	})
+
+	pingTicker := time.NewTicker(webSocketPingInterval)
+	defer pingTicker.Stop()
+
+	for {
+		select {
+		case event := <-subscription.Events():
+			_ = connection.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout))
+			err := connection.WriteJSON(event)
+			if err != nil {
+				return
+			}
+		case <-pingTicker.C:
+			err := connection.WriteControl(websocket.PingMessage, nil, time.Now().Add(webSocketWriteTimeout))
+			if err != nil {
+				return
+			}
+		case <-subscription.Done():
+			_ = connection.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(webSocketWriteTimeout))
+			return
+		case <-closedChan:
+			return
+		}
+	}
+}
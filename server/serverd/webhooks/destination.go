@@ -0,0 +1,104 @@
+package webhooks
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/serverd/config"
+)
+
+// disallowedNetworks are the networks that webhooks may not be delivered to,
+// unless they're allowed with --webhookallowednetwork, so that subscribers
+// can't make the server send requests to itself or to its private network
+var disallowedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",      // "This" network
+	"10.0.0.0/8",     // Private
+	"100.64.0.0/10",  // Carrier-grade NAT
+	"127.0.0.0/8",    // Loopback
+	"169.254.0.0/16", // Link-local, including cloud metadata services
+	"172.16.0.0/12",  // Private
+	"192.168.0.0/16", // Private
+	"224.0.0.0/4",    // Multicast
+	"::/128",         // Unspecified
+	"::1/128",        // Loopback
+	"fc00::/7",       // Unique local
+	"fe80::/10",      // Link-local
+	"ff00::/8",       // Multicast
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	ipNets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		ipNets[i] = ipNet
+	}
+	return ipNets
+}
+
+// ValidateDestination resolves the given webhook host and returns an error if
+// any of its addresses may not be delivered to. Deliveries check the address
+// again when they connect, since the host may resolve differently by then.
+func ValidateDestination(ctx context.Context, host string) error {
+	ipAddrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return errors.Wrapf(err, "error resolving %s", host)
+	}
+	for _, ipAddr := range ipAddrs {
+		if !isAllowedIP(ipAddr.IP, config.ActiveConfig().WebhookAllowedIPNets()) {
+			return errors.Errorf("%s resolves to %s, which webhooks may not be delivered to", host, ipAddr.IP)
+		}
+	}
+	return nil
+}
+
+// isAllowedIP returns whether webhooks may be delivered to the given IP
+func isAllowedIP(ip net.IP, allowedNetworks []*net.IPNet) bool {
+	for _, network := range allowedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	for _, network := range disallowedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// newHTTPClient returns a client that refuses to connect to addresses
+// that webhooks may not be delivered to. The check happens right before
+// connecting, so DNS rebinding and redirects can't get around it.
+func newHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: deliveryTimeout,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isAllowedIP(ip, config.ActiveConfig().WebhookAllowedIPNets()) {
+				return errors.Errorf("webhooks may not be delivered to %s", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: deliveryTimeout,
+		Transport: &http.Transport{
+			// Deliveries are never sent through a proxy,
+			// since the dialer would check the proxy's address
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: deliveryTimeout,
+			MaxIdleConnsPerHost: 1,
+		},
+	}
+}
@@ -0,0 +1,40 @@
+package webhooks
+
+import (
+	"net"
+	"testing"
+)
+
+func TestIsAllowedIP(t *testing.T) {
+	_, allowedNetwork, err := net.ParseCIDR("10.1.0.0/16")
+	if err != nil {
+		t.Fatalf("ParseCIDR: %s", err)
+	}
+	allowedNetworks := []*net.IPNet{allowedNetwork}
+
+	tests := []struct {
+		ip              string
+		expectedAllowed bool
+	}{
+		{ip: "93.184.216.34", expectedAllowed: true},
+		{ip: "2606:2800:220:1::1", expectedAllowed: true},
+		{ip: "127.0.0.1", expectedAllowed: false},
+		{ip: "::1", expectedAllowed: false},
+		{ip: "::ffff:127.0.0.1", expectedAllowed: false},
+		{ip: "0.0.0.0", expectedAllowed: false},
+		{ip: "::", expectedAllowed: false},
+		{ip: "10.0.0.1", expectedAllowed: false},
+		{ip: "172.16.5.4", expectedAllowed: false},
+		{ip: "192.168.1.1", expectedAllowed: false},
+		{ip: "169.254.169.254", expectedAllowed: false},
+		{ip: "fd00::1", expectedAllowed: false},
+		{ip: "fe80::1", expectedAllowed: false},
+		{ip: "10.1.2.3", expectedAllowed: true},
+	}
+	for _, test := range tests {
+		allowed := isAllowedIP(net.ParseIP(test.ip), allowedNetworks)
+		if allowed != test.expectedAllowed {
+			t.Errorf("%s: expected allowed to be %t but got %t", test.ip, test.expectedAllowed, allowed)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package webhooks
+
+import (
+	"github.com/kaspanet/kaspad/util/panics"
+	"github.com/someone235/katnip/server/logger"
+)
+
+var (
+	log   = logger.Logger("WHKD")
+	spawn = panics.GoroutineWrapperFunc(log)
+)
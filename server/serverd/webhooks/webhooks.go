@@ -0,0 +1,231 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+const (
+	// SignatureHeader is the header that holds the HMAC-SHA256
+	// signature of the payload, keyed by the subscription secret
+	SignatureHeader = "X-Katnip-Signature"
+
+	// EventHeader is the header that holds the event of the delivery
+	EventHeader = "X-Katnip-Event"
+
+	// DeliveryHeader is the header that holds the ID of the delivery.
+	// Subscribers may receive a delivery more than once, and can use
+	// it to discard duplicates.
+	DeliveryHeader = "X-Katnip-Delivery"
+)
+
+const (
+	pollInterval    = time.Second
+	deliveryTimeout = 10 * time.Second
+	deliveryBatch   = 100
+
+	// maxConcurrentEndpoints is the number of endpoints that
+	// the deliveries of a batch are sent to at the same time
+	maxConcurrentEndpoints = 20
+
+	// deliveryLease is long enough for the deliveries of a whole
+	// batch to time out one after another, which is what happens when
+	// they all go to the same unresponsive endpoint, so that they aren't
+	// claimed again while they're still being delivered
+	deliveryLease = deliveryBatch*deliveryTimeout + time.Minute
+
+	maxAttempts      = 10
+	baseRetryDelay   = 10 * time.Second
+	maxRetryDelay    = 6 * time.Hour
+	maxLastErrorSize = 1024
+)
+
+var httpClient = newHTTPClient()
+
+// Start starts delivering the queued webhook deliveries
+// and returns a function to stop it.
+func Start() func() {
+	stopChan := make(chan struct{})
+	stoppedChan := make(chan struct{})
+	spawn("webhooks-Start", func() {
+		defer close(stoppedChan)
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				err := deliverDue()
+				if err != nil {
+					log.Errorf("Error delivering webhooks: %s", err)
+				}
+			case <-stopChan:
+				return
+			}
+		}
+	})
+
+	return func() {
+		close(stopChan)
+		<-stoppedChan
+	}
+}
+
+// deliverDue delivers batches of due deliveries until none are left
+func deliverDue() error {
+	for {
+		ids, err := dbaccess.ClaimDueWebhookDeliveries(database.NoTx(), time.Now(), deliveryLease, deliveryBatch)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		deliveries, err := dbaccess.WebhookDeliveriesByIDs(database.NoTx(), ids,
+			dbmodels.WebhookDeliveryFieldNames.WebhookSubscription)
+		if err != nil {
+			return err
+		}
+		err = deliverBatch(deliveries)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// deliverBatch delivers the given deliveries. Deliveries to different endpoints
+// are sent concurrently, so that a slow endpoint doesn't hold up the others,
+// while deliveries to the same endpoint are sent one after another.
+func deliverBatch(deliveries []*dbmodels.WebhookDelivery) error {
+	endpointDeliveries := make(map[string][]*dbmodels.WebhookDelivery)
+	for _, delivery := range deliveries {
+		endpoint := deliveryEndpoint(delivery)
+		endpointDeliveries[endpoint] = append(endpointDeliveries[endpoint], delivery)
+	}
+
+	var firstErr error
+	var firstErrLock sync.Mutex
+	var wg sync.WaitGroup
+	endpointSemaphore := make(chan struct{}, maxConcurrentEndpoints)
+	for _, deliveries := range endpointDeliveries {
+		deliveries := deliveries
+		endpointSemaphore <- struct{}{}
+		wg.Add(1)
+		spawn("webhooks-deliverBatch", func() {
+			defer func() {
+				<-endpointSemaphore
+				wg.Done()
+			}()
+			for _, delivery := range deliveries {
+				err := attempt(delivery)
+				if err != nil {
+					firstErrLock.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					firstErrLock.Unlock()
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// deliveryEndpoint returns the host that the given delivery is sent to
+func deliveryEndpoint(delivery *dbmodels.WebhookDelivery) string {
+	webhookURL, err := url.Parse(delivery.WebhookSubscription.URL)
+	if err != nil {
+		return delivery.WebhookSubscription.URL
+	}
+	return webhookURL.Host
+}
+
+// attempt sends the given delivery and records the outcome. Failed
+// deliveries are retried with an exponential backoff, and are marked
+// as dead once they run out of attempts.
+func attempt(delivery *dbmodels.WebhookDelivery) error {
+	deliveryErr := send(delivery)
+
+	delivery.Attempts++
+	if deliveryErr == nil {
+		delivery.Status = dbmodels.WebhookDeliveryStatusDelivered
+		delivery.LastError = nil
+		return dbaccess.UpdateWebhookDeliveryAttempt(database.NoTx(), delivery)
+	}
+
+	lastError := deliveryErr.Error()
+	if len(lastError) > maxLastErrorSize {
+		lastError = lastError[:maxLastErrorSize]
+	}
+	delivery.LastError = &lastError
+	if delivery.Attempts >= maxAttempts {
+		log.Warnf("Webhook delivery %d to %s is dead after %d attempts: %s",
+			delivery.ID, delivery.WebhookSubscription.URL, delivery.Attempts, deliveryErr)
+		delivery.Status = dbmodels.WebhookDeliveryStatusDead
+	} else {
+		log.Debugf("Webhook delivery %d to %s failed: %s",
+			delivery.ID, delivery.WebhookSubscription.URL, deliveryErr)
+		delivery.NextAttemptAt = time.Now().Add(retryDelay(delivery.Attempts))
+	}
+	return dbaccess.UpdateWebhookDeliveryAttempt(database.NoTx(), delivery)
+}
+
+// retryDelay returns how long to wait after the given number of failed attempts
+func retryDelay(attempts uint32) time.Duration {
+	delay := baseRetryDelay
+	for i := uint32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+func send(delivery *dbmodels.WebhookDelivery) error {
+	payload := []byte(delivery.Payload)
+	request, err := http.NewRequest(http.MethodPost, delivery.WebhookSubscription.URL, bytes.NewReader(payload))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, delivery.Event)
+	request.Header.Set(DeliveryHeader, strconv.FormatUint(delivery.ID, 10))
+	request.Header.Set(SignatureHeader, Sign(delivery.WebhookSubscription.Secret, payload))
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errors.Errorf("unexpected response status %s", response.Status)
+	}
+	return nil
+}
+
+// Sign returns the value of the SignatureHeader for the given payload
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return fmt.Sprintf("sha256=%s", hex.EncodeToString(mac.Sum(nil)))
+}
@@ -20,8 +20,9 @@ const (
 
 var (
 	// Default configuration options
-	defaultLogDir = util.AppDir("katnip_syncd", false)
-	activeConfig  *Config
+	defaultLogDir  = util.AppDir("katnip_syncd", false)
+	defaultMQTTQoS = byte(2)
+	activeConfig   *Config
 )
 
 // ActiveConfig returns the active configuration struct
@@ -32,15 +33,23 @@ func ActiveConfig() *Config {
 // Config defines the configuration options for the sync daemon.
 type Config struct {
 	Migrate           bool   `long:"migrate" description:"Migrate the database to the latest version. The daemon will not start when using this flag."`
-	MQTTBrokerAddress string `long:"mqttaddress" description:"MQTT broker address" required:"false"`
-	MQTTUser          string `long:"mqttuser" description:"MQTT server user" required:"false"`
+	MQTTBrokerAddress string `long:"mqttaddress" description:"MQTT broker address. Use the ssl:// scheme to connect over TLS" required:"false"`
+	MQTTUser          string `long:"mqttuser" description:"MQTT server user. Omit for anonymous brokers" required:"false"`
 	MQTTPassword      string `long:"mqttpass" description:"MQTT server password" required:"false"`
+	MQTTQoS           byte   `long:"mqttqos" description:"MQTT quality of service: 0, 1 or 2 (default: 2)" required:"false"`
+	MQTTCAFile        string `long:"mqttcafile" description:"PEM file with the certificate authorities to verify the MQTT broker against" required:"false"`
+	MQTTCertFile      string `long:"mqttcertfile" description:"PEM client certificate to authenticate to the MQTT broker with" required:"false"`
+	MQTTKeyFile       string `long:"mqttkeyfile" description:"PEM key of the MQTT client certificate" required:"false"`
+	MQTTTopicPrefix   string `long:"mqtttopicprefix" description:"Prefix for all MQTT topics, e.g. kaspa-testnet/" required:"false"`
+	EventsFile        string `long:"eventsfile" description:"Append sync events to this file as newline-delimited JSON" required:"false"`
 	config.CommonConfigFlags
 }
 
 // Parse parses the CLI arguments and returns a config struct.
 func Parse() error {
-	activeConfig = &Config{}
+	activeConfig = &Config{
+		MQTTQoS: defaultMQTTQoS,
+	}
 	parser := flags.NewParser(activeConfig, flags.HelpFlag)
 	_, err := parser.Parse()
 	// Show the version and exit if the version flag was specified.
@@ -61,9 +70,22 @@ func Parse() error {
 		return err
 	}
 
-	if (activeConfig.MQTTBrokerAddress != "" || activeConfig.MQTTUser != "" || activeConfig.MQTTPassword != "") &&
-		(activeConfig.MQTTBrokerAddress == "" || activeConfig.MQTTUser == "" || activeConfig.MQTTPassword == "") {
-		return errors.New("--mqttaddress, --mqttuser, and --mqttpass must be passed all together")
+	if activeConfig.MQTTBrokerAddress == "" &&
+		(activeConfig.MQTTUser != "" || activeConfig.MQTTPassword != "" || activeConfig.MQTTCAFile != "" ||
+			activeConfig.MQTTCertFile != "" || activeConfig.MQTTKeyFile != "" || activeConfig.MQTTTopicPrefix != "") {
+		return errors.New("the MQTT options require --mqttaddress")
+	}
+
+	if activeConfig.MQTTPassword != "" && activeConfig.MQTTUser == "" {
+		return errors.New("--mqttpass requires --mqttuser")
+	}
+
+	if (activeConfig.MQTTCertFile == "") != (activeConfig.MQTTKeyFile == "") {
+		return errors.New("--mqttcertfile and --mqttkeyfile must be passed together")
+	}
+
+	if activeConfig.MQTTQoS > 2 {
+		return errors.New("--mqttqos must be 0, 1 or 2")
 	}
 
 	return nil
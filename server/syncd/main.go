@@ -14,8 +14,11 @@ import (
 	"github.com/pkg/errors"
 	"github.com/someone235/katnip/server/database"
 	"github.com/someone235/katnip/server/kaspadrpc"
+	"github.com/someone235/katnip/server/metrics"
 	"github.com/someone235/katnip/server/syncd/config"
-	"github.com/someone235/katnip/server/syncd/mqtt"
+	"github.com/someone235/katnip/server/syncd/notifications"
+	"github.com/someone235/katnip/server/syncd/publisher"
+	"github.com/someone235/katnip/server/tracing"
 	"github.com/someone235/katnip/server/version"
 )
 
@@ -41,6 +44,18 @@ func main() {
 		profiling.Start(config.ActiveConfig().Profile, log)
 	}
 
+	// Start the metrics server if required
+	if config.ActiveConfig().MetricsListen != "" {
+		shutdownMetrics := metrics.Start(config.ActiveConfig().MetricsListen)
+		defer shutdownMetrics()
+	}
+
+	shutdownTracing, err := tracing.Start("katnip-syncd", &config.ActiveConfig().CommonConfigFlags)
+	if err != nil {
+		panic(errors.Errorf("Error starting tracing: %s", err))
+	}
+	defer shutdownTracing()
+
 	if config.ActiveConfig().Migrate {
 		err := database.Migrate(&config.ActiveConfig().CommonConfigFlags)
 		if err != nil {
@@ -60,11 +75,20 @@ func main() {
 		}
 	}()
 
-	err = mqtt.Connect()
+	eventsPublisher, err := newEventsPublisher()
 	if err != nil {
-		panic(errors.Errorf("Error connecting to MQTT: %s", err))
+		panic(errors.Errorf("Error setting up the event publishers: %s", err))
+	}
+	if eventsPublisher != nil {
+		defer func() {
+			err := eventsPublisher.Close()
+			if err != nil {
+				log.Errorf("Error closing the event publishers: %s", err)
+			}
+		}()
+		stopNotifications := notifications.Start(eventsPublisher)
+		defer stopNotifications()
 	}
-	defer mqtt.Close()
 
 	client, err := kaspadrpc.NewClient(&config.ActiveConfig().CommonConfigFlags, true)
 	if err != nil {
@@ -85,3 +109,38 @@ func main() {
 	// Gracefully stop syncing
 	doneChan <- struct{}{}
 }
+
+// newEventsPublisher returns a publisher for all the event sinks
+// that were configured, or nil if none were
+func newEventsPublisher() (publisher.Publisher, error) {
+	cfg := config.ActiveConfig()
+	var publishers []publisher.Publisher
+
+	if cfg.MQTTBrokerAddress != "" {
+		mqttPublisher, err := publisher.NewMQTTPublisher(&publisher.MQTTOptions{
+			BrokerAddress:    cfg.MQTTBrokerAddress,
+			User:             cfg.MQTTUser,
+			Password:         cfg.MQTTPassword,
+			QualityOfService: cfg.MQTTQoS,
+			CAFile:           cfg.MQTTCAFile,
+			CertFile:         cfg.MQTTCertFile,
+			KeyFile:          cfg.MQTTKeyFile,
+			TopicPrefix:      cfg.MQTTTopicPrefix,
+			RetainedTopics:   []string{notifications.SelectedTipTopic},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "error connecting to MQTT")
+		}
+		publishers = append(publishers, mqttPublisher)
+	}
+
+	if cfg.EventsFile != "" {
+		filePublisher, err := publisher.NewFilePublisher(cfg.EventsFile)
+		if err != nil {
+			return nil, err
+		}
+		publishers = append(publishers, filePublisher)
+	}
+
+	return publisher.NewMultiPublisher(publishers...), nil
+}
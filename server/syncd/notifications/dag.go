@@ -0,0 +1,39 @@
+package notifications
+
+import (
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+// DAGTopic is a topic for incremental updates to the
+// compact DAG representation served by the /dag route
+const DAGTopic = "dag/graph"
+
+// publishDAGNotification publishes the compact representation of the
+// given block along with the edges to its parents. The given block is
+// expected to have its ParentBlocks preloaded.
+func publishDAGNotification(ctx database.Context, dbBlock *dbmodels.Block) error {
+	dagBlock := apimodels.ConvertDAGBlockToDAGBlockResponse(&dbmodels.DAGBlock{
+		ID:           dbBlock.ID,
+		BlockHash:    dbBlock.BlockHash,
+		BlueScore:    dbBlock.BlueScore,
+		Timestamp:    dbBlock.Timestamp,
+		IsChainBlock: dbBlock.IsChainBlock,
+	})
+
+	dagEdges := make([]*apimodels.DAGEdge, len(dbBlock.ParentBlocks))
+	for i, parent := range dbBlock.ParentBlocks {
+		dagEdges[i] = &apimodels.DAGEdge{
+			BlockHash:       dbBlock.BlockHash,
+			ParentBlockHash: parent.BlockHash,
+		}
+	}
+
+	return publish(ctx, DAGTopic, &apimodels.DAGResponse{
+		FromBlueScore: dbBlock.BlueScore,
+		ToBlueScore:   dbBlock.BlueScore,
+		Blocks:        []*apimodels.DAGBlock{dagBlock},
+		Edges:         dagEdges,
+	})
+}
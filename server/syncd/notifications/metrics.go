@@ -0,0 +1,27 @@
+package notifications
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Publishing metrics, served under /metrics
+// when the metrics server is enabled
+var (
+	publishedEventsCount = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "katnip_syncd_published_events_total",
+		Help: "Number of events that were published",
+	})
+	coalescedEventsCount = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "katnip_syncd_coalesced_events_total",
+		Help: "Number of events that were left out because a later event of the same topic was published",
+	})
+	publishFailuresCount = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "katnip_syncd_publish_failures_total",
+		Help: "Number of batches of events that failed to be published",
+	})
+	backloggedEventsCount = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "katnip_syncd_backlogged_events",
+		Help: "Number of events in the outbox that are waiting to be published",
+	})
+)
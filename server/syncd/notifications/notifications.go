@@ -0,0 +1,43 @@
+package notifications
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+// isEnabled is whether notifications are written to the events
+// outbox. It's set once an outbox drainer is started, so that
+// the outbox doesn't grow when nothing consumes it.
+var isEnabled bool
+
+func hasPublisher() bool {
+	return isEnabled
+}
+
+// publish writes the given notification to the events outbox. When called
+// within a database transaction, the notification is published only if and
+// once the transaction is committed.
+func publish(ctx database.Context, topic string, data interface{}) error {
+	outboxEvent, err := newOutboxEvent(topic, data)
+	if err != nil {
+		return err
+	}
+	return dbaccess.InsertOutboxEvents(ctx, []*dbmodels.OutboxEvent{outboxEvent})
+}
+
+func newOutboxEvent(topic string, data interface{}) (*dbmodels.OutboxEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dbmodels.OutboxEvent{
+		Topic:     topic,
+		Payload:   string(payload),
+		CreatedAt: time.Now(),
+	}, nil
+}
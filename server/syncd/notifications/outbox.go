@@ -0,0 +1,187 @@
+package notifications
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/dbmodels"
+	"github.com/someone235/katnip/server/syncd/publisher"
+)
+
+const (
+	outboxPollInterval  = time.Second
+	outboxBatchSize     = 500
+	minOutboxRetryDelay = time.Second
+	maxOutboxRetryDelay = time.Minute
+
+	// outboxBacklogInterval is how often the backlog of the outbox
+	// is counted, apart from draining it, since counting it scans it
+	outboxBacklogInterval = 10 * time.Second
+)
+
+// wakeChan is used to wake the outbox drainer
+// up as soon as new notifications are committed
+var wakeChan = make(chan struct{}, 1)
+
+// Wake tells the outbox drainer that new notifications were committed
+func Wake() {
+	select {
+	case wakeChan <- struct{}{}:
+	default:
+	}
+}
+
+// Start enables the notifications and starts draining the events outbox to the
+// given publisher. Events are published in batches, in the background, so that
+// a slow publisher never holds the sync back. A batch is removed from the outbox
+// only after all its events were published, and a failed batch is retried until
+// it's published, so every event is published at least once.
+// Start returns a function to stop draining.
+func Start(eventsPublisher publisher.Publisher) func() {
+	isEnabled = true
+
+	stopChan := make(chan struct{})
+	stoppedChan := make(chan struct{})
+	spawn("notifications-Start", func() {
+		defer close(stoppedChan)
+		retryDelay := minOutboxRetryDelay
+		for {
+			err := drainOutbox(eventsPublisher, stopChan)
+			wait := outboxPollInterval
+			if err != nil {
+				log.Errorf("Error draining the events outbox. Retrying in %s: %s", retryDelay, err)
+				wait = retryDelay
+				retryDelay *= 2
+				if retryDelay > maxOutboxRetryDelay {
+					retryDelay = maxOutboxRetryDelay
+				}
+			} else {
+				retryDelay = minOutboxRetryDelay
+			}
+
+			// Keep backing off from a failing publisher
+			// even when new notifications arrive
+			wake := wakeChan
+			if err != nil {
+				wake = nil
+			}
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-wake:
+				timer.Stop()
+			case <-stopChan:
+				timer.Stop()
+				return
+			}
+		}
+	})
+
+	backlogStoppedChan := make(chan struct{})
+	spawn("notifications-reportBacklog", func() {
+		defer close(backlogStoppedChan)
+		reportBacklog(stopChan)
+	})
+
+	return func() {
+		close(stopChan)
+		<-stoppedChan
+		<-backlogStoppedChan
+	}
+}
+
+// reportBacklog counts the events in the outbox every outboxBacklogInterval
+// and reports them in the backlogged events gauge, until stopChan is closed
+func reportBacklog(stopChan <-chan struct{}) {
+	ticker := time.NewTicker(outboxBacklogInterval)
+	defer ticker.Stop()
+	for {
+		backlog, err := dbaccess.OutboxEventsCount(database.NoTx())
+		if err != nil {
+			log.Warnf("Error counting the events in the outbox: %s", err)
+		} else {
+			backloggedEventsCount.Set(float64(backlog))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stopChan:
+			return
+		}
+	}
+}
+
+// coalescedTopics are the topics of which only the latest event
+// matters, so older events in the same batch are not published
+var coalescedTopics = map[string]struct{}{
+	SelectedTipTopic: {},
+}
+
+// drainOutbox publishes the events in the outbox in batches until it's empty.
+// A batch that's smaller than outboxBatchSize means that the outbox was emptied.
+func drainOutbox(eventsPublisher publisher.Publisher, stopChan <-chan struct{}) error {
+	for {
+		select {
+		case <-stopChan:
+			return nil
+		default:
+		}
+
+		dbEvents, err := dbaccess.OutboxEvents(database.NoTx(), outboxBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(dbEvents) == 0 {
+			return nil
+		}
+
+		events := coalesce(dbEvents)
+		err = eventsPublisher.Publish(events...)
+		if err != nil {
+			publishFailuresCount.Inc()
+			return err
+		}
+		publishedEventsCount.Add(float64(len(events)))
+		coalescedEventsCount.Add(float64(len(dbEvents) - len(events)))
+
+		ids := make([]uint64, len(dbEvents))
+		for i, dbEvent := range dbEvents {
+			ids[i] = dbEvent.ID
+		}
+		err = dbaccess.DeleteOutboxEvents(database.NoTx(), ids)
+		if err != nil {
+			return err
+		}
+
+		if len(dbEvents) < outboxBatchSize {
+			return nil
+		}
+	}
+}
+
+// coalesce converts the given outbox events to publisher events,
+// leaving out all but the latest event of every coalesced topic
+func coalesce(dbEvents []*dbmodels.OutboxEvent) []*publisher.Event {
+	latestCoalescedEvents := make(map[string]uint64)
+	for _, dbEvent := range dbEvents {
+		if _, ok := coalescedTopics[dbEvent.Topic]; ok {
+			latestCoalescedEvents[dbEvent.Topic] = dbEvent.ID
+		}
+	}
+
+	events := make([]*publisher.Event, 0, len(dbEvents))
+	for _, dbEvent := range dbEvents {
+		if latestID, ok := latestCoalescedEvents[dbEvent.Topic]; ok && latestID != dbEvent.ID {
+			continue
+		}
+		events = append(events, &publisher.Event{
+			Sequence: dbEvent.ID,
+			Topic:    dbEvent.Topic,
+			Data:     json.RawMessage(dbEvent.Payload),
+		})
+	}
+	return events
+}
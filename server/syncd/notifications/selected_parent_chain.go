@@ -1,17 +1,18 @@
-package mqtt
+package notifications
 
 import (
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/database"
 )
 
-// SelectedParentChainTopic is an MQTT topic for changes in the
+// SelectedParentChainTopic is a topic for changes in the
 // selected parent chain
 const SelectedParentChainTopic = "dag/selected-parent-chain"
 
 // PublishSelectedParentChainNotifications publishes notifications for changes in the selected parent chain
-func PublishSelectedParentChainNotifications(removedChainHashes []string, addedChainBlocks []*appmessage.ChainBlock) error {
-	if !isConnected() {
+func PublishSelectedParentChainNotifications(ctx database.Context, removedChainHashes []string, addedChainBlocks []*appmessage.ChainBlock) error {
+	if !hasPublisher() {
 		return nil
 	}
 
@@ -32,5 +33,5 @@ func PublishSelectedParentChainNotifications(removedChainHashes []string, addedC
 	}
 	notificationData.RemovedBlockHashes = removedChainHashes
 
-	return publish(SelectedParentChainTopic, notificationData)
+	return publish(ctx, SelectedParentChainTopic, notificationData)
 }
@@ -1,4 +1,4 @@
-package mqtt
+package notifications
 
 import (
 	"github.com/someone235/katnip/server/apimodels"
@@ -8,20 +8,20 @@ import (
 )
 
 const (
-	// SelectedTipTopic is an MQTT topic for DAG selected tips
+	// SelectedTipTopic is a topic for DAG selected tips
 	SelectedTipTopic = "dag/selected-tip"
 )
 
 // PublishSelectedTipNotification publishes notification for a new selected tip
-func PublishSelectedTipNotification(selectedTipHash string) error {
-	if !isConnected() {
+func PublishSelectedTipNotification(ctx database.Context, selectedTipHash string) error {
+	if !hasPublisher() {
 		return nil
 	}
-	dbBlock, err := dbaccess.BlockByHash(database.NoTx(), selectedTipHash, dbmodels.BlockRecommendedPreloadedFields...)
+	dbBlock, err := dbaccess.BlockByHash(ctx, selectedTipHash, dbmodels.BlockRecommendedPreloadedFields...)
 	if err != nil {
 		return err
 	}
 
 	block := apimodels.ConvertBlockModelToBlockResponse(dbBlock, dbBlock.BlueScore)
-	return publish(SelectedTipTopic, block)
+	return publish(ctx, SelectedTipTopic, block)
 }
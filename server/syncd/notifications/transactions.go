@@ -0,0 +1,89 @@
+package notifications
+
+import (
+	"github.com/someone235/katnip/server/database"
+	"path"
+
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+const (
+	// TransactionsTopic is a topic for transactions
+	TransactionsTopic = "transactions"
+
+	// AcceptedTransactionsTopic is a topic for accepted transactions
+	AcceptedTransactionsTopic = "transactions/accepted"
+
+	// UnacceptedTransactionsTopic is a topic for unaccepted transactions
+	UnacceptedTransactionsTopic = "transactions/unaccepted"
+)
+
+// publishTransactionsNotifications publishes notifications for each transaction of the given transactions.
+// All the notifications are written to the events outbox at once.
+func publishTransactionsNotifications(ctx database.Context, topic string, dbTransactions []*dbmodels.Transaction, selectedTipBlueScore uint64) error {
+	var outboxEvents []*dbmodels.OutboxEvent
+	for _, dbTransaction := range dbTransactions {
+		transaction := apimodels.ConvertTxModelToTxResponse(dbTransaction, selectedTipBlueScore)
+		addresses := uniqueAddressesForTransaction(transaction)
+		for _, address := range addresses {
+			outboxEvent, err := newOutboxEvent(path.Join(topic, address), transaction)
+			if err != nil {
+				return err
+			}
+			outboxEvents = append(outboxEvents, outboxEvent)
+		}
+	}
+	return dbaccess.InsertOutboxEvents(ctx, outboxEvents)
+}
+
+func uniqueAddressesForTransaction(transaction *apimodels.TransactionResponse) []string {
+	addressesMap := make(map[string]struct{})
+	addresses := []string{}
+	for _, output := range transaction.Outputs {
+		if _, exists := addressesMap[output.Address]; !exists {
+			addresses = append(addresses, output.Address)
+			addressesMap[output.Address] = struct{}{}
+		}
+	}
+	for _, input := range transaction.Inputs {
+		if _, exists := addressesMap[input.Address]; !exists {
+			addresses = append(addresses, input.Address)
+			addressesMap[input.Address] = struct{}{}
+		}
+	}
+	return addresses
+}
+
+// PublishAcceptedTransactionsNotifications publishes notification for each transaction that was accepted by the selected parent chain
+func PublishAcceptedTransactionsNotifications(ctx database.Context, acceptedTransactions []*dbmodels.Transaction) error {
+	if !hasPublisher() {
+		return nil
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(ctx)
+	if err != nil {
+		return err
+	}
+
+	return publishTransactionsNotifications(ctx, AcceptedTransactionsTopic, acceptedTransactions, selectedTipBlueScore)
+}
+
+// PublishUnacceptedTransactionsNotifications publishes notification for each unaccepted transaction of the given chain-block
+func PublishUnacceptedTransactionsNotifications(ctx database.Context, unacceptedTransactions []*dbmodels.Transaction) error {
+	if !hasPublisher() {
+		return nil
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = publishTransactionsNotifications(ctx, UnacceptedTransactionsTopic, unacceptedTransactions, selectedTipBlueScore)
+	if err != nil {
+		return err
+	}
+	return nil
+}
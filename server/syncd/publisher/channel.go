@@ -0,0 +1,56 @@
+package publisher
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ChannelPublisher publishes events to an in-process channel
+type ChannelPublisher struct {
+	lock     sync.RWMutex
+	events   chan *Event
+	isClosed bool
+}
+
+// NewChannelPublisher returns a publisher that sends every event to a
+// channel with the given buffer size. Publishing blocks while the buffer
+// is full.
+func NewChannelPublisher(bufferSize int) *ChannelPublisher {
+	return &ChannelPublisher{
+		events: make(chan *Event, bufferSize),
+	}
+}
+
+// Events returns the channel the events are sent to.
+// It is closed when the publisher is closed.
+func (cp *ChannelPublisher) Events() <-chan *Event {
+	return cp.events
+}
+
+// Publish sends the given events to the channel
+func (cp *ChannelPublisher) Publish(events ...*Event) error {
+	cp.lock.RLock()
+	defer cp.lock.RUnlock()
+
+	if cp.isClosed {
+		return errors.New("cannot publish to a closed channel publisher")
+	}
+	for _, event := range events {
+		cp.events <- event
+	}
+	return nil
+}
+
+// Close closes the channel
+func (cp *ChannelPublisher) Close() error {
+	cp.lock.Lock()
+	defer cp.lock.Unlock()
+
+	if cp.isClosed {
+		return nil
+	}
+	cp.isClosed = true
+	close(cp.events)
+	return nil
+}
@@ -0,0 +1,71 @@
+package publisher
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// fileEvent is a single line of the NDJSON file
+type fileEvent struct {
+	Sequence  uint64      `json:"sequence"`
+	Timestamp int64       `json:"timestamp"`
+	Topic     string      `json:"topic"`
+	Data      interface{} `json:"data"`
+}
+
+// filePublisher appends every event to a file as a line of
+// newline-delimited JSON (NDJSON)
+type filePublisher struct {
+	lock sync.Mutex
+	file *os.File
+}
+
+// NewFilePublisher opens the file at the given path for appending, creating
+// it if needed, and returns a publisher that appends every event to it as
+// a line of newline-delimited JSON
+func NewFilePublisher(path string) (Publisher, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	log.Infof("Publishing events to %s", path)
+
+	return &filePublisher{file: file}, nil
+}
+
+func (fp *filePublisher) Publish(events ...*Event) error {
+	timestamp := time.Now().UnixNano() / int64(time.Millisecond)
+	var lines []byte
+	for _, event := range events {
+		line, err := json.Marshal(&fileEvent{
+			Sequence:  event.Sequence,
+			Timestamp: timestamp,
+			Topic:     event.Topic,
+			Data:      event.Data,
+		})
+		if err != nil {
+			return err
+		}
+		lines = append(lines, line...)
+		lines = append(lines, '\n')
+	}
+
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+
+	// The lines are written with a single call, so that
+	// readers tailing the file never see partial lines
+	_, err := fp.file.Write(lines)
+	return errors.WithStack(err)
+}
+
+func (fp *filePublisher) Close() error {
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+
+	return errors.WithStack(fp.file.Close())
+}
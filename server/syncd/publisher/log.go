@@ -0,0 +1,11 @@
+package publisher
+
+import (
+	"github.com/kaspanet/kaspad/util/panics"
+	"github.com/someone235/katnip/server/logger"
+)
+
+var (
+	log   = logger.Logger("PBLS")
+	spawn = panics.GoroutineWrapperFunc(log)
+)
@@ -0,0 +1,203 @@
+package publisher
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io/ioutil"
+	"sync"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	quiesceMilliseconds = 250
+
+	// mqttWorkers is the maximum number of messages
+	// that are waiting for the broker at once
+	mqttWorkers = 16
+)
+
+var mqttPublishFailuresCount = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "katnip_syncd_mqtt_publish_failures_total",
+	Help: "Number of messages that failed to be published to the MQTT broker",
+})
+
+// MQTTOptions are the options of an MQTT publisher
+type MQTTOptions struct {
+	// BrokerAddress is the address of the broker. Use the ssl://
+	// scheme to connect to the broker over TLS.
+	BrokerAddress string
+
+	// User and Password are left empty for anonymous brokers
+	User     string
+	Password string
+
+	QualityOfService byte
+
+	// CAFile is a PEM file with the certificate authorities the broker's
+	// certificate is verified against, instead of the system's ones
+	CAFile string
+
+	// CertFile and KeyFile are a PEM certificate and key
+	// the publisher authenticates itself to the broker with
+	CertFile string
+	KeyFile  string
+
+	// TopicPrefix is prepended to the topics of all the published events
+	TopicPrefix string
+
+	// RetainedTopics are the topics whose last message is retained by the
+	// broker and sent to every new subscriber. They are given without
+	// the TopicPrefix.
+	RetainedTopics []string
+}
+
+// mqttPublisher publishes events to an MQTT broker,
+// using their topics as MQTT topics
+type mqttPublisher struct {
+	client           mqtt.Client
+	qualityOfService byte
+	topicPrefix      string
+	retainedTopics   map[string]struct{}
+}
+
+// NewMQTTPublisher connects to an MQTT broker using the given
+// options and returns a publisher that publishes to it
+func NewMQTTPublisher(mqttOptions *MQTTOptions) (Publisher, error) {
+	options := mqtt.NewClientOptions()
+	options.AddBroker(mqttOptions.BrokerAddress)
+	options.SetUsername(mqttOptions.User)
+	options.SetPassword(mqttOptions.Password)
+	options.SetAutoReconnect(true)
+
+	if mqttOptions.CAFile != "" || mqttOptions.CertFile != "" {
+		tlsConfig, err := newMQTTTLSConfig(mqttOptions)
+		if err != nil {
+			return nil, err
+		}
+		options.SetTLSConfig(tlsConfig)
+	}
+
+	client := mqtt.NewClient(options)
+	if token := client.Connect(); token.Wait() && token.Error() != nil {
+		return nil, token.Error()
+	}
+	log.Infof("Connected to MQTT in %s", mqttOptions.BrokerAddress)
+
+	retainedTopics := make(map[string]struct{}, len(mqttOptions.RetainedTopics))
+	for _, topic := range mqttOptions.RetainedTopics {
+		retainedTopics[topic] = struct{}{}
+	}
+
+	return &mqttPublisher{
+		client:           client,
+		qualityOfService: mqttOptions.QualityOfService,
+		topicPrefix:      mqttOptions.TopicPrefix,
+		retainedTopics:   retainedTopics,
+	}, nil
+}
+
+func newMQTTTLSConfig(mqttOptions *MQTTOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	if mqttOptions.CAFile != "" {
+		caPEM, err := ioutil.ReadFile(mqttOptions.CAFile)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, errors.Errorf("no certificates were found in %s", mqttOptions.CAFile)
+		}
+	}
+
+	if mqttOptions.CertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(mqttOptions.CertFile, mqttOptions.KeyFile)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+// Publish publishes the given events through a bounded pool of workers,
+// so that the broker's round-trips for different messages overlap
+func (mp *mqttPublisher) Publish(events ...*Event) error {
+	workers := mqttWorkers
+	if len(events) < workers {
+		workers = len(events)
+	}
+
+	eventsChan := make(chan *Event)
+	errChan := make(chan error, len(events))
+	waitGroup := sync.WaitGroup{}
+	waitGroup.Add(workers)
+	for i := 0; i < workers; i++ {
+		spawn("mqttPublisher-Publish", func() {
+			defer waitGroup.Done()
+			for event := range eventsChan {
+				errChan <- mp.publish(event)
+			}
+		})
+	}
+	for _, event := range events {
+		eventsChan <- event
+	}
+	close(eventsChan)
+	waitGroup.Wait()
+	close(errChan)
+
+	for err := range errChan {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mp *mqttPublisher) publish(event *Event) error {
+	payload, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	payload, err = withSequence(payload, event.Sequence)
+	if err != nil {
+		return err
+	}
+
+	_, retained := mp.retainedTopics[event.Topic]
+	token := mp.client.Publish(mp.topicPrefix+event.Topic, mp.qualityOfService, retained, payload)
+	token.Wait()
+	if token.Error() != nil {
+		mqttPublishFailuresCount.Inc()
+		return errors.WithStack(token.Error())
+	}
+	return nil
+}
+
+func (mp *mqttPublisher) Close() error {
+	mp.client.Disconnect(quiesceMilliseconds)
+	return nil
+}
+
+// withSequence adds the given sequence number to the given JSON object
+// as its "sequence" field. MQTT 3.1.1 messages don't have headers, and
+// adding a field keeps the payload compatible with existing consumers.
+func withSequence(payload []byte, sequence uint64) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(payload, &fields)
+	if err != nil || fields == nil {
+		return nil, errors.Errorf("MQTT payloads must be JSON objects, got: %s", payload)
+	}
+	fields["sequence"], err = json.Marshal(sequence)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
@@ -0,0 +1,76 @@
+package publisher
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Publisher is a sink for the events emitted by the sync flow.
+// Implementations must be safe for concurrent use.
+type Publisher interface {
+	// Publish publishes the given events, and returns once all of
+	// them were published. Events may be published concurrently, so
+	// their order is not guaranteed. The events' data is expected to
+	// be serializable to JSON.
+	Publish(events ...*Event) error
+
+	// Close releases the resources held by the publisher.
+	Close() error
+}
+
+// Event is a published event
+type Event struct {
+	// Sequence is the sequence number of the event. Events are
+	// published at least once, so consumers may receive an event
+	// more than once, and can use it to discard duplicates.
+	Sequence uint64      `json:"sequence"`
+	Topic    string      `json:"topic"`
+	Data     interface{} `json:"data"`
+}
+
+// multiPublisher publishes every event to several publishers
+type multiPublisher struct {
+	publishers []Publisher
+}
+
+// NewMultiPublisher returns a publisher that publishes every event to all the
+// given publishers. It returns nil if no publishers are given, and the only
+// publisher if just one is given.
+func NewMultiPublisher(publishers ...Publisher) Publisher {
+	switch len(publishers) {
+	case 0:
+		return nil
+	case 1:
+		return publishers[0]
+	default:
+		return &multiPublisher{publishers: publishers}
+	}
+}
+
+// Publish publishes the given events to all the publishers, even if some
+// of them fail, and returns the first error encountered
+func (mp *multiPublisher) Publish(events ...*Event) error {
+	var firstErr error
+	for _, publisher := range mp.publishers {
+		err := publisher.Publish(events...)
+		if err != nil {
+			if firstErr != nil {
+				log.Errorf("Error publishing events: %s", err)
+				continue
+			}
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Close closes all the publishers and returns the first error encountered
+func (mp *multiPublisher) Close() error {
+	var firstErr error
+	for _, publisher := range mp.publishers {
+		err := publisher.Close()
+		if err != nil && firstErr == nil {
+			firstErr = errors.WithStack(err)
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,32 @@
+package publisher
+
+import "testing"
+
+func TestMultiPublisher(t *testing.T) {
+	first := NewChannelPublisher(1)
+	second := NewChannelPublisher(1)
+	multi := NewMultiPublisher(first, second)
+
+	err := multi.Publish(&Event{Sequence: 1, Topic: "dag/blocks", Data: "data"})
+	if err != nil {
+		t.Fatalf("Publish: %s", err)
+	}
+	for i, channelPublisher := range []*ChannelPublisher{first, second} {
+		event := <-channelPublisher.Events()
+		if event.Sequence != 1 || event.Topic != "dag/blocks" || event.Data != "data" {
+			t.Errorf("publisher %d: unexpected event %+v", i, event)
+		}
+	}
+
+	err = multi.Close()
+	if err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+	if _, ok := <-first.Events(); ok {
+		t.Errorf("Expected the events channel to be closed")
+	}
+	err = multi.Publish(&Event{Sequence: 2, Topic: "dag/blocks", Data: "data"})
+	if err == nil {
+		t.Errorf("Expected publishing to closed publishers to fail")
+	}
+}
@@ -0,0 +1,100 @@
+package sync
+
+import (
+	"sort"
+
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+// insertAddressBalanceChanges inserts the changes in the balances of the addresses
+// of the given transactions, which were accepted by the given chain block. It
+// requires the outputs and the previous transaction outputs of the transactions
+// to be preloaded.
+func insertAddressBalanceChanges(dbTx *database.TxContext, dbAcceptingBlock *dbmodels.Block,
+	dbTransactions []*dbmodels.Transaction) error {
+
+	addressIDSet := make(map[uint64]struct{})
+	for _, dbTransaction := range dbTransactions {
+		for addressID := range transactionValueChanges(dbTransaction) {
+			addressIDSet[addressID] = struct{}{}
+		}
+	}
+	addressIDs := make([]uint64, 0, len(addressIDSet))
+	for addressID := range addressIDSet {
+		addressIDs = append(addressIDs, addressID)
+	}
+
+	balances, err := dbaccess.AddressBalancesByAddressIDs(dbTx, addressIDs)
+	if err != nil {
+		return err
+	}
+
+	balanceChanges := addressBalanceChanges(dbAcceptingBlock, dbTransactions, balances)
+	balanceChangesToAdd := make([]interface{}, len(balanceChanges))
+	for i, balanceChange := range balanceChanges {
+		balanceChangesToAdd[i] = balanceChange
+	}
+	return dbaccess.BulkInsert(dbTx, balanceChangesToAdd)
+}
+
+// addressBalanceChanges returns the changes in the balances of the addresses of
+// the given transactions, which were accepted by the given chain block, ordered
+// by transaction ID. The given balances are the balances of the addresses before
+// the transactions, and are updated to the balances after them.
+func addressBalanceChanges(dbAcceptingBlock *dbmodels.Block, dbTransactions []*dbmodels.Transaction,
+	balances map[uint64]int64) []*dbmodels.AddressBalanceChange {
+
+	sortedTransactions := make([]*dbmodels.Transaction, len(dbTransactions))
+	copy(sortedTransactions, dbTransactions)
+	sort.Slice(sortedTransactions, func(i, j int) bool {
+		return sortedTransactions[i].ID < sortedTransactions[j].ID
+	})
+
+	balanceChanges := make([]*dbmodels.AddressBalanceChange, 0)
+	for _, dbTransaction := range sortedTransactions {
+		valueChanges := transactionValueChanges(dbTransaction)
+		addressIDs := make([]uint64, 0, len(valueChanges))
+		for addressID := range valueChanges {
+			addressIDs = append(addressIDs, addressID)
+		}
+		sort.Slice(addressIDs, func(i, j int) bool { return addressIDs[i] < addressIDs[j] })
+
+		for _, addressID := range addressIDs {
+			valueChange := valueChanges[addressID]
+			if valueChange == 0 {
+				continue
+			}
+			balances[addressID] += valueChange
+			balanceChanges = append(balanceChanges, &dbmodels.AddressBalanceChange{
+				AddressID:        addressID,
+				TransactionID:    dbTransaction.ID,
+				AcceptingBlockID: dbAcceptingBlock.ID,
+				BlueScore:        dbAcceptingBlock.BlueScore,
+				Timestamp:        dbAcceptingBlock.Timestamp,
+				ValueChange:      valueChange,
+				Balance:          balances[addressID],
+			})
+		}
+	}
+	return balanceChanges
+}
+
+// transactionValueChanges returns the change in the balance of
+// every address that the given transaction has an input or an output of
+func transactionValueChanges(dbTransaction *dbmodels.Transaction) map[uint64]int64 {
+	valueChanges := make(map[uint64]int64)
+	for _, dbTransactionOutput := range dbTransaction.TransactionOutputs {
+		if dbTransactionOutput.AddressID != nil {
+			valueChanges[*dbTransactionOutput.AddressID] += int64(dbTransactionOutput.Value)
+		}
+	}
+	for _, dbTransactionInput := range dbTransaction.TransactionInputs {
+		dbPreviousTransactionOutput := dbTransactionInput.PreviousTransactionOutput
+		if dbPreviousTransactionOutput != nil && dbPreviousTransactionOutput.AddressID != nil {
+			valueChanges[*dbPreviousTransactionOutput.AddressID] -= int64(dbPreviousTransactionOutput.Value)
+		}
+	}
+	return valueChanges
+}
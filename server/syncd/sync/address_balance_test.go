@@ -0,0 +1,47 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+func TestAddressBalanceChanges(t *testing.T) {
+	addressA := uint64(1)
+	addressB := uint64(2)
+	previousOutput := &dbmodels.TransactionOutput{Value: 100, AddressID: &addressA}
+	dbTransactions := []*dbmodels.Transaction{
+		// A sends 60 to B, and gets the change minus a fee of 10
+		{
+			ID:                 20,
+			TransactionInputs:  []dbmodels.TransactionInput{{PreviousTransactionOutput: previousOutput}},
+			TransactionOutputs: []dbmodels.TransactionOutput{{Value: 60, AddressID: &addressB}, {Value: 30, AddressID: &addressA}},
+		},
+		// A coinbase transaction that pays A
+		{
+			ID:                 10,
+			TransactionOutputs: []dbmodels.TransactionOutput{{Value: 50, AddressID: &addressA}},
+		},
+	}
+	dbAcceptingBlock := &dbmodels.Block{ID: 5, BlueScore: 7}
+	balances := map[uint64]int64{addressA: 100}
+
+	balanceChanges := addressBalanceChanges(dbAcceptingBlock, dbTransactions, balances)
+
+	expected := []dbmodels.AddressBalanceChange{
+		{AddressID: addressA, TransactionID: 10, AcceptingBlockID: 5, BlueScore: 7, ValueChange: 50, Balance: 150},
+		{AddressID: addressA, TransactionID: 20, AcceptingBlockID: 5, BlueScore: 7, ValueChange: -70, Balance: 80},
+		{AddressID: addressB, TransactionID: 20, AcceptingBlockID: 5, BlueScore: 7, ValueChange: 60, Balance: 60},
+	}
+	if len(balanceChanges) != len(expected) {
+		t.Fatalf("expected %d balance changes but got %d", len(expected), len(balanceChanges))
+	}
+	for i, balanceChange := range balanceChanges {
+		if *balanceChange != expected[i] {
+			t.Errorf("balance change %d: expected %+v but got %+v", i, expected[i], *balanceChange)
+		}
+	}
+	if balances[addressA] != 80 || balances[addressB] != 60 {
+		t.Errorf("unexpected balances after the changes: %v", balances)
+	}
+}
@@ -2,7 +2,6 @@ package sync
 
 import (
 	"github.com/kaspanet/kaspad/app/appmessage"
-	"github.com/kaspanet/kaspad/infrastructure/logger"
 	"github.com/kaspanet/kaspad/util/mstime"
 	"github.com/someone235/katnip/server/database"
 	"github.com/someone235/katnip/server/serializer"
@@ -13,7 +12,7 @@ import (
 )
 
 func insertBlocks(dbTx *database.TxContext, blocks []*appmessage.RPCBlock) error {
-	onEnd := logger.LogAndMeasureExecutionTime(log, "insertBlocks")
+	onEnd := measureStage(dbTx, "insertBlocks")
 	defer onEnd()
 
 	blocksToAdd := make([]interface{}, len(blocks))
@@ -27,8 +26,10 @@ func insertBlocks(dbTx *database.TxContext, blocks []*appmessage.RPCBlock) error
 	return dbaccess.BulkInsert(dbTx, blocksToAdd)
 }
 
+// getBlocksWithTheirParentIDs returns a map from hashes to IDs of the given
+// blocks, their parents, and the blocks in their merge sets.
 func getBlocksWithTheirParentIDs(dbTx *database.TxContext, blocks []*appmessage.RPCBlock) (map[string]uint64, error) {
-	onEnd := logger.LogAndMeasureExecutionTime(log, "getBlocksWithTheirParentIDs")
+	onEnd := measureStage(dbTx, "getBlocksWithTheirParentIDs")
 	defer onEnd()
 
 	blockSet := make(map[string]struct{})
@@ -37,6 +38,12 @@ func getBlocksWithTheirParentIDs(dbTx *database.TxContext, blocks []*appmessage.
 		for _, parentHash := range block.Header.ParentHashes {
 			blockSet[parentHash] = struct{}{}
 		}
+		for _, mergeSetBlockHash := range block.VerboseData.MergeSetBluesHashes {
+			blockSet[mergeSetBlockHash] = struct{}{}
+		}
+		for _, mergeSetBlockHash := range block.VerboseData.MergeSetRedsHashes {
+			blockSet[mergeSetBlockHash] = struct{}{}
+		}
 	}
 
 	blockHashes := stringsSetToSlice(blockSet)
@@ -2,7 +2,6 @@ package sync
 
 import (
 	"github.com/kaspanet/kaspad/app/appmessage"
-	"github.com/kaspanet/kaspad/infrastructure/logger"
 	"github.com/someone235/katnip/server/database"
 	"github.com/someone235/katnip/server/dbaccess"
 	"github.com/someone235/katnip/server/dbmodels"
@@ -11,7 +10,7 @@ import (
 )
 
 func insertBlockParents(dbTx *database.TxContext, blocks []*appmessage.RPCBlock, blockHashesToIDs map[string]uint64) error {
-	onEnd := logger.LogAndMeasureExecutionTime(log, "insertBlockParents")
+	onEnd := measureStage(dbTx, "insertBlockParents")
 	defer onEnd()
 
 	parentsToAdd := make([]interface{}, 0)
@@ -0,0 +1,57 @@
+package sync
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/dbmodels"
+
+	"github.com/pkg/errors"
+)
+
+func insertMergeSetBlocks(dbTx *database.TxContext, blocks []*appmessage.RPCBlock, blockHashesToIDs map[string]uint64) error {
+	onEnd := measureStage(dbTx, "insertMergeSetBlocks")
+	defer onEnd()
+
+	mergeSetBlocksToAdd := make([]interface{}, 0)
+	for _, block := range blocks {
+		dbMergeSetBlocks, err := dbMergeSetBlocksFromRPCBlock(blockHashesToIDs, block)
+		if err != nil {
+			return err
+		}
+		for _, dbMergeSetBlock := range dbMergeSetBlocks {
+			mergeSetBlocksToAdd = append(mergeSetBlocksToAdd, dbMergeSetBlock)
+		}
+	}
+	return dbaccess.BulkInsert(dbTx, mergeSetBlocksToAdd)
+}
+
+func dbMergeSetBlocksFromRPCBlock(blockHashesToIDs map[string]uint64, block *appmessage.RPCBlock) ([]*dbmodels.MergeSetBlock, error) {
+	blockID, ok := blockHashesToIDs[block.VerboseData.Hash]
+	if !ok {
+		return nil, errors.Errorf("couldn't find block ID for block %s", block.VerboseData.Hash)
+	}
+
+	dbMergeSetBlocks := make([]*dbmodels.MergeSetBlock, 0,
+		len(block.VerboseData.MergeSetBluesHashes)+len(block.VerboseData.MergeSetRedsHashes))
+	for _, mergeSet := range []struct {
+		hashes []string
+		isBlue bool
+	}{
+		{hashes: block.VerboseData.MergeSetBluesHashes, isBlue: true},
+		{hashes: block.VerboseData.MergeSetRedsHashes, isBlue: false},
+	} {
+		for _, mergedBlockHash := range mergeSet.hashes {
+			mergedBlockID, ok := blockHashesToIDs[mergedBlockHash]
+			if !ok {
+				return nil, errors.Errorf("missing merge set block %s for block %s", mergedBlockHash, block.VerboseData.Hash)
+			}
+			dbMergeSetBlocks = append(dbMergeSetBlocks, &dbmodels.MergeSetBlock{
+				BlockID:       blockID,
+				MergedBlockID: mergedBlockID,
+				IsBlue:        mergeSet.isBlue,
+			})
+		}
+	}
+	return dbMergeSetBlocks, nil
+}
@@ -0,0 +1,84 @@
+package sync
+
+import (
+	"math"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/kaspadrpc"
+	"github.com/someone235/katnip/server/tracing"
+)
+
+var (
+	syncedBlocksCount = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "katnip_syncd_blocks_total",
+		Help: "Number of blocks that were added to the database",
+	})
+
+	stageDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "katnip_syncd_stage_duration_seconds",
+		Help:    "Execution time of the sync stages",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 15),
+	}, []string{"stage"})
+)
+
+// measureStage logs the execution time of the given sync stage, like
+// logger.LogAndMeasureExecutionTime does, records it in the stage duration
+// histogram, and traces it as a child of the span of dbTx. Call the
+// returned function when the stage ends.
+func measureStage(dbTx *database.TxContext, stage string) func() {
+	onEnd := logger.LogAndMeasureExecutionTime(log, stage)
+	_, endSpan := tracing.StartSpan(dbTx.Context(), "sync/"+stage)
+	start := time.Now()
+	return func() {
+		onEnd()
+		endSpan(nil)
+		stageDuration.WithLabelValues(stage).Observe(time.Since(start).Seconds())
+	}
+}
+
+// registerClientMetrics registers the metrics that are
+// collected from the node on every scrape
+func registerClientMetrics(client *kaspadrpc.Client) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "katnip_syncd_lag_blue_score",
+		Help: "Blue score of the node's virtual selected parent minus the blue score of the synced selected tip",
+	}, func() float64 {
+		return syncLag(client)
+	})
+
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "katnip_syncd_notification_queue_depth",
+		Help:        "Number of node notifications that are waiting to be handled",
+		ConstLabels: prometheus.Labels{"notification": "block_added"},
+	}, func() float64 {
+		return float64(len(client.OnBlockAdded))
+	})
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "katnip_syncd_notification_queue_depth",
+		Help:        "Number of node notifications that are waiting to be handled",
+		ConstLabels: prometheus.Labels{"notification": "virtual_selected_parent_chain_changed"},
+	}, func() float64 {
+		return float64(len(client.OnVirtualSelectedParentChainChanged))
+	})
+}
+
+// syncLag returns how far the database is behind the node, or NaN
+// if it couldn't be found, in which case the gauge is reported as NaN
+func syncLag(client *kaspadrpc.Client) float64 {
+	virtualSelectedParentBlueScore, err := client.GetVirtualSelectedParentBlueScore()
+	if err != nil {
+		log.Warnf("Error getting the virtual selected parent blue score for the sync lag: %s", err)
+		return math.NaN()
+	}
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(database.NoTx())
+	if err != nil {
+		log.Warnf("Error getting the selected tip blue score for the sync lag: %s", err)
+		return math.NaN()
+	}
+	return float64(virtualSelectedParentBlueScore.BlueScore) - float64(selectedTipBlueScore)
+}
@@ -0,0 +1,252 @@
+package sync
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+// selectedParentChainUpdate holds the IDs of the transactions
+// whose acceptance status was changed by updateSelectedParentChain.
+type selectedParentChainUpdate struct {
+	acceptedTransactionIDs   []string
+	unacceptedTransactionIDs []string
+}
+
+// updateSelectedParentChain updates the database to reflect the current selected
+// parent chain. First it "unaccepts" all removedChainHashes and then it "accepts"
+// all addChainBlocks.
+func updateSelectedParentChain(dbTx *database.TxContext, removedChainHashes []string,
+	addedChainBlocks []*appmessage.ChainBlock) (*selectedParentChainUpdate, error) {
+
+	onEnd := measureStage(dbTx, "updateSelectedParentChain")
+	defer onEnd()
+
+	update := &selectedParentChainUpdate{
+		acceptedTransactionIDs:   make([]string, 0),
+		unacceptedTransactionIDs: make([]string, 0),
+	}
+	for _, removedHash := range removedChainHashes {
+		err := updateRemovedChainHashes(dbTx, removedHash, update)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, addedBlock := range addedChainBlocks {
+		err := updateAddedChainBlocks(dbTx, addedBlock, update)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return update, nil
+}
+
+// updateRemovedChainHashes "unaccepts" the block of the given removedHash.
+// That is to say, it marks it as not in the selected parent chain in the
+// following ways:
+// * All its TransactionInputs.PreviousTransactionOutputs are set IsSpent = false
+// * All its Transactions are set AcceptingBlockID = nil
+// * The address balance changes of its Transactions are deleted
+// * All the blocks it accepted are set AcceptingBlockID = nil
+// * The block is set IsChainBlock = false
+// Blocks that are already not in the selected parent chain are skipped.
+func updateRemovedChainHashes(dbTx *database.TxContext, removedHash string, update *selectedParentChainUpdate) error {
+	dbBlock, err := dbaccess.BlockByHash(dbTx, removedHash)
+	if err != nil {
+		return err
+	}
+	if dbBlock == nil {
+		return errors.Errorf("removed chain block %s does not exist in the database", removedHash)
+	}
+	if !dbBlock.IsChainBlock {
+		log.Debugf("Block %s is already not a chain block", removedHash)
+		return nil
+	}
+
+	dbTransactions, err := dbaccess.AcceptedTransactionsByBlockID(dbTx, dbBlock.ID,
+		dbmodels.TransactionFieldNames.InputsPreviousTransactionOutputs)
+	if err != nil {
+		return err
+	}
+
+	for _, dbTransaction := range dbTransactions {
+		for _, dbTransactionInput := range dbTransaction.TransactionInputs {
+			dbPreviousTransactionOutput := dbTransactionInput.PreviousTransactionOutput
+			if dbPreviousTransactionOutput == nil {
+				continue
+			}
+			if !dbPreviousTransactionOutput.IsSpent {
+				return errors.Errorf("cannot de-spend an unspent transaction output: %s index: %d",
+					dbTransaction.TransactionID, dbTransactionInput.Index)
+			}
+			err = dbaccess.UpdateTransactionOutputIsSpent(dbTx, dbPreviousTransactionOutput.ID, false)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = dbaccess.UpdateTransactionAcceptingBlockID(dbTx, dbTransaction.ID, nil)
+		if err != nil {
+			return err
+		}
+		update.unacceptedTransactionIDs = append(update.unacceptedTransactionIDs, dbTransaction.TransactionID)
+	}
+
+	err = dbaccess.DeleteAddressBalanceChangesByAcceptingBlockID(dbTx, dbBlock.ID)
+	if err != nil {
+		return err
+	}
+
+	err = dbaccess.UpdateBlocksAcceptedByAcceptingBlock(dbTx, dbBlock.ID, nil)
+	if err != nil {
+		return err
+	}
+
+	return dbaccess.UpdateBlockIsChainBlock(dbTx, dbBlock.ID, false)
+}
+
+// updateAddedChainBlocks "accepts" the given addedBlock. That is to say,
+// it marks it as in the selected parent chain in the following ways:
+// * All its TransactionInputs.PreviousTransactionOutputs are set IsSpent = true
+// * All its Transactions are set AcceptingBlockID = addedBlock
+// * The address balance changes of its Transactions are inserted
+// * All the blocks it accepted are set AcceptingBlockID = addedBlock
+// * The block is set IsChainBlock = true
+// Blocks that are already in the selected parent chain are skipped.
+func updateAddedChainBlocks(dbTx *database.TxContext, addedBlock *appmessage.ChainBlock, update *selectedParentChainUpdate) error {
+	dbAddedBlock, err := dbaccess.BlockByHash(dbTx, addedBlock.Hash)
+	if err != nil {
+		return err
+	}
+	if dbAddedBlock == nil {
+		return errors.Errorf("added chain block %s does not exist in the database", addedBlock.Hash)
+	}
+	if dbAddedBlock.IsChainBlock {
+		log.Debugf("Block %s is already a chain block", addedBlock.Hash)
+		return nil
+	}
+
+	acceptedTransactions := make([]*dbmodels.Transaction, 0)
+	for _, acceptedBlock := range addedBlock.AcceptedBlocks {
+		dbAcceptedBlock, err := dbaccess.BlockByHash(dbTx, acceptedBlock.Hash)
+		if err != nil {
+			return err
+		}
+		if dbAcceptedBlock == nil {
+			return errors.Errorf("accepted block %s does not exist in the database", acceptedBlock.Hash)
+		}
+		if dbAcceptedBlock.AcceptingBlockID != nil {
+			return errors.Errorf("block %s erroneously marked as accepted", acceptedBlock.Hash)
+		}
+
+		dbAcceptedTransactions, err := dbaccess.TransactionsByIDsAndBlockID(dbTx, acceptedBlock.AcceptedTransactionIDs,
+			dbAcceptedBlock.ID, dbmodels.TransactionFieldNames.InputsPreviousTransactionOutputs,
+			dbmodels.TransactionFieldNames.TransactionOutputs)
+		if err != nil {
+			return err
+		}
+		if len(dbAcceptedTransactions) != len(acceptedBlock.AcceptedTransactionIDs) {
+			return errors.Errorf("some transactions are missing for accepted block %s", acceptedBlock.Hash)
+		}
+
+		for _, dbAcceptedTransaction := range dbAcceptedTransactions {
+			for _, dbTransactionInput := range dbAcceptedTransaction.TransactionInputs {
+				dbPreviousTransactionOutput := dbTransactionInput.PreviousTransactionOutput
+				if dbPreviousTransactionOutput == nil {
+					continue
+				}
+				if dbPreviousTransactionOutput.IsSpent {
+					return errors.Errorf("cannot spend an already spent transaction output: %s index: %d",
+						dbAcceptedTransaction.TransactionID, dbTransactionInput.Index)
+				}
+				err = dbaccess.UpdateTransactionOutputIsSpent(dbTx, dbPreviousTransactionOutput.ID, true)
+				if err != nil {
+					return err
+				}
+			}
+
+			err = dbaccess.UpdateTransactionAcceptingBlockID(dbTx, dbAcceptedTransaction.ID, &dbAddedBlock.ID)
+			if err != nil {
+				return err
+			}
+
+			if dbAcceptedTransaction.Fee == nil {
+				if fee, ok := transactionFee(dbAcceptedTransaction); ok {
+					err = dbaccess.UpdateTransactionFee(dbTx, dbAcceptedTransaction.ID, fee)
+					if err != nil {
+						return err
+					}
+				}
+			}
+			update.acceptedTransactionIDs = append(update.acceptedTransactionIDs, dbAcceptedTransaction.TransactionID)
+		}
+		acceptedTransactions = append(acceptedTransactions, dbAcceptedTransactions...)
+
+		err = dbaccess.UpdateBlockAcceptingBlockID(dbTx, dbAcceptedBlock.ID, &dbAddedBlock.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = insertAddressBalanceChanges(dbTx, dbAddedBlock, acceptedTransactions)
+	if err != nil {
+		return err
+	}
+
+	return dbaccess.UpdateBlockIsChainBlock(dbTx, dbAddedBlock.ID, true)
+}
+
+// transactionFee returns the fee paid by the given transaction, which is the
+// difference between the value of the outputs it spends and the value of its
+// outputs. It requires the previous transaction outputs of the transaction to
+// be preloaded, and returns false for coinbase transactions and transactions
+// whose previous outputs aren't all known.
+func transactionFee(dbTransaction *dbmodels.Transaction) (uint64, bool) {
+	if len(dbTransaction.TransactionInputs) == 0 {
+		return 0, false
+	}
+
+	inputsValue := uint64(0)
+	for _, dbTransactionInput := range dbTransaction.TransactionInputs {
+		if dbTransactionInput.PreviousTransactionOutput == nil {
+			return 0, false
+		}
+		inputsValue += dbTransactionInput.PreviousTransactionOutput.Value
+	}
+
+	outputsValue := uint64(0)
+	for _, dbTransactionOutput := range dbTransaction.TransactionOutputs {
+		outputsValue += dbTransactionOutput.Value
+	}
+
+	if outputsValue > inputsValue {
+		return 0, false
+	}
+	return inputsValue - outputsValue, true
+}
+
+// chainBlocksExist checks whether all the blocks referenced by the given
+// chain changes are already in the database.
+func chainBlocksExist(ctx database.Context, removedChainHashes []string,
+	addedChainBlocks []*appmessage.ChainBlock) (bool, error) {
+
+	hashSet := make(map[string]struct{})
+	for _, removedHash := range removedChainHashes {
+		hashSet[removedHash] = struct{}{}
+	}
+	for _, addedBlock := range addedChainBlocks {
+		hashSet[addedBlock.Hash] = struct{}{}
+		for _, acceptedBlock := range addedBlock.AcceptedBlocks {
+			hashSet[acceptedBlock.Hash] = struct{}{}
+		}
+	}
+
+	hashes := stringsSetToSlice(hashSet)
+	existingHashes, err := dbaccess.ExistingHashes(ctx, hashes)
+	if err != nil {
+		return false, err
+	}
+	return len(existingHashes) == len(hashes), nil
+}
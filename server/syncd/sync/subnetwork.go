@@ -2,7 +2,6 @@ package sync
 
 import (
 	"github.com/kaspanet/kaspad/app/appmessage"
-	"github.com/kaspanet/kaspad/infrastructure/logger"
 	"github.com/someone235/katnip/server/database"
 	"github.com/someone235/katnip/server/dbaccess"
 	"github.com/someone235/katnip/server/dbmodels"
@@ -14,7 +13,7 @@ import (
 func insertSubnetworks(client *kaspadrpc.Client, dbTx *database.TxContext, blocks []*appmessage.RPCBlock) (
 	subnetworkIDsToIDs map[string]uint64, err error) {
 
-	onEnd := logger.LogAndMeasureExecutionTime(log, "insertSubnetworks")
+	onEnd := measureStage(dbTx, "insertSubnetworks")
 	defer onEnd()
 
 	subnetworkSet := make(map[string]struct{})
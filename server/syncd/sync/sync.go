@@ -1,12 +1,19 @@
 package sync
 
 import (
+	"context"
+
 	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/apimodels"
 	"github.com/someone235/katnip/server/database"
 
 	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/dbmodels"
 	"github.com/someone235/katnip/server/kaspadrpc"
-	"github.com/someone235/katnip/server/syncd/mqtt"
+	"github.com/someone235/katnip/server/syncd/notifications"
+	"github.com/someone235/katnip/server/syncd/webhooks"
+	"github.com/someone235/katnip/server/tracing"
 )
 
 // StartSync keeps the node and the database in sync. On start, it downloads
@@ -17,6 +24,7 @@ func StartSync(doneChan chan struct{}) error {
 	if err != nil {
 		return err
 	}
+	registerClientMetrics(client)
 
 	// Mass download missing data
 	err = fetchInitialData(client)
@@ -36,12 +44,21 @@ func fetchInitialData(client *kaspadrpc.Client) error {
 	if err != nil {
 		return err
 	}
+	log.Infof("Syncing past selected parent chain")
+	err = syncSelectedParentChain(client)
+	if err != nil {
+		return err
+	}
 	log.Infof("Finished syncing past data")
 	return nil
 }
 
 // sync keeps the database in sync with the node via notifications
 func sync(client *kaspadrpc.Client, doneChan chan struct{}) error {
+	// Chain changes may arrive before the blocks they refer to,
+	// so they're queued until all of their blocks are in the database
+	pendingChainChangedMsgs := make([]*appmessage.VirtualSelectedParentChainChangedNotificationMessage, 0)
+
 	// Handle client notifications until we're told to stop
 	for {
 		select {
@@ -50,10 +67,18 @@ func sync(client *kaspadrpc.Client, doneChan chan struct{}) error {
 			if err != nil {
 				return err
 			}
+		case chainChanged := <-client.OnVirtualSelectedParentChainChanged:
+			pendingChainChangedMsgs = append(pendingChainChangedMsgs, chainChanged)
 		case <-doneChan:
 			log.Infof("StartSync stopped")
 			return nil
 		}
+
+		var err error
+		pendingChainChangedMsgs, err = handlePendingChainChangedMsgs(pendingChainChangedMsgs)
+		if err != nil {
+			return err
+		}
 	}
 }
 
@@ -80,7 +105,9 @@ func syncBlocks(client *kaspadrpc.Client) error {
 			log.Debugf("Calling getBlocks with no start hash")
 		}
 
+		endSpan := kaspadrpc.StartSpan(context.Background(), "GetBlocks")
 		blocksResult, err := client.GetBlocks(startHash, true, true)
+		endSpan(err)
 		if err != nil {
 			return err
 		}
@@ -100,21 +127,101 @@ func syncBlocks(client *kaspadrpc.Client) error {
 	return nil
 }
 
+// chainBlocksBatchSize is the number of added chain blocks
+// that syncSelectedParentChain commits in a single transaction
+const chainBlocksBatchSize = 100
+
+// syncSelectedParentChain attempts to download the selected parent
+// chain starting with the selected tip, and then updates the
+// database accordingly.
+func syncSelectedParentChain(client *kaspadrpc.Client) (err error) {
+	ctx, endSpan := tracing.StartSpan(context.Background(), "sync/syncSelectedParentChain")
+	defer func() { endSpan(err) }()
+
+	selectedTip, err := dbaccess.SelectedTip(database.NoTxWithContext(ctx))
+	if err != nil {
+		return err
+	}
+	if selectedTip == nil {
+		return errors.New("couldn't find the selected tip in the database")
+	}
+
+	endRPCSpan := kaspadrpc.StartSpan(ctx, "GetVirtualSelectedParentChainFromBlock")
+	chainFromBlockResult, err := client.GetVirtualSelectedParentChainFromBlock(selectedTip.BlockHash)
+	endRPCSpan(err)
+	if err != nil {
+		return err
+	}
+
+	// The chain is applied in batches, each in a transaction of its own, so
+	// that catching up on a long chain, such as on the first start with a
+	// database where only genesis is marked as a chain block, doesn't run
+	// in one huge transaction. Every batch leaves a prefix of the chain in
+	// the database, so a restart continues after the last committed batch.
+	removedChainBlockHashes := chainFromBlockResult.RemovedChainBlockHashes
+	addedChainBlocks := chainFromBlockResult.AddedChainBlocks
+	appliedCount := 0
+	for len(removedChainBlockHashes) > 0 || len(addedChainBlocks) > 0 {
+		batchSize := chainBlocksBatchSize
+		if batchSize > len(addedChainBlocks) {
+			batchSize = len(addedChainBlocks)
+		}
+		err := applySelectedParentChainBatch(ctx, removedChainBlockHashes, addedChainBlocks[:batchSize])
+		if err != nil {
+			return err
+		}
+		appliedCount += batchSize
+		log.Debugf("Applied %d of %d chain blocks", appliedCount, len(chainFromBlockResult.AddedChainBlocks))
+
+		// Release the applied blocks, so that the acceptance
+		// data of the rest of the chain is all that's kept
+		for i := 0; i < batchSize; i++ {
+			addedChainBlocks[i] = nil
+		}
+		removedChainBlockHashes = nil
+		addedChainBlocks = addedChainBlocks[batchSize:]
+	}
+	return nil
+}
+
+// applySelectedParentChainBatch removes the given chain blocks from the
+// selected parent chain, adds the given chain blocks to it, and commits
+func applySelectedParentChainBatch(ctx context.Context, removedChainBlockHashes []string,
+	addedChainBlocks []*appmessage.ChainBlock) error {
+
+	dbTx, err := database.NewTxWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbTx.RollbackUnlessCommitted()
+
+	_, err = updateSelectedParentChain(dbTx, removedChainBlockHashes, addedChainBlocks)
+	if err != nil {
+		return err
+	}
+	return dbTx.Commit()
+}
+
 // fetchBlock downloads the serialized block and raw block data of
 // the block with hash blockHash.
-func fetchBlock(client *kaspadrpc.Client, blockHash string) (
+func fetchBlock(ctx context.Context, client *kaspadrpc.Client, blockHash string) (
 	*appmessage.RPCBlock, error) {
 	log.Debugf("Getting block %s from the RPC server", blockHash)
+	endSpan := kaspadrpc.StartSpan(ctx, "GetBlock")
 	blockResponse, err := client.GetBlock(blockHash, true)
+	endSpan(err)
 	if err != nil {
 		return nil, err
 	}
 	return blockResponse.Block, nil
 }
 
-func handleBlockAddedMsg(client *kaspadrpc.Client, blockAdded *appmessage.BlockAddedNotificationMessage) error {
+func handleBlockAddedMsg(client *kaspadrpc.Client, blockAdded *appmessage.BlockAddedNotificationMessage) (err error) {
+	ctx, endSpan := tracing.StartSpan(context.Background(), "sync/handleBlockAdded")
+	defer func() { endSpan(err) }()
+
 	blockHash := blockAdded.Block.VerboseData.Hash
-	blockExists, err := dbaccess.DoesBlockExist(database.NoTx(), blockHash)
+	blockExists, err := dbaccess.DoesBlockExist(database.NoTxWithContext(ctx), blockHash)
 	if err != nil {
 		return err
 	}
@@ -122,7 +229,7 @@ func handleBlockAddedMsg(client *kaspadrpc.Client, blockAdded *appmessage.BlockA
 		return nil
 	}
 
-	dbTx, err := database.NewTx()
+	dbTx, err := database.NewTxWithContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -134,24 +241,180 @@ func handleBlockAddedMsg(client *kaspadrpc.Client, blockAdded *appmessage.BlockA
 		return err
 	}
 
-	err = dbTx.Commit()
+	for _, hash := range addedBlockHashes {
+		err := webhooks.EnqueueBlockAddedDeliveries(dbTx, hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = dbaccess.NotifyEvent(dbTx, dbaccess.EventBlocksAdded, addedBlockHashes)
 	if err != nil {
 		return err
 	}
 
 	for _, hash := range addedBlockHashes {
-		err := mqtt.PublishBlockAddedNotifications(hash)
+		err := notifications.PublishBlockAddedNotifications(dbTx, hash)
 		if err != nil {
 			return err
 		}
 	}
+
+	err = dbTx.Commit()
+	if err != nil {
+		return err
+	}
+
+	notifications.Wake()
+	return nil
+}
+
+// handlePendingChainChangedMsgs handles the given chain changes in order,
+// and returns the ones that could not be handled yet because some of their
+// blocks are still missing from the database.
+func handlePendingChainChangedMsgs(
+	pendingChainChangedMsgs []*appmessage.VirtualSelectedParentChainChangedNotificationMessage) (
+	[]*appmessage.VirtualSelectedParentChainChangedNotificationMessage, error) {
+
+	for len(pendingChainChangedMsgs) > 0 {
+		chainChanged := pendingChainChangedMsgs[0]
+		canHandle, err := chainBlocksExist(database.NoTx(), chainChanged.RemovedChainBlockHashes,
+			chainChanged.AddedChainBlocks)
+		if err != nil {
+			return nil, err
+		}
+		if !canHandle {
+			log.Debugf("Some blocks of the chain change are missing. Postponing its handling")
+			break
+		}
+
+		err = handleChainChangedMsg(chainChanged)
+		if err != nil {
+			return nil, err
+		}
+		pendingChainChangedMsgs = pendingChainChangedMsgs[1:]
+	}
+	return pendingChainChangedMsgs, nil
+}
+
+func handleChainChangedMsg(chainChanged *appmessage.VirtualSelectedParentChainChangedNotificationMessage) (err error) {
+	ctx, endSpan := tracing.StartSpan(context.Background(), "sync/handleChainChanged")
+	defer func() { endSpan(err) }()
+
+	dbTx, err := database.NewTxWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbTx.RollbackUnlessCommitted()
+
+	previousSelectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(dbTx)
+	if err != nil {
+		return err
+	}
+
+	update, err := updateSelectedParentChain(dbTx, chainChanged.RemovedChainBlockHashes,
+		chainChanged.AddedChainBlocks)
+	if err != nil {
+		return err
+	}
+
+	unacceptedTransactions, err := dbaccess.TransactionsByIDs(dbTx, update.unacceptedTransactionIDs,
+		dbmodels.TransactionRecommendedPreloadedFields...)
+	if err != nil {
+		return err
+	}
+	acceptedTransactions, err := dbaccess.TransactionsByIDs(dbTx, update.acceptedTransactionIDs,
+		dbmodels.TransactionRecommendedPreloadedFields...)
+	if err != nil {
+		return err
+	}
+
+	err = enqueueChainChangedWebhookDeliveries(dbTx, unacceptedTransactions, acceptedTransactions,
+		previousSelectedTipBlueScore)
+	if err != nil {
+		return err
+	}
+
+	err = publishChainChangedNotifications(dbTx, chainChanged, unacceptedTransactions, acceptedTransactions)
+	if err != nil {
+		return err
+	}
+
+	addedChainBlockHashes := make([]string, len(chainChanged.AddedChainBlocks))
+	for i, addedChainBlock := range chainChanged.AddedChainBlocks {
+		addedChainBlockHashes[i] = addedChainBlock.Hash
+	}
+	err = dbaccess.NotifyEvent(dbTx, dbaccess.EventChainBlocksAdded, addedChainBlockHashes)
+	if err != nil {
+		return err
+	}
+
+	err = dbTx.Commit()
+	if err != nil {
+		return err
+	}
+
+	notifications.Wake()
 	return nil
 }
 
+// enqueueChainChangedWebhookDeliveries queues the webhook deliveries for the
+// transactions whose acceptance status was changed, and for the transactions
+// that reached a confirmation threshold. It's called in the same database
+// transaction as the chain update, so that no delivery is lost.
+func enqueueChainChangedWebhookDeliveries(dbTx *database.TxContext, unacceptedTransactions []*dbmodels.Transaction,
+	acceptedTransactions []*dbmodels.Transaction, previousSelectedTipBlueScore uint64) error {
+
+	err := webhooks.EnqueueTransactionsDeliveries(dbTx, apimodels.WebhookEventTransactionUnaccepted, unacceptedTransactions)
+	if err != nil {
+		return err
+	}
+
+	err = webhooks.EnqueueTransactionsDeliveries(dbTx, apimodels.WebhookEventTransactionAccepted, acceptedTransactions)
+	if err != nil {
+		return err
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(dbTx)
+	if err != nil {
+		return err
+	}
+	return webhooks.EnqueueConfirmationDeliveries(dbTx, previousSelectedTipBlueScore, selectedTipBlueScore)
+}
+
+// publishChainChangedNotifications publishes the changes in the selected parent chain,
+// the transactions whose acceptance status was changed, and the new selected tip.
+func publishChainChangedNotifications(dbTx *database.TxContext,
+	chainChanged *appmessage.VirtualSelectedParentChainChangedNotificationMessage,
+	unacceptedTransactions []*dbmodels.Transaction, acceptedTransactions []*dbmodels.Transaction) error {
+
+	err := notifications.PublishSelectedParentChainNotifications(dbTx, chainChanged.RemovedChainBlockHashes,
+		chainChanged.AddedChainBlocks)
+	if err != nil {
+		return err
+	}
+
+	err = notifications.PublishUnacceptedTransactionsNotifications(dbTx, unacceptedTransactions)
+	if err != nil {
+		return err
+	}
+
+	err = notifications.PublishAcceptedTransactionsNotifications(dbTx, acceptedTransactions)
+	if err != nil {
+		return err
+	}
+
+	if len(chainChanged.AddedChainBlocks) == 0 {
+		return nil
+	}
+	selectedTip := chainChanged.AddedChainBlocks[len(chainChanged.AddedChainBlocks)-1]
+	return notifications.PublishSelectedTipNotification(dbTx, selectedTip.Hash)
+}
+
 func fetchAndAddBlock(client *kaspadrpc.Client, dbTx *database.TxContext,
 	blockHash string) (addedBlockHashes []string, err error) {
 
-	block, err := fetchBlock(client, blockHash)
+	block, err := fetchBlock(dbTx.Context(), client, blockHash)
 	if err != nil {
 		return nil, err
 	}
@@ -193,7 +456,7 @@ func fetchMissingAncestors(client *kaspadrpc.Client, dbTx *database.TxContext, b
 			if _, ok := missingAncestorsSet[missingHash]; ok {
 				continue
 			}
-			block, err := fetchBlock(client, missingHash)
+			block, err := fetchBlock(dbTx.Context(), client, missingHash)
 			if err != nil {
 				return nil, err
 			}
@@ -254,8 +517,11 @@ func missingBlockHashes(dbTx *database.TxContext, blockHashes []string,
 
 // addBlocks inserts data in the given verbose blocks
 // into the database.
-func addBlocks(client *kaspadrpc.Client, getBlocksResponse *appmessage.GetBlocksResponseMessage) error {
-	dbTx, err := database.NewTx()
+func addBlocks(client *kaspadrpc.Client, getBlocksResponse *appmessage.GetBlocksResponseMessage) (err error) {
+	ctx, endSpan := tracing.StartSpan(context.Background(), "sync/addBlocks")
+	defer func() { endSpan(err) }()
+
+	dbTx, err := database.NewTxWithContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -311,11 +577,17 @@ func bulkInsertBlocksData(client *kaspadrpc.Client, dbTx *database.TxContext, bl
 		return err
 	}
 
+	err = insertMergeSetBlocks(dbTx, blocks, blockHashesToIDs)
+	if err != nil {
+		return err
+	}
+
 	err = insertTransactionBlocks(dbTx, blocks, blockHashesToIDs, transactionHashesToTxsWithMetadata)
 	if err != nil {
 		return err
 	}
 
+	syncedBlocksCount.Add(float64(len(blocks)))
 	log.Infof("Added %d blocks", len(blocks))
 	return nil
 }
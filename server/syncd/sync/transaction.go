@@ -4,7 +4,6 @@ import (
 	"encoding/hex"
 
 	"github.com/kaspanet/kaspad/app/appmessage"
-	"github.com/kaspanet/kaspad/infrastructure/logger"
 	"github.com/pkg/errors"
 	"github.com/someone235/katnip/server/database"
 	"github.com/someone235/katnip/server/dbaccess"
@@ -31,7 +30,7 @@ func transactionHashesToTxsWithMetadataToTransactionHashes(transactionHashesToTx
 func insertTransactions(dbTx *database.TxContext, blocks []*appmessage.RPCBlock, subnetworkIDsToIDs map[string]uint64) (
 	map[string]*txWithMetadata, error) {
 
-	onEnd := logger.LogAndMeasureExecutionTime(log, "insertTransactions")
+	onEnd := measureStage(dbTx, "insertTransactions")
 	defer onEnd()
 
 	transactionHashesToTxsWithMetadata := make(map[string]*txWithMetadata)
@@ -86,6 +85,7 @@ func insertTransactions(dbTx *database.TxContext, blocks []*appmessage.RPCBlock,
 			SubnetworkID:    subnetworkID,
 			Gas:             tx.Gas,
 			Payload:         payload,
+			Mass:            tx.VerboseData.Mass,
 			Version:         tx.Version,
 		}
 	}
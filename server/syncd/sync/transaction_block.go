@@ -2,7 +2,6 @@ package sync
 
 import (
 	"github.com/kaspanet/kaspad/app/appmessage"
-	"github.com/kaspanet/kaspad/infrastructure/logger"
 	"github.com/someone235/katnip/server/database"
 	"github.com/someone235/katnip/server/dbaccess"
 	"github.com/someone235/katnip/server/dbmodels"
@@ -13,7 +12,7 @@ import (
 func insertTransactionBlocks(dbTx *database.TxContext, blocks []*appmessage.RPCBlock,
 	blockHashesToIDs map[string]uint64, transactionHashesToTxsWithMetadata map[string]*txWithMetadata) error {
 
-	onEnd := logger.LogAndMeasureExecutionTime(log, "insertTransactionBlocks")
+	onEnd := measureStage(dbTx, "insertTransactionBlocks")
 	defer onEnd()
 
 	transactionBlocksToAdd := make([]interface{}, 0)
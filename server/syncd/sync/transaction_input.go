@@ -4,7 +4,6 @@ import (
 	"encoding/hex"
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
-	"github.com/kaspanet/kaspad/infrastructure/logger"
 	"github.com/someone235/katnip/server/database"
 	"github.com/someone235/katnip/server/serializer"
 
@@ -13,7 +12,7 @@ import (
 )
 
 func insertTransactionInputs(dbTx *database.TxContext, transactionHashesToTxsWithMetadata map[string]*txWithMetadata) error {
-	onEnd := logger.LogAndMeasureExecutionTime(log, "insertTransactionInputs")
+	onEnd := measureStage(dbTx, "insertTransactionInputs")
 	defer onEnd()
 
 	outpointsSet := make(map[dbaccess.Outpoint]struct{})
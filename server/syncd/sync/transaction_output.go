@@ -2,7 +2,6 @@ package sync
 
 import (
 	"encoding/hex"
-	"github.com/kaspanet/kaspad/infrastructure/logger"
 	"github.com/someone235/katnip/server/database"
 
 	"github.com/pkg/errors"
@@ -11,7 +10,7 @@ import (
 )
 
 func insertTransactionOutputs(dbTx *database.TxContext, transactionHashesToTxsWithMetadata map[string]*txWithMetadata) error {
-	onEnd := logger.LogAndMeasureExecutionTime(log, "insertTransactionOutputs")
+	onEnd := measureStage(dbTx, "insertTransactionOutputs")
 	defer onEnd()
 
 	addressesToAddressIDs, err := insertAddresses(dbTx, transactionHashesToTxsWithMetadata)
@@ -1,5 +1,5 @@
-package mqtt
+package webhooks
 
 import "github.com/someone235/katnip/server/logger"
 
-var log = logger.Logger("MQTT")
+var log = logger.Logger("WHKS")
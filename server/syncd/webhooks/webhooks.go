@@ -0,0 +1,173 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/someone235/katnip/server/apimodels"
+	"github.com/someone235/katnip/server/database"
+	"github.com/someone235/katnip/server/dbaccess"
+	"github.com/someone235/katnip/server/dbmodels"
+)
+
+// EnqueueBlockAddedDeliveries queues a delivery for every webhook
+// subscription watching one of the transactions of the added block
+func EnqueueBlockAddedDeliveries(ctx database.Context, hash string) error {
+	preloadedFields := dbmodels.PrefixFieldNames(dbmodels.BlockFieldNames.Transactions, dbmodels.TransactionRecommendedPreloadedFields)
+	dbBlock, err := dbaccess.BlockByHash(ctx, hash, preloadedFields...)
+	if err != nil {
+		return err
+	}
+
+	return EnqueueTransactionsDeliveries(ctx, apimodels.WebhookEventTransaction, dbBlock.Transactions)
+}
+
+// EnqueueTransactionsDeliveries queues a delivery of the given event for every
+// webhook subscription without a confirmation threshold that watches one of the
+// given transactions, either by its ID or by one of its addresses
+func EnqueueTransactionsDeliveries(ctx database.Context, event string, dbTransactions []*dbmodels.Transaction) error {
+	if len(dbTransactions) == 0 {
+		return nil
+	}
+
+	selectedTipBlueScore, err := dbaccess.SelectedTipBlueScore(ctx)
+	if err != nil {
+		return err
+	}
+
+	transactions := make([]*apimodels.TransactionResponse, len(dbTransactions))
+	transactionIDs := make([]string, len(dbTransactions))
+	addressSet := make(map[string]struct{})
+	for i, dbTransaction := range dbTransactions {
+		transactions[i] = apimodels.ConvertTxModelToTxResponse(dbTransaction, selectedTipBlueScore)
+		transactionIDs[i] = dbTransaction.TransactionID
+		for _, address := range transactionAddresses(transactions[i]) {
+			addressSet[address] = struct{}{}
+		}
+	}
+	addresses := make([]string, 0, len(addressSet))
+	for address := range addressSet {
+		addresses = append(addresses, address)
+	}
+
+	subscriptions, err := dbaccess.WebhookSubscriptionsByAddressesOrTransactionIDs(ctx, addresses, transactionIDs)
+	if err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	deliveries := make([]*dbmodels.WebhookDelivery, 0)
+	for _, transaction := range transactions {
+		for _, subscription := range subscriptions {
+			if !isWatching(subscription, transaction) {
+				continue
+			}
+			delivery, err := newDelivery(subscription.ID, event, transaction)
+			if err != nil {
+				return err
+			}
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	log.Debugf("Queueing %d '%s' webhook deliveries", len(deliveries), event)
+	return dbaccess.InsertWebhookDeliveries(ctx, deliveries)
+}
+
+// EnqueueConfirmationDeliveries queues a delivery for every transaction that
+// reached the confirmation threshold of a webhook subscription watching it
+// when the selected tip blue score advanced from `fromBlueScore` to `toBlueScore`
+func EnqueueConfirmationDeliveries(ctx database.Context, fromBlueScore uint64, toBlueScore uint64) error {
+	confirmations, err := dbaccess.WebhookConfirmationsByBlueScoreRange(ctx, fromBlueScore, toBlueScore)
+	if err != nil {
+		return err
+	}
+	if len(confirmations) == 0 {
+		return nil
+	}
+
+	transactionIDSet := make(map[string]struct{})
+	for _, confirmation := range confirmations {
+		transactionIDSet[confirmation.TransactionID] = struct{}{}
+	}
+	transactionIDs := make([]string, 0, len(transactionIDSet))
+	for transactionID := range transactionIDSet {
+		transactionIDs = append(transactionIDs, transactionID)
+	}
+
+	dbTransactions, err := dbaccess.TransactionsByIDs(ctx, transactionIDs, dbmodels.TransactionRecommendedPreloadedFields...)
+	if err != nil {
+		return err
+	}
+	transactionsByID := make(map[string]*apimodels.TransactionResponse, len(dbTransactions))
+	for _, dbTransaction := range dbTransactions {
+		transactionsByID[dbTransaction.TransactionID] = apimodels.ConvertTxModelToTxResponse(dbTransaction, toBlueScore)
+	}
+
+	deliveries := make([]*dbmodels.WebhookDelivery, len(confirmations))
+	for i, confirmation := range confirmations {
+		deliveries[i], err = newDelivery(confirmation.WebhookSubscriptionID, apimodels.WebhookEventTransactionConfirmed,
+			transactionsByID[confirmation.TransactionID])
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("Queueing %d '%s' webhook deliveries", len(deliveries), apimodels.WebhookEventTransactionConfirmed)
+	return dbaccess.InsertWebhookDeliveries(ctx, deliveries)
+}
+
+func newDelivery(subscriptionID uint64, event string, transaction *apimodels.TransactionResponse) (*dbmodels.WebhookDelivery, error) {
+	payload, err := json.Marshal(&apimodels.WebhookNotification{
+		SubscriptionID: subscriptionID,
+		Event:          event,
+		Transaction:    transaction,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &dbmodels.WebhookDelivery{
+		WebhookSubscriptionID: subscriptionID,
+		Event:                 event,
+		TransactionID:         &transaction.TransactionID,
+		Payload:               string(payload),
+		Status:                dbmodels.WebhookDeliveryStatusPending,
+		Attempts:              0,
+		NextAttemptAt:         now,
+		CreatedAt:             now,
+	}, nil
+}
+
+func isWatching(subscription *dbmodels.WebhookSubscription, transaction *apimodels.TransactionResponse) bool {
+	if subscription.TransactionID != nil && *subscription.TransactionID == transaction.TransactionID {
+		return true
+	}
+	if subscription.Address == nil {
+		return false
+	}
+	for _, address := range transactionAddresses(transaction) {
+		if address == *subscription.Address {
+			return true
+		}
+	}
+	return false
+}
+
+func transactionAddresses(transaction *apimodels.TransactionResponse) []string {
+	addresses := make([]string, 0, len(transaction.Inputs)+len(transaction.Outputs))
+	for _, output := range transaction.Outputs {
+		if output.Address != "" {
+			addresses = append(addresses, output.Address)
+		}
+	}
+	for _, input := range transaction.Inputs {
+		if input.Address != "" {
+			addresses = append(addresses, input.Address)
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,9 @@
+package tracing
+
+import (
+	"github.com/someone235/katnip/server/logger"
+)
+
+var (
+	log = logger.Logger("TRCE")
+)
@@ -0,0 +1,116 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/someone235/katnip/server/config"
+	"github.com/someone235/katnip/server/version"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/someone235/katnip/server"
+	shutdownTimeout     = 5 * time.Second
+)
+
+// Start sets up the propagation of W3C trace context, and, if an exporter
+// is configured, starts exporting the traces of the given service with it.
+// It returns a function that exports the remaining traces and stops.
+func Start(serviceName string, cfg *config.CommonConfigFlags) (func(), error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+
+	if cfg.TraceExporter == "" {
+		return func() {}, nil
+	}
+
+	exporter, closeExporter, err := newExporter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.TraceSampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String(serviceName),
+			semconv.ServiceVersionKey.String(version.Version()))),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	log.Infof("Exporting traces with the %s exporter", cfg.TraceExporter)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		err := tracerProvider.Shutdown(ctx)
+		if err != nil {
+			log.Errorf("Error exporting the remaining traces: %s", err)
+		}
+		err = closeExporter()
+		if err != nil {
+			log.Errorf("Error closing the trace exporter: %s", err)
+		}
+	}, nil
+}
+
+// newExporter returns the configured exporter, and a function
+// that closes what it writes to once it's shut down
+func newExporter(cfg *config.CommonConfigFlags) (sdktrace.SpanExporter, func() error, error) {
+	noopClose := func() error { return nil }
+	switch cfg.TraceExporter {
+	case "stdout":
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		return exporter, noopClose, errors.WithStack(err)
+	case "file":
+		file, err := os.OpenFile(cfg.TraceFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, nil, errors.WithStack(err)
+		}
+		return exporter, file.Close, nil
+	case "jaeger":
+		exporter, err := jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(cfg.TraceEndpoint)))
+		return exporter, noopClose, errors.WithStack(err)
+	default:
+		return nil, nil, errors.Errorf("unknown trace exporter %s", cfg.TraceExporter)
+	}
+}
+
+// Tracer returns the tracer that katnip's spans are started with
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// StartSpan starts a span with the given name as a child of the span in ctx,
+// and returns a context with the new span and a function that ends it. The
+// function records the error it's given, if it's not nil, on the span.
+func StartSpan(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, func(err error)) {
+	ctx, span := Tracer().Start(ctx, name, options...)
+	return ctx, func(err error) {
+		EndSpan(span, err)
+	}
+}
+
+// EndSpan records the given error on the given span
+// if it's not nil, and ends the span
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -35,27 +35,28 @@ export default function BlockPage() {
     return (
         <Box>
             <Block block={block}/>
-            <Parents parents={block.parentBlockHashes}/>
+            <BlockLinks title="Parents" hashes={block.parentBlockHashes}/>
+            <BlockLinks title="Children" hashes={block.childBlockHashes}/>
             <Transactions transactionIds={block.transactionIds}/>
         </Box>
     );
 }
 
-function Parents({parents}: { parents: Array<string> }) {
+function BlockLinks({title, hashes}: { title: string, hashes: Array<string> }) {
     const classes = useStyles();
 
     return (
         <Box my={4}>
             <Typography variant="h6" component="h1" gutterBottom>
-                Parents
+                {title}
             </Typography>
             <TableContainer component={Paper}>
-                <Table className={classes.table} aria-label="Parents">
-                    {parents.map(parent => <TableRow key={parent}>
+                <Table className={classes.table} aria-label={title}>
+                    {hashes.map(hash => <TableRow key={hash}>
                             <TableCell>
                                 <Link
                                     color="textSecondary"
-                                    href={`#/block/${parent}`}>{parent}</Link>
+                                    href={`#/block/${hash}`}>{hash}</Link>
                             </TableCell>
                         </TableRow>
                     )}
@@ -134,6 +135,10 @@ function Block({block}: { block: ApiBlock }) {
                         <TableCell variant="head">Number of Parents</TableCell>
                         <TableCell>{block.parentBlockHashes.length}</TableCell>
                     </TableRow>
+                    <TableRow>
+                        <TableCell variant="head">Number of Children</TableCell>
+                        <TableCell>{block.childBlockHashes.length}</TableCell>
+                    </TableRow>
                     <TableRow>
                         <TableCell variant="head">Number of Transactions</TableCell>
                         <TableCell>{block.transactionCount}</TableCell>
@@ -8,7 +8,7 @@ import TableHead from '@material-ui/core/TableHead';
 import TableRow from '@material-ui/core/TableRow';
 import Paper from '@material-ui/core/Paper';
 import Link from '@material-ui/core/Link';
-import {getBlocks, ApiBlock} from "./lib/api";
+import {getBlocks, subscribeToBlocks, ApiBlock} from "./lib/api";
 
 
 const useStyles = makeStyles({
@@ -24,8 +24,7 @@ export default function BlockList() {
 
     useEffect(() => {
         getBlocks().then(setBlocks)
-        const timeoutID = setInterval(() => getBlocks().then(setBlocks), 1000);
-        return () => clearTimeout(timeoutID)
+        return subscribeToBlocks((block) => setBlocks((blocks) => [block, ...blocks].slice(0, 10)))
     }, [null])
 
     return (
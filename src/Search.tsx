@@ -26,7 +26,7 @@ export default function Search() {
                     paddingLeft: 20,
                     height: 30,
                 }}
-                id="standard-basic" placeholder="Search for block hash or transaction ID" InputProps={{
+                id="standard-basic" placeholder="Search for block hash, transaction ID or address" InputProps={{
                 disableUnderline: true,
                 endAdornment: (
                     <InputAdornment position="start">
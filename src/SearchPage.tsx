@@ -1,33 +1,35 @@
 import React, {useEffect, useState} from "react";
-import {getBlock, getTx} from "./lib/api";
+import {ApiSearchResponse, search as searchApi} from "./lib/api";
 import {useParams} from "react-router-dom";
 import Container from "@material-ui/core/Container";
+import Link from '@material-ui/core/Link';
 
 export default function Search() {
     const {search} = useParams<{ search: string }>()
     const [err, setErr] = useState("");
+    const [results, setResults] = useState<ApiSearchResponse["results"]>([]);
 
     useEffect(() => {
         (async () => {
+            setErr("")
+            setResults([])
             try {
-                await getBlock(search)
-                window.location.href = "#/block/" + search
-                return
-            } catch (e: any) {
-                if (e.errorCode == 404) {
-                    try {
-                        await getTx(search)
-                        window.location.href = "#/tx/" + search
-                        return
-                    } catch (e: any) {
-                        if (e.errorCode == 404) {
-                            setErr(`Couldn't find a block or transaction with ID or hash of ${search}`)
-                            return
-                        }
-                        setErr(`Error ${e.errorCode}: ${e.errorMessage}`)
-                    }
+                const response = await searchApi(search)
+                if (response.results.length == 0) {
+                    setErr(`Couldn't find a block, transaction or address matching ${search}`)
+                    return
+                }
+                const [result] = response.results
+                if (response.results.length == 1 && result.type == 'block') {
+                    window.location.href = "#/block/" + result.value
                     return
                 }
+                if (response.results.length == 1 && result.type == 'transaction') {
+                    window.location.href = "#/tx/" + result.value
+                    return
+                }
+                setResults(response.results)
+            } catch (e: any) {
                 setErr(`Error ${e.errorCode}: ${e.errorMessage}`)
             }
         })()
@@ -35,5 +37,13 @@ export default function Search() {
 
     return <Container>
         {err}
+        <ul>
+            {results.map(result => <li key={result.type + result.value}>
+                {result.type}:{' '}
+                {result.type == 'block' && <Link color="textSecondary" href={`#/block/${result.value}`}>{result.value}</Link>}
+                {result.type == 'transaction' && <Link color="textSecondary" href={`#/tx/${result.value}`}>{result.value}</Link>}
+                {result.type == 'address' && result.value}
+            </li>)}
+        </ul>
     </Container>
-}
\ No newline at end of file
+}
@@ -12,6 +12,18 @@ export function getTx(id: string): Promise<ApiTx> {
     return apiCall(`transaction/id/${id}`);
 }
 
+export function search(query: string): Promise<ApiSearchResponse> {
+    return apiCall(`search?q=${encodeURIComponent(query)}`);
+}
+
+export function subscribeToBlocks(onBlock: (block: ApiBlock) => void): () => void {
+    const eventSource = new EventSource(baseEndpoint + 'stream?topics=dag/blocks');
+    eventSource.addEventListener('dag/blocks', (event) => {
+        onBlock(JSON.parse((event as MessageEvent).data));
+    });
+    return () => eventSource.close();
+}
+
 async function apiCall(path: string): Promise<any> {
     const response = await fetch(baseEndpoint + path).then(res => res.json());
     if (response.errorMessage != undefined) {
@@ -40,6 +52,7 @@ export interface ApiBlock {
     blueScore: number
     timestamp: number
     parentBlockHashes: Array<string>
+    childBlockHashes: Array<string>
     transactionCount: number
     hashMerkleRoot: string
     acceptedIDMerkleRoot: string
@@ -50,3 +63,11 @@ export interface ApiBlock {
     difficulty: number
     transactionIds: Array<string>
 }
+
+export interface ApiSearchResponse {
+    query: string
+    results: Array<{
+        type: 'block' | 'transaction' | 'address'
+        value: string
+    }>
+}